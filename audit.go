@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// auditWatching is the key of the prior value of whether current user
+	// was watching a case
+	auditWatching = "watching"
+	// auditNone is audit in config to log nothing
+	auditNone = "-"
+)
+
+var (
+	// auditFile is the audit log, set when config is loaded.
+	// Nothing is logged if empty, as opted out by auditNone.
+	auditFile string
+	// auditMu guards writing of auditFile and auditCount
+	auditMu sync.Mutex
+	// auditCount is the number of entries in auditFile, -1 if not counted
+	auditCount = -1
+	// auditUndone is the entry being undone by current actions, if any
+	auditUndone int
+)
+
+// auditReq is a request sent successfully, that may change anything
+type auditReq struct {
+	Method string `json:"method"`
+	// URL is redacted by redactURL
+	URL string `json:"url"`
+	// Body is the JSON body, or a JSON string of any other one
+	Body json.RawMessage `json:"body,omitempty"`
+	File string          `json:"file,omitempty"`
+}
+
+// auditEntry is one line of the audit log, for an action on one ID
+type auditEntry struct {
+	// Entry is the number of the line, beginning with 1
+	Entry  int    `json:"entry"`
+	Time   string `json:"time"`
+	Server string `json:"server"`
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	ID     string `json:"id,omitempty"`
+	// Prior are values before the action, to undo it
+	Prior IssueInfos `json:"prior,omitempty"`
+	Reqs  []auditReq `json:"requests"`
+	// Err is set if the action fails after some requests sent
+	Err string `json:"error,omitempty"`
+	// Undo is the entry undone by this one, if any
+	Undo int `json:"undo,omitempty"`
+}
+
+// auditRec records requests of an action on one ID, in its context
+type auditRec struct {
+	mu    sync.Mutex
+	entry auditEntry
+}
+
+// auditKey is the key of auditRec in contexts
+type auditKey struct{}
+
+// auditLogFile is the audit log in config, or besides the config file
+// by default, or none if opted out by auditNone
+func auditLogFile() string {
+	switch cfg.Audit {
+	case auditNone:
+		return ""
+	case "":
+	default:
+		return cfg.Audit
+	}
+	if len(cfgFile) < 1 {
+		return module + ".audit.jsonl"
+	}
+	return strings.TrimSuffix(cfgFile, filepath.Ext(cfgFile)) +
+		".audit.jsonl"
+}
+
+// auditStart fetches prior values for the action, and makes a context
+// to record requests of it, unless nothing is to be logged.
+// done of the recorder is to be called when the action is done.
+func auditStart(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	action string, inf IssueInfos) (context.Context, *auditRec) {
+	if len(auditFile) < 1 || dryRun {
+		return ctx, nil
+	}
+	rec := &auditRec{entry: auditEntry{Server: svr.Name, Type: svr.Type,
+		Action: action, ID: inf[IssueinfoStrID], Undo: auditUndone}}
+	rec.entry.Prior = auditPrior(ctx, svr, authInfo, action, inf)
+	return context.WithValue(ctx, auditKey{}, rec), rec
+}
+
+// auditOf gets the recorder of ctx, if any
+func auditOf(ctx context.Context) *auditRec {
+	rec, _ := ctx.Value(auditKey{}).(*auditRec)
+	return rec
+}
+
+// auditBody reads the body of a request, that may change anything,
+// for it to be recorded when sent
+// Return values: the body read, and a new reader of it to be sent
+func auditBody(ctx context.Context, method string,
+	bodyReq io.Reader) ([]byte, io.Reader) {
+	if auditOf(ctx) == nil || bodyReq == nil || !mutating(method) {
+		return nil, bodyReq
+	}
+	body, err := io.ReadAll(bodyReq)
+	if err != nil {
+		Log(false, false, err)
+	}
+	return body, bytes.NewReader(body)
+}
+
+// auditSent records a request sent successfully, that may change anything
+func auditSent(ctx context.Context, method, url string,
+	body []byte, fName string) {
+	rec := auditOf(ctx)
+	if rec == nil || !mutating(method) {
+		return
+	}
+	req := auditReq{Method: method, URL: redactURL(url), File: fName}
+	switch {
+	case len(body) < 1:
+	case json.Valid(body):
+		req.Body = body
+	default:
+		req.Body, _ = json.Marshal(string(body))
+	}
+	rec.mu.Lock()
+	rec.entry.Reqs = append(rec.entry.Reqs, req)
+	rec.mu.Unlock()
+}
+
+// done writes the entry, if any request is recorded.
+// Failures to write are logged, without failing the action.
+func (rec *auditRec) done(err error) {
+	if rec == nil {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.entry.Reqs) < 1 {
+		return
+	}
+	if err != nil {
+		rec.entry.Err = err.Error()
+	}
+	rec.entry.Time = time.Now().Format(time.RFC3339)
+	if err = auditWrite(auditFile, &rec.entry); err != nil {
+		Log(true, false, "failed to write audit log", auditFile, err)
+	}
+}
+
+// auditWrite appends an entry to file, with its number set
+func auditWrite(file string, entry *auditEntry) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if auditCount < 0 {
+		b, err := os.ReadFile(file)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		auditCount = bytes.Count(b, []byte("\n"))
+	}
+	entry.Entry = auditCount + 1
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	auditCount++
+	Log(false, false, "audit entry", entry.Entry, "written")
+	return nil
+}
+
+// auditPrior fetches values to be changed by actions that can be undone
+func auditPrior(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	action string, inf IssueInfos) IssueInfos {
+	b, ok := backendOf(svr)
+	if !ok || len(inf[IssueinfoStrID]) < 1 {
+		return nil
+	}
+	c, ok := b.(caseBackend)
+	if !ok {
+		return nil
+	}
+	funcs := c.cases()
+	// not to change inf
+	inf1 := IssueInfos{IssueinfoStrID: inf[IssueinfoStrID]}
+	var (
+		key string
+		res IssueInfoSlc
+		err error
+	)
+	switch action {
+	case "transfer a case to someone":
+		key = IssueinfoStrAssignee
+	case "move status of a case",
+		"reject a case from any known statuses",
+		"close a case to resolved from any known statuses":
+		key = IssueinfoStrState
+	case "watch a case", "unwatch a case":
+		if funcs.watcherList == nil {
+			return nil
+		}
+		if res, err = funcs.watcherList(ctx, svr, authInfo, inf1); err != nil {
+			Log(true, false, "failed to get watchers for audit log", err)
+			return nil
+		}
+		watching := slices.ContainsFunc(res, func(w IssueInfos) bool {
+			return w[IssueinfoStrID] == authInfo.User
+		})
+		return IssueInfos{auditWatching: strconv.FormatBool(watching)}
+	default:
+		return nil
+	}
+	if funcs.detail == nil {
+		return nil
+	}
+	if res, err = funcs.detail(ctx, svr, authInfo, inf1); err != nil ||
+		len(res) < 1 {
+		Log(true, false, "failed to get", key, "for audit log", err)
+		return nil
+	}
+	return IssueInfos{key: res[0][key]}
+}
+
+// auditEntryOf reads entry n from file
+func auditEntryOf(file string, n int) (entry auditEntry, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	rd := bufio.NewReader(f)
+	for i := 1; ; i++ {
+		line, err := rd.ReadBytes('\n')
+		if i == n && len(line) > 0 {
+			if err = json.Unmarshal(line, &entry); err != nil {
+				return entry, fmt.Errorf("%w: entry %d of %s: %w",
+					errCfg, n, file, err)
+			}
+			// by line, even if written by multiple processes
+			entry.Entry = n
+			return entry, nil
+		}
+		if err == io.EOF {
+			return entry, fmt.Errorf("%w: entry %d of %s",
+				eztools.ErrOutOfBound, n, file)
+		}
+		if err != nil {
+			return entry, err
+		}
+	}
+}
+
+// auditNoUndo makes an error of an entry that cannot be undone
+func auditNoUndo(entry auditEntry, why string) error {
+	return fmt.Errorf("%w: NO undo of entry %d, %s, for %s",
+		eztools.ErrInvalidInput, entry.Entry, entry.Action, why)
+}
+
+// auditInverse makes the inverse action of an entry
+// Return values: the action, and input for each run of it
+func auditInverse(entry auditEntry) (string, []IssueInfos, error) {
+	switch entry.Action {
+	case "transfer a case to someone":
+		assignee := entry.Prior[IssueinfoStrAssignee]
+		if len(assignee) < 1 {
+			return "", nil, auditNoUndo(entry, "no assignee before it")
+		}
+		return entry.Action, []IssueInfos{{IssueinfoStrID: entry.ID,
+			IssueinfoStrSummary: assignee}}, nil
+	case "move status of a case",
+		"reject a case from any known statuses",
+		"close a case to resolved from any known statuses":
+		state := entry.Prior[IssueinfoStrState]
+		if len(state) < 1 {
+			return "", nil, auditNoUndo(entry, "no status before it")
+		}
+		inf := IssueInfos{IssueinfoStrID: entry.ID,
+			IssueinfoStrComments: "undo of " + module + " audit entry " +
+				strconv.Itoa(entry.Entry)}
+		// Bugzilla takes the state as a project, as by -p
+		switch entry.Type {
+		case CategoryJira:
+			inf[IssueinfoStrToState] = state
+		default:
+			inf[IssueinfoStrProj] = state
+		}
+		return "move status of a case", []IssueInfos{inf}, nil
+	case "watch a case", "unwatch a case":
+		watching, ok := entry.Prior[auditWatching]
+		if !ok {
+			return "", nil, auditNoUndo(entry, "no watchers before it")
+		}
+		if (watching == "true") == (entry.Action == "watch a case") {
+			return "", nil, auditNoUndo(entry, "nothing changed by it")
+		}
+		action := "watch a case"
+		if entry.Action == action {
+			action = "unwatch a case"
+		}
+		return action, []IssueInfos{{IssueinfoStrID: entry.ID}}, nil
+	case "abandon a submit", "abandon all my open submits":
+		return auditInverseGerrit(entry, "/abandon", "restore a submit")
+	case "restore a submit":
+		return auditInverseGerrit(entry, "/restore", "abandon a submit")
+	}
+	return "", nil, auditNoUndo(entry, "no inverse action")
+}
+
+// auditInverseGerrit makes the inverse action on each change
+// in requests of an entry, which are POST changes/ID/action
+func auditInverseGerrit(entry auditEntry,
+	action, inverse string) (string, []IssueInfos, error) {
+	var infs []IssueInfos
+	for _, req := range entry.Reqs {
+		rest, ok := strings.CutSuffix(req.URL, action)
+		if !ok {
+			continue
+		}
+		if _, id, ok := strings.Cut(rest, "/changes/"); ok && len(id) > 0 {
+			infs = append(infs, IssueInfos{IssueinfoStrID: id})
+		}
+	}
+	if len(infs) < 1 {
+		return "", nil, auditNoUndo(entry, "no changes in it")
+	}
+	return inverse, infs, nil
+}
+
+// auditUndo runs the inverse action of an entry in the audit log,
+// on the server of the entry
+func auditUndo(ctx context.Context, cats cat2Act, para params) []error {
+	if len(auditFile) < 1 {
+		return []error{fmt.Errorf("%w: NO audit log, opted out in config", errCfg)}
+	}
+	n, err := strconv.Atoi(para.undo)
+	if err != nil || n < 1 {
+		return []error{fmt.Errorf("%w: entry %q to undo",
+			eztools.ErrInvalidInput, para.undo)}
+	}
+	entry, err := auditEntryOf(auditFile, n)
+	if err != nil {
+		return []error{err}
+	}
+	svr := matchSvr(cfg.Svrs, entry.Server)
+	if svr == nil || svr.Type != entry.Type {
+		return []error{fmt.Errorf("%w: server %s of %s NOT found",
+			errCfg, entry.Server, entry.Type)}
+	}
+	action, infs, err := auditInverse(entry)
+	if err != nil {
+		return []error{err}
+	}
+	funs := matchFuncFromParam(action, svr, cats)
+	if funs == nil {
+		return []error{auditNoUndo(entry, "no action of "+action)}
+	}
+	Log(true, false, "undoing entry", n, "by", action)
+	auditUndone = n
+	defer func() {
+		auditUndone = 0
+	}()
+	var errs []error
+	for _, inf := range infs {
+		errs = append(errs, mainLoop(ctx, svr, cats, funs, inf, para)...)
+		if errors.Is(errors.Join(errs...), errIntr) {
+			break
+		}
+	}
+	return errs
+}
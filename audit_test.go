@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// auditFakeSvr makes a fake server of a type, with fixtures of it
+func auditFakeSvr(t *testing.T, tp string, reqs []fakeReq) *fakeSvr {
+	dir, err := filepath.Abs(filepath.Join("testdata", strings.ToLower(tp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newFakeSvr(t, tp, dir, reqs)
+}
+
+func TestAuditUndo(t *testing.T) {
+	silent, user, svrsCfg := uiSilent, cfg.User, cfg.Svrs
+	defer func() {
+		uiSilent, cfg.User, cfg.Svrs = silent, user, svrsCfg
+		auditFile, auditCount = "", -1
+	}()
+	cfg.User = fakeUser
+	auditFile, auditCount = filepath.Join(t.TempDir(), "audit.jsonl"), -1
+	for i, c := range []struct {
+		tp, action string
+		inf        IssueInfos
+		// reqs are of prior values, if any, and the action
+		reqs  []fakeReq
+		prior IssueInfos
+		// inverse is the inverse action, with its first input
+		inverse string
+		infInv  IssueInfos
+	}{
+		{CategoryJira, "transfer a case to someone",
+			IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrSummary: "alice"},
+			[]fakeReq{{req: "GET " + jiraFakeIssue, resp: "issue.json"},
+				{req: "PUT " + jiraFakeIssue + ` {"set":{"name":"alice"}}`}},
+			IssueInfos{IssueinfoStrAssignee: "tester"},
+			"transfer a case to someone",
+			IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrSummary: "tester"}},
+		{CategoryJira, "unwatch a case", IssueInfos{IssueinfoStrID: "X-1"},
+			[]fakeReq{{req: "GET " + jiraFakeIssue + "/watchers",
+				resp: "watchers.json"},
+				{req: "DELETE " + jiraFakeIssue + "/watchers?username=tester"}},
+			IssueInfos{auditWatching: "true"},
+			"watch a case", IssueInfos{IssueinfoStrID: "X-1"}},
+		{CategoryBugzilla, "move status of a case",
+			IssueInfos{IssueinfoStrID: "1", IssueinfoStrProj: "RESOLVED"},
+			append([]fakeReq{{req: "GET " + bugzillaFakeBug, resp: "bug.json"}},
+				append(slices.Clone(bugzillaFakeTrans), fakeReq{
+					req:  "PUT " + bugzillaFakeBug + ` {"status":"RESOLVED"}`,
+					resp: "update.json"})...),
+			IssueInfos{IssueinfoStrState: "CONFIRMED"},
+			"move status of a case",
+			IssueInfos{IssueinfoStrID: "1", IssueinfoStrProj: "CONFIRMED"}},
+		{CategoryGerrit, "abandon all my open submits", nil,
+			[]fakeReq{{req: gerritFakeMyOpen, resp: "changes.json"},
+				{req: "POST " + gerritFakeChg + "/abandon", resp: "change.json"}},
+			nil, "restore a submit", IssueInfos{IssueinfoStrID: gerritFakeID}},
+	} {
+		f := auditFakeSvr(t, c.tp, c.reqs)
+		inf := make(IssueInfos)
+		maps.Copy(inf, c.inf)
+		if _, errs := f.run(c.action, inf); errs != nil {
+			t.Error(c.action, errs)
+		}
+		f.Close()
+		entry, err := auditEntryOf(auditFile, i+1)
+		if err != nil {
+			t.Fatal(c.action, err)
+		}
+		if entry.Action != c.action || entry.Server != f.svr.Name ||
+			len(entry.Reqs) != 1 || len(entry.Prior) != len(c.prior) {
+			t.Error(c.action, "entry got", entry)
+		}
+		for k, v := range c.prior {
+			if entry.Prior[k] != v {
+				t.Errorf("%s: prior %s=%q expected, got %q",
+					c.action, k, v, entry.Prior[k])
+			}
+		}
+		action, infs, err := auditInverse(entry)
+		if err != nil || action != c.inverse || len(infs) < 1 {
+			t.Fatal(c.action, "inverse got", action, infs, err)
+		}
+		for k, v := range c.infInv {
+			if infs[0][k] != v {
+				t.Errorf("%s: inverse %s=%q expected, got %q",
+					c.action, k, v, infs[0][k])
+			}
+		}
+	}
+	// to the prior status by its own key for Jira
+	if _, infs, err := auditInverse(auditEntry{Type: CategoryJira,
+		Action: "move status of a case", ID: "X-1",
+		Prior: IssueInfos{IssueinfoStrState: "Open"}}); err != nil ||
+		infs[0][IssueinfoStrToState] != "Open" ||
+		len(infs[0][IssueinfoStrProj]) > 0 {
+		t.Error("to_status of Open expected, got", infs, err)
+	}
+	// nothing changed by watching a case watched
+	if _, _, err := auditInverse(auditEntry{Action: "watch a case",
+		Prior: IssueInfos{auditWatching: "true"}}); !errors.Is(err,
+		eztools.ErrInvalidInput) {
+		t.Error("no undo expected, got", err)
+	}
+	// nothing logged getting something
+	f := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "GET " + jiraFakeIssue, resp: "issue.json"}})
+	f.run("show details of a case", IssueInfos{IssueinfoStrID: "X-1"})
+	f.Close()
+	if _, err := auditEntryOf(auditFile, 5); !errors.Is(err,
+		eztools.ErrOutOfBound) {
+		t.Error("no entry 5 expected, got", err)
+	}
+	// restore the changes abandoned, on the server in the entry
+	f = auditFakeSvr(t, CategoryGerrit, []fakeReq{
+		{req: "POST " + gerritFakeChg + "/restore", resp: "change.json"}})
+	defer f.Close()
+	cfg.Svrs = []svrs{f.svr}
+	if errs := auditUndo(context.Background(), makeCat2Act(),
+		params{undo: "4"}); errs != nil {
+		t.Error(errs)
+	}
+	entry, err := auditEntryOf(auditFile, 5)
+	if err != nil || entry.Action != "restore a submit" || entry.Undo != 4 {
+		t.Error("undo of entry 4 expected, got", entry, err)
+	}
+	b, err := os.ReadFile(auditFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{fakePass, fakeToken} {
+		if strings.Contains(string(b), s) {
+			t.Error(s, "in audit log")
+		}
+	}
+}
+
+func TestAuditOff(t *testing.T) {
+	user, svrsCfg := cfg.User, cfg.Svrs
+	defer func() {
+		cfg.User, cfg.Svrs = user, svrsCfg
+	}()
+	cfg.User = fakeUser
+	// no prior values fetched, without an audit log
+	f := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "PUT " + jiraFakeIssue + ` {"set":{"name":"alice"}}`}})
+	defer f.Close()
+	if _, errs := f.run("transfer a case to someone", IssueInfos{
+		IssueinfoStrID: "X-1", IssueinfoStrSummary: "alice"}); errs != nil {
+		t.Error(errs)
+	}
+	if errs := auditUndo(context.Background(), makeCat2Act(),
+		params{undo: "1"}); !errors.Is(errors.Join(errs...), errCfg) {
+		t.Error("config error expected, got", errs)
+	}
+}
+
+func TestAuditLogFile(t *testing.T) {
+	audit, file := cfg.Audit, cfgFile
+	defer func() {
+		cfg.Audit, cfgFile = audit, file
+	}()
+	cfgFile = filepath.Join("d", "jirrit.xml")
+	for _, c := range []struct{ audit, exp string }{
+		{"", filepath.Join("d", "jirrit.audit.jsonl")},
+		{"a.jsonl", "a.jsonl"},
+		{auditNone, ""}} {
+		cfg.Audit = c.audit
+		if got := auditLogFile(); got != c.exp {
+			t.Errorf("%q expected for %q, got %q", c.exp, c.audit, got)
+		}
+	}
+}
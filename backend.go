@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// Backend is a type of servers, registered by regBackend
+type Backend interface {
+	// Type is the type of servers in config
+	Type() string
+	// PassType is the recommended type of passwords
+	PassType() string
+	// Magic is the recommended magic for config, if any
+	Magic() string
+	// Actions are all actions supported, in order of choices
+	Actions() []action2Func
+	// ListMine lists issues of current user for IDs to choose from,
+	// with keys of fields shown besides IDs.
+	// nil is returned if not supported.
+	ListMine() (actionFunc, string, string)
+	// Input asks for info specific to the action, and updates inf.
+	// Return true if not enough info is given, false otherwise.
+	Input(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+		action string, inf IssueInfos) bool
+}
+
+// capabilities of backends, found by type assertions
+type (
+	// caseBackend is a backend of cases, with functions of shared actions
+	caseBackend interface {
+		cases() caseFuncs
+	}
+	// passHinter explains passwords for the type of servers,
+	// besides the recommended type
+	passHinter interface {
+		PassHint() string
+	}
+	// idReuser takes previous ID as default, when prompting for one
+	idReuser interface {
+		// parseID parses previous ID.
+		// Return values: base for smart affix,
+		// whether project is changed, whether parsed
+		parseID(svr *svrs, id string) (string, bool, bool)
+		// changeID makes a new ID from input and the parsed previous one.
+		// Return value: whether input is changed
+		changeID(svr *svrs, s, base string, smart, changes bool) (string, bool)
+	}
+	// actHider hides some actions from choices
+	actHider interface {
+		hideAct(svr svrs, action string) bool
+	}
+)
+
+// caseFuncs are functions of actions shared by backends of cases,
+// nil for those not supported
+type caseFuncs struct {
+	myOpen, detail, comments, addComment,
+	transition, reject, close,
+	addFile, listFile, getFile,
+	watcherList, watcherAdd, watcherDel,
+	transfer, link actionFunc
+}
+
+// caseActs are actions shared by backends of cases,
+// in order of choices
+var caseActs = []struct {
+	n string
+	f func(caseFuncs) actionFunc
+}{
+	{"transfer a case to someone",
+		func(c caseFuncs) actionFunc { return c.transfer }},
+	{"move status of a case",
+		func(c caseFuncs) actionFunc { return c.transition }},
+	{"show details of a case",
+		func(c caseFuncs) actionFunc { return c.detail }},
+	{"list comments of a case",
+		func(c caseFuncs) actionFunc { return c.comments }},
+	{"add a comment to a case",
+		func(c caseFuncs) actionFunc { return c.addComment }},
+	{"list my open cases",
+		func(c caseFuncs) actionFunc { return c.myOpen }},
+	{"link a case to another",
+		func(c caseFuncs) actionFunc { return c.link }},
+	{"list watchers of a case",
+		func(c caseFuncs) actionFunc { return c.watcherList }},
+	{"watch a case",
+		func(c caseFuncs) actionFunc { return c.watcherAdd }},
+	{"unwatch a case",
+		func(c caseFuncs) actionFunc { return c.watcherDel }},
+	{"add a file to a case",
+		func(c caseFuncs) actionFunc { return c.addFile }},
+	{"list files attached to a case",
+		func(c caseFuncs) actionFunc { return c.listFile }},
+	{"get a file to a case",
+		func(c caseFuncs) actionFunc { return c.getFile }},
+	{"reject a case from any known statuses",
+		func(c caseFuncs) actionFunc { return c.reject }},
+	{"close a case to resolved from any known statuses",
+		func(c caseFuncs) actionFunc { return c.close }},
+}
+
+// caseActsOf lists shared actions of cases a backend supports
+func caseActsOf(b Backend) (ret []action2Func) {
+	c, ok := b.(caseBackend)
+	if !ok {
+		return
+	}
+	funcs := c.cases()
+	for _, act := range caseActs {
+		if f := act.f(funcs); f != nil {
+			ret = append(ret, action2Func{act.n, f})
+		}
+	}
+	return
+}
+
+// inputIssueInfo4Case asks for input of shared actions of cases,
+// leaving specific ones to backends.
+// Return true if not enough info is given, false otherwise.
+func inputIssueInfo4Case(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, action string, inf IssueInfos) bool {
+	switch action {
+	case "close a case to resolved from any known statuses",
+		"reject a case from any known statuses":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPrompt(svr, inf, IssueinfoStrComments)
+	case "move status of a case",
+		"show details of a case",
+		"list comments of a case",
+		"list files attached to a case",
+		"list watchers of a case",
+		"watch a case",
+		"unwatch a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "link a case to another":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+			"ID (not indexes above, if any) this issue blocks")
+	case "add a file to a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPrompt(svr, inf, IssueinfoStrFile)
+	case "get a file to a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrKey, "file ID")
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrFile, "file to be saved as")
+	case "add a comment to a case":
+		if useInputOrPrompt(svr, inf, IssueinfoStrComments) {
+			return true
+		}
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "transfer a case to someone":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrSummary, "assignee")
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrComments, "component")
+	}
+	return false
+}
+
+var (
+	// backends are registered ones by types
+	backends = make(map[string]Backend)
+	// svrTypes are types of registered backends, in order of registration
+	svrTypes []string
+)
+
+// regBackend registers a backend, to be called in init()
+func regBackend(b Backend) {
+	if _, ok := backends[b.Type()]; !ok {
+		svrTypes = append(svrTypes, b.Type())
+	}
+	backends[b.Type()] = b
+}
+
+// backendOf gets the backend of a server
+func backendOf(svr *svrs) (Backend, bool) {
+	if svr == nil {
+		return nil, false
+	}
+	b, ok := backends[svr.Type]
+	return b, ok
+}
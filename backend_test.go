@@ -0,0 +1,81 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBackendCaseActs(t *testing.T) {
+	for _, tp := range []string{CategoryJira, CategoryBugzilla} {
+		b, ok := backends[tp]
+		if !ok {
+			t.Fatal(tp, "not registered")
+		}
+		acts := b.Actions()
+		i := 0
+		for _, act := range caseActs {
+			for i < len(acts) && acts[i].n != act.n {
+				i++
+			}
+			if i >= len(acts) {
+				t.Error(tp, "lacks shared action in order", act.n)
+				break
+			}
+		}
+		if f, _, _ := b.ListMine(); f == nil {
+			t.Error(tp, "lacks list of my open cases")
+		}
+	}
+	for _, tp := range []string{CategoryGerrit, CategoryJenkins} {
+		if n := len(caseActsOf(backends[tp])); n != 0 {
+			t.Error(tp, "has", n, "shared actions of cases")
+		}
+	}
+}
+
+func TestBackendJiraOrder(t *testing.T) {
+	// former choices keep their numbers
+	exp := []string{
+		"transfer a case to someone",
+		"move status of a case",
+		"show details of a case",
+		"list comments of a case",
+		"add a comment to a case",
+		"delete a comment from a case",
+		"change a comment from a case",
+		"list my open cases",
+		"link a case to another",
+		"list watchers of a case",
+		"check whether watching a case",
+		"watch a case",
+		"unwatch a case",
+		"add a file to a case",
+		"list files attached to a case",
+		"get a file to a case",
+		"remove a file attached to a case",
+		"reject a case from any known statuses",
+		"close a case to resolved from any known statuses",
+		"close a case with default design as steps",
+		"close a case with general requirement as steps",
+	}
+	acts := backends[CategoryJira].Actions()
+	for i, n := range exp {
+		if i >= len(acts) || acts[i].n != n {
+			t.Fatal(n, "expected as choice", i)
+		}
+	}
+}
+
+func TestBackendHide(t *testing.T) {
+	const act = "close a case with default design as steps"
+	svr := svrs{Type: CategoryJira}
+	choices, funcs := makeActs2Choose(svr, backends[CategoryJira].Actions())
+	if len(choices) != len(funcs) || slices.Contains(choices, act) {
+		t.Error(act, "not hidden without test fields")
+	}
+	svr.Flds.TstStep = "customfield_10200"
+	if choices, _ = makeActs2Choose(svr,
+		backends[CategoryJira].Actions()); !slices.Contains(choices, act) {
+		t.Error(act, "hidden with test fields")
+	}
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -48,7 +50,7 @@ func parseTypicalBZNum(svr *svrs, num string) (nonDigit,
 				// parts[0]=""
 				if len(parts) == 2 && len(parts[1]) > 0 {
 					if eztools.Debugging && eztools.Verbose > 1 {
-						eztools.ShowStrln("Auto changing to " +
+						showStrln("Auto changing to " +
 							svr.Proj + typicalJiraSeparator + parts[1])
 					}
 					return svr.Proj + typicalJiraSeparator, parts[1], true, true
@@ -60,7 +62,7 @@ func parseTypicalBZNum(svr *svrs, num string) (nonDigit,
 }*/
 
 // BugzillaTransfer transfer an issue to someone else, and additionally to a component
-func BugzillaTransfer(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaTransfer(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrSummary]) < 1 {
@@ -83,10 +85,10 @@ func BugzillaTransfer(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, issueInfo[IssueinfoStrID]+" in transition")
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPut,
+	_, err = restSth(ctx, http.MethodPut,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"?",
 			"", authInfo),
@@ -108,7 +110,7 @@ func bugzillaChooseState(svr *svrs, issueInfo IssueInfos,
 		if reso, ok = issueInfo[IssueinfoStrLink]; ok {
 			break
 		}
-		if indx, res := eztools.ChooseStrings(resos); indx != eztools.InvalidID {
+		if indx, res := chooseStrings(resos); indx != eztools.InvalidID {
 			reso = res
 		}
 	}
@@ -143,9 +145,9 @@ func bugzillaChooseTran(tranName string,
 			noInteractionAllowed()
 			return "", false, eztools.ErrInvalidInput
 		}
-		eztools.ShowStrln(
+		showStrln(
 			"There are following transitions available.")
-		i, _ := eztools.ChooseStrings(tranNames)
+		i, _ := chooseStrings(tranNames)
 		if i == eztools.InvalidID {
 			return "", false, eztools.ErrInvalidInput
 		}
@@ -156,14 +158,14 @@ func bugzillaChooseTran(tranName string,
 }
 
 // bugzillaTranExec transition issue {id} to state {tranID}
-func bugzillaTranExec(svr *svrs, authInfo eztools.AuthInfo,
+func bugzillaTranExec(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	id, cmt, tranID string, cmtReq bool, body any) (IssueInfoSlc, error) {
 	issueInfo1 := makeIssueInfo()
 	issueInfo1[IssueinfoStrID] = id
 	if body == nil {
 		if cmtReq {
 			if len(cmt) < 1 {
-				cmt = eztools.PromptStr(IssueinfoStrComments)
+				cmt = promptStr(IssueinfoStrComments)
 				if len(cmt) < 1 {
 					return issueInfo1.ToSlc(),
 						eztools.ErrInvalidInput
@@ -188,10 +190,10 @@ func bugzillaTranExec(svr *svrs, authInfo eztools.AuthInfo,
 		Log(false, false, id, "in transition to",
 			tranID, "w/t comment", cmt)
 		if eztools.Verbose > 1 {
-			eztools.ShowStrln(jsonStr)
+			showStrln(jsonStr)
 		}
 	}
-	bodyMap, err := restMap(http.MethodPut,
+	bodyMap, err := restMap(ctx, http.MethodPut,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			id+"?", "", authInfo),
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
@@ -199,8 +201,8 @@ func bugzillaTranExec(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // bugzillaTranFromAvail is transitions for reject & close
-func bugzillaTranFromAvail(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos, steps []string,
+func bugzillaTranFromAvail(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos, steps []string,
 	funcBody func(tranID string, tranCmtReq bool) any) (
 	IssueInfoSlc, error) {
 	var (
@@ -212,11 +214,11 @@ func bugzillaTranFromAvail(svr *svrs, authInfo eztools.AuthInfo,
 	)
 	for i, tran := range steps {
 		if eztools.Debugging && eztools.Verbose > 1 {
-			eztools.ShowStrln("Trying " + tran)
+			showStrln("Trying " + tran)
 		}
 		if tranNames == nil || len(tranNames) < 1 {
 			stt, tranNames, tranCmtReqs, err =
-				bugzillaGetTrans(svr, authInfo, issueInfo, stt)
+				bugzillaGetTrans(ctx, svr, authInfo, issueInfo, stt)
 			if err != nil {
 				return nil, err
 			}
@@ -231,7 +233,7 @@ func bugzillaTranFromAvail(svr *svrs, authInfo eztools.AuthInfo,
 			if i == len(steps)-1 {
 				// return error if the last step fails,
 				// since it is a key one
-				if err == eztools.ErrNoValidResults {
+				if errors.Is(err, eztools.ErrNoValidResults) {
 					Log(true, false, "No available transitions. Check permission!")
 				}
 				return nil, err
@@ -240,11 +242,11 @@ func bugzillaTranFromAvail(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		tranNames = nil
 		if funcBody == nil {
-			ret, err = bugzillaTranExec(svr, authInfo,
+			ret, err = bugzillaTranExec(ctx, svr, authInfo,
 				issueInfo[IssueinfoStrID], issueInfo[IssueinfoStrComments],
 				tranID, tranCmtReq, nil)
 		} else {
-			ret, err = bugzillaTranExec(svr, authInfo,
+			ret, err = bugzillaTranExec(ctx, svr, authInfo,
 				issueInfo[IssueinfoStrID], issueInfo[IssueinfoStrComments],
 				tranID, tranCmtReq, funcBody(tranID, tranCmtReq))
 		}
@@ -264,7 +266,7 @@ func bugzillaTranFromAvail(svr *svrs, authInfo eztools.AuthInfo,
 //
 //	If there are multiple steps, and comment is provided,
 //	it is added during all steps!
-func BugzillaReject(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaReject(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	Steps := makeStates(svr, StateTypeTranRej)
 	if Steps == nil {
@@ -279,7 +281,7 @@ func BugzillaReject(svr *svrs, authInfo eztools.AuthInfo,
 				issueInfo, reso, false, tranID, cmtReq)
 		}
 	}
-	return bugzillaTranFromAvail(svr, authInfo, issueInfo, Steps,
+	return bugzillaTranFromAvail(ctx, svr, authInfo, issueInfo, Steps,
 		makeBody)
 }
 
@@ -298,7 +300,7 @@ func inputMultiple(cfg, ans []string) (ret string) {
 		if len(ans) > 0 { // use ans if cfg is < 2
 			return strings.Join(ans, "")
 		}
-		return eztools.PromptStr("what to input")
+		return promptStr("what to input")
 	}
 	ansLen := len(ans)
 	var ansI int
@@ -309,7 +311,7 @@ func inputMultiple(cfg, ans []string) (ret string) {
 			continue
 		}
 		ret += cfg1
-		ret += eztools.PromptStr("what to append to \"" + cfg1 + "\"")
+		ret += promptStr("what to append to \"" + cfg1 + "\"")
 	}
 	for ; ansI < ansLen; ansI++ { // use ans
 		ret += ans[ansI]
@@ -377,7 +379,7 @@ func bugzillaBody4Tran(svr *svrs, issueInfo IssueInfos, reso string, solutionNee
 //
 //	If there are multiple steps, and comment is provided,
 //	it is added during all steps!
-func BugzillaClose(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaClose(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	Steps := makeStates(svr, StateTypeTranCls)
 	if Steps == nil {
@@ -392,16 +394,16 @@ func BugzillaClose(svr *svrs, authInfo eztools.AuthInfo,
 				issueInfo, reso, true, tranID, cmtReq)
 		}
 	}
-	return bugzillaTranFromAvail(svr, authInfo, issueInfo, Steps, makeBody)
+	return bugzillaTranFromAvail(ctx, svr, authInfo, issueInfo, Steps, makeBody)
 }
 
 // BugzillaTransition transitions an issue to a state
-func BugzillaTransition(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func BugzillaTransition(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	_, names, cmtReqs, err := bugzillaGetTrans(svr, authInfo,
+	_, names, cmtReqs, err := bugzillaGetTrans(ctx, svr, authInfo,
 		issueInfo, "")
 	if err != nil {
 		return nil, err
@@ -411,13 +413,13 @@ func BugzillaTransition(svr *svrs, authInfo eztools.AuthInfo,
 	if err != nil {
 		return nil, err
 	}
-	return bugzillaTranExec(svr, authInfo,
+	return bugzillaTranExec(ctx, svr, authInfo,
 		issueInfo[IssueinfoStrID], issueInfo[IssueinfoStrComments],
 		tranID, cmtReq, nil)
 }
 
 // BugzillaLink links two issues
-func BugzillaLink(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaLink(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrLink]) < 1 ||
@@ -446,10 +448,10 @@ func BugzillaLink(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, issueInfo[IssueinfoStrID]+" in transition")
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPut,
+	_, err = restSth(ctx, http.MethodPut,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"?", "", authInfo),
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
@@ -457,13 +459,13 @@ func BugzillaLink(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // BugzillaAddComment adds a comment to an issue
-func BugzillaAddComment(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func BugzillaAddComment(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrComments]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	inf, err := bugzillaAddComment1(svr, authInfo, issueInfo)
+	inf, err := bugzillaAddComment1(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -471,8 +473,8 @@ func BugzillaAddComment(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // bugzillaAddComment1 adds a comment to an issue, with no input checking
-func bugzillaAddComment1(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfos, error) {
+func bugzillaAddComment1(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfos, error) {
 	jsonStr, err := json.Marshal(map[string]string{
 		"comment": issueInfo[IssueinfoStrComments]})
 	if err != nil {
@@ -481,10 +483,10 @@ func bugzillaAddComment1(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, "commenting", issueInfo[IssueinfoStrID])
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPost,
+	_, err = restSth(ctx, http.MethodPost,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"/comment?", "", authInfo),
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
@@ -492,12 +494,12 @@ func bugzillaAddComment1(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // BugzillaComments lists comments of an issue
-func BugzillaComments(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaComments(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"/comment?",
 			"", authInfo), authInfo, nil, svr.Magic)
@@ -577,11 +579,11 @@ func bugzillaParseTran1(_ /*key*/ string, val any, stt string,
 //		available states
 //		whether comment required of a state
 //		error
-func bugzillaGetTrans(svr *svrs, authInfo eztools.AuthInfo,
+func bugzillaGetTrans(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, stt string) (string, []string, []bool, error) {
 	if len(stt) < 1 {
 		var ok bool
-		slcInf, err := BugzillaDetail(svr, authInfo, issueInfo)
+		slcInf, err := BugzillaDetail(ctx, svr, authInfo, issueInfo)
 		if err != nil || slcInf == nil || len(slcInf) != 1 {
 			return "", nil, nil, eztools.ErrOutOfBound
 		}
@@ -591,7 +593,7 @@ func bugzillaGetTrans(svr *svrs, authInfo eztools.AuthInfo,
 		}
 	}
 	const RestAPIBZStr = "rest/field/bug/"
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		bugzillaURIWtToken(svr.URL+RestAPIBZStr+
 			"bug_status?", "", authInfo),
 		authInfo, nil, svr.Magic)
@@ -634,21 +636,22 @@ func bugzillaGetTrans(svr *svrs, authInfo eztools.AuthInfo,
 	return stt, retStates, retCmts, nil
 }
 
-func bugzillaDetailExec(svr *svrs, authInfo eztools.AuthInfo,
+func bugzillaDetailExec(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (map[string]interface{}, error) {
-	return restMap(http.MethodGet,
+	return restMap(ctx, http.MethodGet,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"?",
 			"", authInfo), authInfo, nil, svr.Magic)
 }
 
 // BugzillaDetail show details of an issue
-func BugzillaDetail(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaDetail(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := bugzillaDetailExec(svr, authInfo, issueInfo)
+	bodyMap, err := bugzillaDetailExec(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -723,14 +726,14 @@ func bugzillaParse1Chg(v any) (issueInfoOut IssueInfos) {
 func bugzillaParse1Issue(m map[string]interface{}) (issueInfoOut IssueInfos) {
 	issueInfoOut = make(IssueInfos)
 	if eztools.Debugging && eztools.Verbose > 2 {
-		eztools.ShowStrln("parsing one issue")
+		showStrln("parsing one issue")
 	}
 	for i, v := range m {
 		if v == nil {
 			continue
 		}
 		if eztools.Debugging && eztools.Verbose > 2 {
-			eztools.ShowStr(i, "=", v, "\t")
+			showStr(i, "=", v, "\t")
 		}
 		switch i {
 		case IssueinfoStrChg:
@@ -741,6 +744,8 @@ func bugzillaParse1Issue(m map[string]interface{}) (issueInfoOut IssueInfos) {
 			val := chkNLoopStringMap(v, "",
 				[]string{IssueinfoStrRealName})
 			issueInfoOut[IssueinfoStrDispname] = val[0]
+		case "assigned_to":
+			issueInfoOut[IssueinfoStrAssignee] = chkNSetIssueInfo(v)
 		case IssueinfoStrProd:
 			issueInfoOut[IssueinfoStrProj] = chkNSetIssueInfo(v)
 		case IssueinfoStrState:
@@ -753,7 +758,7 @@ func bugzillaParse1Issue(m map[string]interface{}) (issueInfoOut IssueInfos) {
 		}
 	}
 	if eztools.Debugging && eztools.Verbose > 2 {
-		eztools.ShowStrln("")
+		showStrln("")
 	}
 	return
 }
@@ -807,7 +812,7 @@ func bugzillaParseComments(m map[string]interface{}) IssueInfoSlc {
 }
 
 // BugzillaMyOpen list all open issues of configured user
-func BugzillaMyOpen(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaMyOpen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	const RestAPIBZStr = "rest/bug?"
 	var states string
@@ -818,7 +823,7 @@ func BugzillaMyOpen(svr *svrs, authInfo eztools.AuthInfo,
 			}
 		}
 	}
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		bugzillaURIWtToken(svr.URL+RestAPIBZStr,
 			"assigned_to="+authInfo.User+states,
 			authInfo), authInfo, nil, svr.Magic)
@@ -828,12 +833,12 @@ func BugzillaMyOpen(svr *svrs, authInfo eztools.AuthInfo,
 	return bugzillaParseIssues(bodyMap), nil
 }
 
-func BugzillaWatcherList(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func BugzillaWatcherList(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := bugzillaDetailExec(svr, authInfo, issueInfo)
+	bodyMap, err := bugzillaDetailExec(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -851,8 +856,8 @@ func BugzillaWatcherList(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // BugzillaWatcherAdd adds user to cc
-func BugzillaWatcherAdd(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func BugzillaWatcherAdd(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
@@ -871,10 +876,10 @@ func BugzillaWatcherAdd(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, "watching", issueInfo[IssueinfoStrID])
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPut,
+	_, err = restSth(ctx, http.MethodPut,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"?", "", authInfo),
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
@@ -882,8 +887,8 @@ func BugzillaWatcherAdd(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // BugzillaWatcherDel removes user from cc
-func BugzillaWatcherDel(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func BugzillaWatcherDel(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
@@ -902,17 +907,17 @@ func BugzillaWatcherDel(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, "unwatching", issueInfo[IssueinfoStrID])
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPut,
+	_, err = restSth(ctx, http.MethodPut,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"?", "", authInfo),
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
 	return nil, err
 }
 
-func BugzillaAddFile(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaAddFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrFile]) < 1 ||
@@ -948,10 +953,10 @@ func BugzillaAddFile(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, "attaching to", issueInfo[IssueinfoStrID])
 		/*if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}*/
 	}
-	_, err = restMap(http.MethodPost,
+	_, err = restMap(ctx, http.MethodPost,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"/attachment?",
 			"", authInfo), authInfo,
@@ -959,12 +964,12 @@ func BugzillaAddFile(svr *svrs, authInfo eztools.AuthInfo,
 	return nil, err
 }
 
-func BugzillaListFile(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaListFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+
 			issueInfo[IssueinfoStrID]+"/attachment?",
 			"", authInfo), authInfo, nil, svr.Magic)
@@ -1034,9 +1039,9 @@ func bugzillaParseAttachments(bodyMap map[string]interface{}, tp string, dataNee
 	return
 }
 
-func bugzillaGetFileInf(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfos, error) {
-	inf, err := BugzillaListFile(svr, authInfo, issueInfo)
+func bugzillaGetFileInf(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfos, error) {
+	inf, err := BugzillaListFile(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return issueInfo, err
 	}
@@ -1051,7 +1056,7 @@ func bugzillaGetFileInf(svr *svrs, authInfo eztools.AuthInfo,
 			}
 		}
 	} else {
-		i := eztools.ChooseMaps(inf.ToMapSlc(), " (",
+		i := chooseMaps(inf.ToMapSlc(), " (",
 			IssueinfoStrFile, IssueinfoStrSize)
 		if i == eztools.InvalidID {
 			return issueInfo, eztools.ErrInvalidInput
@@ -1063,7 +1068,7 @@ func bugzillaGetFileInf(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // BugzillaGetFile saves an attachment
-func BugzillaGetFile(svr *svrs, authInfo eztools.AuthInfo,
+func BugzillaGetFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
@@ -1077,7 +1082,7 @@ func BugzillaGetFile(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		isDir = true
 	}
-	issueInfo, err = bugzillaGetFileInf(svr, authInfo, issueInfo)
+	issueInfo, err = bugzillaGetFileInf(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1088,7 +1093,7 @@ func BugzillaGetFile(svr *svrs, authInfo eztools.AuthInfo,
 		issueInfo[IssueinfoStrFile] = filepath.Join(issueInfo[IssueinfoStrFile],
 			issueInfo[IssueinfoStrName])
 	}
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		bugzillaURIWtToken(svr.URL+urlAPI4BZ+"attachment/"+
 			issueInfo[IssueinfoStrKey]+"?",
 			"", authInfo), authInfo, nil, svr.Magic)
@@ -1121,3 +1126,72 @@ func BugzillaGetFile(svr *svrs, authInfo eztools.AuthInfo,
 	}
 	return ret, nil
 }
+
+// bugzillaBackend is the backend of Bugzilla servers
+type bugzillaBackend struct{}
+
+func init() {
+	regBackend(bugzillaBackend{})
+}
+
+// Type is CategoryBugzilla
+func (bugzillaBackend) Type() string {
+	return CategoryBugzilla
+}
+
+// PassType is PassToken, from API Key settings
+func (bugzillaBackend) PassType() string {
+	return PassToken
+}
+
+// Magic is not needed
+func (bugzillaBackend) Magic() string {
+	return ""
+}
+
+// Actions are shared ones of cases
+func (b bugzillaBackend) Actions() []action2Func {
+	return caseActsOf(b)
+}
+
+// ListMine lists my open cases with products and summaries
+func (bugzillaBackend) ListMine() (actionFunc, string, string) {
+	return BugzillaMyOpen, IssueinfoStrProj, IssueinfoStrSummary
+}
+
+// Input asks for input of shared actions of cases, and specific ones
+func (bugzillaBackend) Input(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, action string, inf IssueInfos) bool {
+	if inputIssueInfo4Case(ctx, svr, authInfo, action, inf) {
+		return true
+	}
+	switch action {
+	case "move status of a case":
+		useInputOrPromptStr(svr, inf, IssueinfoStrComments,
+			IssueinfoStrComments+" (added to all statues during transition)")
+	case "add a file to a case":
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrKey, "description")
+	}
+	return false
+}
+
+// parseID takes no smart affix
+func (bugzillaBackend) parseID(*svrs, string) (string, bool, bool) {
+	return "", false, false
+}
+
+// changeID takes input as is
+func (bugzillaBackend) changeID(*svrs, string, string, bool, bool) (string, bool) {
+	return "", false
+}
+
+// cases are Bugzilla* functions
+func (bugzillaBackend) cases() caseFuncs {
+	return caseFuncs{myOpen: BugzillaMyOpen, detail: BugzillaDetail,
+		comments: BugzillaComments, addComment: BugzillaAddComment,
+		transition: BugzillaTransition, reject: BugzillaReject, close: BugzillaClose,
+		addFile: BugzillaAddFile, listFile: BugzillaListFile, getFile: BugzillaGetFile,
+		watcherList: BugzillaWatcherList, watcherAdd: BugzillaWatcherAdd,
+		watcherDel: BugzillaWatcherDel, transfer: BugzillaTransfer, link: BugzillaLink}
+}
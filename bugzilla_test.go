@@ -1,8 +1,11 @@
 package main
 
 import (
+	"net/http"
+	"slices"
 	"testing"
 
+	"gitee.com/bon-ami/eztools/v6"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -119,3 +122,122 @@ func TestBugzillaChangeComment(t *testing.T) {
 func TestBugzillaDeleteComment(t *testing.T) {
 	BugzillaTests(t, "delete a comment from a case", false)
 }
+
+const (
+	bugzillaFakeKey = "?Bugzilla_api_key=apikey"
+	bugzillaFakeBug = "/rest/bug/1" + bugzillaFakeKey
+)
+
+// bugzillaFakeTrans are requests to get available transitions of a case
+var bugzillaFakeTrans = []fakeReq{
+	{req: "GET " + bugzillaFakeBug, resp: "bug.json"},
+	{req: "GET /rest/field/bug/bug_status" + bugzillaFakeKey,
+		resp: "field_status.json"}}
+
+var bugzillaFakeCases = []fakeCase{
+	{action: "transfer a case to someone",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrSummary: "alice"},
+		reqs: []fakeReq{
+			{req: "PUT " + bugzillaFakeBug + ` {"assigned_to":"alice"}`,
+				resp: "update.json"}}},
+	{action: "move status of a case",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrProj: "RESOLVED"},
+		reqs: append(slices.Clone(bugzillaFakeTrans),
+			fakeReq{req: "PUT " + bugzillaFakeBug + ` {"status":"RESOLVED"}`,
+				resp: "update.json"}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "1",
+			IssueinfoStrState: "CONFIRMED -> RESOLVED"})},
+	{action: "move status of a case",
+		inf:  IssueInfos{IssueinfoStrID: "1", IssueinfoStrProj: "VERIFIED"},
+		reqs: bugzillaFakeTrans,
+		err:  eztools.ErrNoValidResults},
+	{action: "show details of a case",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: []fakeReq{
+			{req: "GET " + bugzillaFakeBug, resp: "bug.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "1",
+			IssueinfoStrProj: "Prod", IssueinfoStrState: "CONFIRMED",
+			IssueinfoStrDispname: "Test Er", IssueinfoStrAssignee: "tester"})},
+	{action: "show details of a case",
+		inf: IssueInfos{IssueinfoStrID: "9"},
+		reqs: []fakeReq{
+			{req: "GET /rest/bug/9" + bugzillaFakeKey,
+				code: http.StatusNotFound}},
+		err: eztools.ErrNoValidResults},
+	{action: "list comments of a case",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: []fakeReq{
+			{req: "GET /rest/bug/1/comment" + bugzillaFakeKey,
+				resp: "comments.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "100",
+			IssueinfoStrKey: "tester", IssueinfoStrComments: "Crashes at once."})},
+	{action: "add a comment to a case",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrComments: "Fixed."},
+		reqs: []fakeReq{
+			{req: "POST /rest/bug/1/comment" + bugzillaFakeKey +
+				` {"comment":"Fixed."}`, resp: "comment.json", code: http.StatusCreated}}},
+	{action: "list my open cases",
+		reqs: []fakeReq{
+			{req: "GET /rest/bug" + bugzillaFakeKey +
+				"&assigned_to=tester&status!=CLOSED", resp: "search.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "1"})},
+	{action: "link a case to another",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrLink: "2"},
+		reqs: []fakeReq{
+			{req: "PUT " + bugzillaFakeBug + ` {"blocks":{"add":[2]}}`,
+				resp: "update.json"}}},
+	{action: "list watchers of a case",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: []fakeReq{
+			{req: "GET " + bugzillaFakeBug, resp: "bug.json"}}},
+	{action: "watch a case",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: []fakeReq{
+			{req: "PUT " + bugzillaFakeBug + ` {"cc":{"add":["tester"]}}`,
+				resp: "update.json"}}},
+	{action: "unwatch a case",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: []fakeReq{
+			{req: "PUT " + bugzillaFakeBug + ` {"cc":{"remove":["tester"]}}`,
+				resp: "update.json"}}},
+	{action: "add a file to a case",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrKey: "build log",
+			IssueinfoStrFile: fakeUpload},
+		reqs: []fakeReq{
+			{req: "POST /rest/bug/1/attachment" + bugzillaFakeKey +
+				` "file_name":"` + fakeUpload + `"`,
+				resp: "ids.json", code: http.StatusCreated}}},
+	{action: "list files attached to a case",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: []fakeReq{
+			{req: "GET /rest/bug/1/attachment" + bugzillaFakeKey,
+				resp: "attachments.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrKey: "30",
+			IssueinfoStrFile: "log.txt", IssueinfoStrDesc: "crash log"})},
+	{action: "get a file to a case",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrKey: "30"},
+		reqs: []fakeReq{
+			{req: "GET /rest/bug/1/attachment" + bugzillaFakeKey,
+				resp: "attachments.json"},
+			{req: "GET /rest/bug/attachment/30" + bugzillaFakeKey,
+				resp: "attachment.json"}},
+		chk: fakeChkFile("log.txt", "hello\n")},
+	{action: "reject a case from any known statuses",
+		inf: IssueInfos{IssueinfoStrID: "1"},
+		reqs: append(slices.Clone(bugzillaFakeTrans),
+			fakeReq{req: "PUT " + bugzillaFakeBug +
+				` {"resolution":"INVALID","status":"REJECTED"}`,
+				resp: "update.json"}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "1"})},
+	{action: "close a case to resolved from any known statuses",
+		inf: IssueInfos{IssueinfoStrID: "1", IssueinfoStrComments: "Done."},
+		reqs: append(slices.Clone(bugzillaFakeTrans),
+			fakeReq{req: "PUT " + bugzillaFakeBug +
+				` {"status":"RESOLVED","resolution":"FIXED","cf_analysis_solution":"","comment":{"body":"Done."}}`,
+				resp: "update.json"}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrState: "CONFIRMED -> RESOLVED"})},
+}
+
+func TestBugzillaOffline(t *testing.T) {
+	fakeRun(t, CategoryBugzilla, bugzillaFakeCases)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// errIntr is the cause of contexts canceled by SIGINT
+var errIntr = errors.New("interrupted")
+
+// timeoutKey is the key of the timeout of requests in contexts
+type timeoutKey struct{}
+
+// withTimeout sets the timeout of each request in seconds to ctx,
+// from the server of actions, or -timeout.
+// No timeout if not positive.
+func withTimeout(ctx context.Context, secs int) context.Context {
+	return context.WithValue(ctx, timeoutKey{},
+		time.Duration(secs)*time.Second)
+}
+
+// reqCtx makes the context of a request from ctx, with the timeout of ctx,
+// if any, before it starts, unless ctx is done.
+// Cancellation of ctx does not stop the request, for it to finish,
+// while an expired one is stopped.
+// stop is to be called when the response is done with.
+func reqCtx(ctx context.Context) (ctxReq context.Context, stop func(),
+	err error) {
+	if ctx.Err() != nil {
+		return nil, nil, context.Cause(ctx)
+	}
+	ctxReq = context.WithoutCancel(ctx)
+	timeout, _ := ctx.Value(timeoutKey{}).(time.Duration)
+	if timeout <= 0 {
+		return ctxReq, func() {}, nil
+	}
+	ctxReq, stop = context.WithTimeoutCause(ctxReq, timeout,
+		fmt.Errorf("%w: no response in %v: %w", errConn, timeout,
+			context.DeadlineExceeded))
+	return ctxReq, stop, nil
+}
+
+// intrCtx makes a context canceled with errIntr by the first SIGINT,
+// while a second one terminates the process as usual.
+// stop is to be called when it is not needed any more.
+func intrCtx(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sig:
+			signal.Stop(sig)
+			Log(true, false, "Interrupted. Finishing current requests.",
+				"Interrupt again to quit at once.")
+			cancel(errIntr)
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sig)
+		close(done)
+		cancel(nil)
+	}
+}
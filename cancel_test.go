@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestRestTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	// the slow request is stopped, not left running
+	stopped := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-unblock:
+			case <-r.Context().Done():
+				close(stopped)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	defer close(unblock)
+	authInfo := eztools.AuthInfo{Type: eztools.AuthNone}
+	ctx := withTimeout(context.Background(), 1)
+	start := time.Now()
+	_, err := restMap(ctx, http.MethodGet, srv.URL+"/slow", authInfo, nil, "")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errConn) {
+		t.Error("timeout expected, got", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Error("timed out in", d)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Error("request timed out still running")
+	}
+	if m, err := restMap(ctx, http.MethodGet, srv.URL, authInfo,
+		nil, ""); err != nil || m["ok"] != true {
+		t.Error("unexpected result", m, err)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(errIntr)
+	if _, err := restMap(ctx, http.MethodGet, srv.URL, authInfo,
+		nil, ""); !errors.Is(err, errIntr) {
+		t.Error("no request expected after interruption, got", err)
+	}
+}
+
+func TestRestIntr(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(withTimeout(context.Background(),
+		5))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// interrupted while the request is running
+		cancel(errIntr)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	if m, err := restMap(ctx, http.MethodGet, srv.URL,
+		eztools.AuthInfo{Type: eztools.AuthNone}, nil, ""); err != nil ||
+		m["ok"] != true {
+		t.Error("request running expected to finish, got", m, err)
+	}
+}
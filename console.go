@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// logWriter takes logs and prompts, so that they do not mix with results
+// in outWriter. It is standard error for machine readable output formats.
+var logWriter io.Writer = os.Stdout
+
+func showStr(inf ...any) {
+	fmt.Fprint(logWriter, inf...)
+}
+
+func showStrln(inf ...any) {
+	fmt.Fprintln(logWriter, inf...)
+}
+
+func showByteln(b []byte) {
+	fmt.Fprintln(logWriter, string(b))
+}
+
+// readLn reads one line from standard input without buffering beyond it,
+// so that the line editor of the shell gets the rest
+func readLn() string {
+	var (
+		line []byte
+		b    = make([]byte, 1)
+	)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n < 1 || err != nil || b[0] == '\n' {
+			break
+		}
+		line = append(line, b[0])
+	}
+	return strings.TrimSuffix(string(line), "\r")
+}
+
+// promptStr shows a prompt and reads one line
+func promptStr(prompt string) string {
+	fmt.Fprint(logWriter, prompt+": ")
+	return readLn()
+}
+
+// chkCfmNPrompt asks for a confirmation, with def taken for an empty answer
+// return value: whether it is confirmed with y or Y
+func chkCfmNPrompt(prompt, def string) bool {
+	s := promptStr(prompt + " ([Y/y]es/[N/n]o, [Enter]=" + def + ")")
+	if len(s) < 1 {
+		s = def
+	}
+	return strings.EqualFold(s, "y")
+}
+
+// chooseStringsWtIDs lists choices with their IDs and asks for one
+// return value: ID chosen, or eztools.InvalidID
+func chooseStringsWtIDs(lenFunc func() int, idFunc func(int) int,
+	strFunc func(int) string, prompt string) int {
+	ids := make(map[string]int)
+	for i := 0; i < lenFunc(); i++ {
+		id := strconv.Itoa(idFunc(i))
+		ids[id] = idFunc(i)
+		fmt.Fprintln(logWriter, id+": "+strFunc(i))
+	}
+	if id, ok := ids[promptStr(prompt)]; ok {
+		return id
+	}
+	return eztools.InvalidID
+}
+
+// chooseStrings lists choices with their indexes and asks for one
+// return values: index and string chosen, or eztools.InvalidID
+func chooseStrings(choices []string) (int, string) {
+	i := chooseStringsWtIDs(func() int {
+		return len(choices)
+	}, func(i int) int {
+		return i
+	}, func(i int) string {
+		return choices[i]
+	}, "choose one")
+	if i == eztools.InvalidID {
+		return i, ""
+	}
+	return i, choices[i]
+}
+
+// chooseMaps lists values of keys of each map and asks for one.
+// Values are joined by sep, which is closed if it opens a parenthesis.
+// return value: index chosen, or eztools.InvalidID
+func chooseMaps(choices []map[string]string, sep string, keys ...string) int {
+	var closing string
+	if strings.HasSuffix(sep, "(") {
+		closing = ")"
+	}
+	return chooseStringsWtIDs(func() int {
+		return len(choices)
+	}, func(i int) int {
+		return i
+	}, func(i int) string {
+		var s string
+		for j, k := range keys {
+			if j > 0 {
+				s += sep
+			}
+			s += choices[i][k]
+		}
+		return s + strings.Repeat(closing, max(len(keys)-1, 0))
+	}, "choose one")
+}
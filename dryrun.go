@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// dryRun is set by -dry-run, not to send requests changing anything
+var dryRun bool
+
+// urlRedacted replaces secrets in URLs printed in dry run mode,
+// or saved in the audit log
+const urlRedacted = "REDACTED"
+
+// dryRunSkip prints a request, instead of sending it, in dry run mode,
+// unless it only gets something.
+// Parameters: method, url, body, and name of file to be sent, if any
+// Return value: whether it is skipped, to be taken as a success
+func dryRunSkip(method, url string, bodyReq io.Reader, fName string) bool {
+	if !dryRun || !mutating(method) {
+		return false
+	}
+	inf := []any{"DRY RUN:", method, redactURL(url)}
+	if bodyReq != nil {
+		body, err := io.ReadAll(bodyReq)
+		if err != nil {
+			body = []byte(err.Error())
+		}
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			body = indented.Bytes()
+		}
+		inf = append(inf, "\n"+string(body))
+	}
+	if len(fName) > 0 {
+		inf = append(inf, "\nfile:", fName)
+	}
+	Log(true, false, inf...)
+	Log(true, false, "DRY RUN: not sent. taken as a success.")
+	return true
+}
+
+// mutating checks whether a method may change anything
+func mutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// redactURL redacts user info and query values of keys, tokens
+// and passwords in a URL
+func redactURL(urlIn string) string {
+	u, err := url.Parse(urlIn)
+	if err != nil {
+		return urlIn
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), urlRedacted)
+	}
+	query := u.Query()
+	redacted := false
+	for k := range query {
+		lower := strings.ToLower(k)
+		for _, secret := range []string{"key", "token", "pass"} {
+			if strings.Contains(lower, secret) {
+				query.Set(k, urlRedacted)
+				redacted = true
+				break
+			}
+		}
+	}
+	if redacted {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// ErrReportJSON is the only format of -error-report
+	ErrReportJSON = "json"
+	// restErrBodyMax is the max size of response bodies kept in errors
+	restErrBodyMax = 512
+)
+
+var (
+	// errReportFmt is the format of -error-report, if any
+	errReportFmt string
+	// errReportWriter is where error reports go
+	errReportWriter io.Writer = os.Stderr
+)
+
+// restErr is an error of a REST request, with the HTTP status,
+// if got, and an excerpt of the response body.
+// It wraps errAuth, errGram and others by the status.
+type restErr struct {
+	method, url string
+	status      int
+	body        string
+	err         error
+}
+
+func (e *restErr) Error() string {
+	if e.status == 0 {
+		return e.err.Error()
+	}
+	return "HTTP " + strconv.Itoa(e.status) + ": " + e.err.Error()
+}
+
+func (e *restErr) Unwrap() error {
+	return e.err
+}
+
+// newRestErr makes a restErr, with the body cut to restErrBodyMax
+// and secrets in the URL redacted
+func newRestErr(method, url string, status int, body []byte,
+	err error) *restErr {
+	if len(body) > restErrBodyMax {
+		body = body[:restErrBodyMax]
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1]
+		}
+	}
+	return &restErr{method: method, url: redactURL(url), status: status,
+		body: strings.TrimSpace(string(body)), err: err}
+}
+
+// actErr is an error of an action on a server, for an ID if any
+type actErr struct {
+	svr, action, id string
+	err             error
+}
+
+func (e *actErr) Error() string {
+	if len(e.id) < 1 {
+		return e.action + ": " + e.err.Error()
+	}
+	return e.action + ": " + e.id + ": " + e.err.Error()
+}
+
+func (e *actErr) Unwrap() error {
+	return e.err
+}
+
+// errReport is a failure in an error report, for IDs to be retried
+type errReport struct {
+	Server string `json:"server,omitempty"`
+	Action string `json:"action,omitempty"`
+	// ID is the ID failed, or IDs skipped separated by commas
+	ID string `json:"id,omitempty"`
+	// Code is the exit code, as listed by -h
+	Code   int    `json:"code"`
+	Status int    `json:"status,omitempty"`
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Body   string `json:"body,omitempty"`
+	Error  string `json:"error"`
+}
+
+// setErrReport checks and sets the format of -error-report
+func setErrReport(f string) error {
+	if len(f) > 0 && f != ErrReportJSON {
+		Log(true, false, "error report format must be", ErrReportJSON)
+		return eztools.ErrInvalidInput
+	}
+	errReportFmt = f
+	return nil
+}
+
+// errReports makes reports of errors, one for each
+func errReports(errs []error) []errReport {
+	ret := make([]errReport, 0, len(errs))
+	for _, err := range errs {
+		r := errReport{Code: exitCode(err), Error: err.Error()}
+		var ae *actErr
+		if errors.As(err, &ae) {
+			r.Server, r.Action, r.ID = ae.svr, ae.action, ae.id
+		}
+		var re *restErr
+		if errors.As(err, &re) {
+			r.Status, r.Method, r.URL, r.Body = re.status, re.method,
+				re.url, re.body
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// errReportWrite writes reports of errors in format of -error-report,
+// if any, as an empty array for no errors
+func errReportWrite(errs []error) {
+	if errReportFmt != ErrReportJSON {
+		return
+	}
+	enc := json.NewEncoder(errReportWriter)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(errReports(errs)); err != nil {
+		Log(false, false, err)
+	}
+}
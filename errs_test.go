@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestErrReport(t *testing.T) {
+	silent, user, writer := uiSilent, cfg.User, errReportWriter
+	defer func() {
+		uiSilent, cfg.User, errReportWriter = silent, user, writer
+		setErrReport("")
+	}()
+	uiSilent, cfg.User = true, fakeUser
+	f := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "POST /rest/api/latest/issue/X-8/comment",
+			resp: "comment.json", code: http.StatusCreated},
+		{req: "POST /rest/api/latest/issue/X-9/comment",
+			resp: "error.json", code: http.StatusNotFound}})
+	defer f.Close()
+	_, errs := f.run("add a comment to a case",
+		IssueInfos{IssueinfoStrID: "X-8,X-9", IssueinfoStrComments: "hi"})
+	if len(errs) != 1 {
+		t.Fatal("1 error expected, got", errs)
+	}
+	var re *restErr
+	if !errors.Is(errs[0], eztools.ErrNoValidResults) ||
+		!errors.As(errs[0], &re) || re.status != http.StatusNotFound {
+		t.Fatal("404 expected, got", errs[0])
+	}
+	if exitCode(errs[0]) != extRslt {
+		t.Error("exit code of results expected, got", exitCode(errs[0]))
+	}
+
+	if err := setErrReport("xml"); !errors.Is(err, eztools.ErrInvalidInput) {
+		t.Error("invalid input expected, got", err)
+	}
+	if err := setErrReport(ErrReportJSON); err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	errReportWriter = &buf
+	errReportWrite(append(errs, errors.New("other")))
+	var reports []errReport
+	if err := json.Unmarshal([]byte(buf.String()), &reports); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if len(reports) != 2 {
+		t.Fatal("2 reports expected, got", reports)
+	}
+	r := reports[0]
+	if r.Server != f.svr.Name || r.Action != "add a comment to a case" ||
+		r.ID != "X-9" || r.Code != extRslt ||
+		r.Status != http.StatusNotFound || r.Method != http.MethodPost ||
+		!strings.HasSuffix(r.URL, "/issue/X-9/comment") ||
+		!strings.Contains(r.Body, "Issue Does Not Exist") {
+		t.Error("unexpected report", r)
+	}
+	if reports[1].Error != "other" || reports[1].Code != extOthr {
+		t.Error("unexpected report", reports[1])
+	}
+}
+
+func TestRestErrBody(t *testing.T) {
+	body := strings.Repeat("é", restErrBodyMax)
+	e := newRestErr(http.MethodGet, "u", http.StatusBadGateway,
+		[]byte(body), errSrvr)
+	if len(e.body) > restErrBodyMax || !strings.HasPrefix(body, e.body) {
+		t.Error("body cut at a char expected, got", len(e.body))
+	}
+	if e.Error() != "HTTP 502: "+errSrvr.Error() {
+		t.Error("unexpected error", e)
+	}
+	if exitCode(newRestErr("", "", http.StatusConflict, nil,
+		errors.New("409 Conflict"))) != extSrvr {
+		t.Error("exit code of servers expected for other statuses")
+	}
+}
+
+func TestRestErrConn(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+	_, err := restMap(context.Background(), http.MethodGet,
+		url+"/rest?token=secret", eztools.AuthInfo{Type: eztools.AuthNone},
+		nil, "")
+	var re *restErr
+	if !errors.Is(err, errConn) || !errors.As(err, &re) {
+		t.Fatal("connection error expected, got", err)
+	}
+	if re.method != http.MethodGet || strings.Contains(re.url, "secret") ||
+		!strings.Contains(re.url, urlRedacted) {
+		t.Error("unexpected request", re.method, re.url)
+	}
+	if exitCode(err) != extConn {
+		t.Error("exit code of connections expected, got", exitCode(err))
+	}
+	if exitCode(errors.New("other")) != extOthr {
+		t.Error("exit code of others expected")
+	}
+}
@@ -7,16 +7,15 @@ def main():
     try:
         data = json.loads(input_data)
     except json.JSONDecodeError as e:
-        print(f"Error decoding JSON: {e}")
+        print(f"Error decoding JSON: {e}", file=sys.stderr)
         sys.exit(1)
 
-    # print('Received data:')
-    # print(data)
-    # Check for the key-value pair
-    if 'name' in data and data['name'].startswith('MAD'):
-        sys.exit(0)
-    else:
-        sys.exit(1)
+    # print('Received data:', file=sys.stderr)
+    # print(data, file=sys.stderr)
+    # Check for the key-value pair in each result
+    print(json.dumps([
+        'name' in result and result['name'].startswith('MAD')
+        for result in data]))
 
 
 if __name__ == "__main__":
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	fakeUser   = "tester"
+	fakePass   = "secret"
+	fakeToken  = "apikey"
+	fakeMagic  = ")]}'"
+	fakeUpload = "upload.txt"
+	// fakeURL in fixtures is replaced with the URL of the fake server
+	fakeURL = "{{URL}}"
+	// fakeFiles is the directory of fixtures replied as attachments
+	fakeFiles = "files"
+)
+
+// fakeReq is a request expected by a fake server, and the reply to it
+type fakeReq struct {
+	// req is method and request URI, optionally followed by
+	// a string that the request body must contain, separated by spaces
+	req string
+	// resp is the fixture under testdata/<server type> to reply with.
+	// No content is replied if empty.
+	resp string
+	// code is the status code to reply with, if not a success
+	code int
+	// reqHdr is a header, as "Name: value", the request must have
+	reqHdr string
+	// hdr are headers to reply with, with fakeURL replaced
+	hdr map[string]string
+	// noDry is for a request not sent in dry run,
+	// as it needs the reply of a request skipped
+	noDry bool
+}
+
+// fakeCase is an offline test of one action against a fake server
+type fakeCase struct {
+	action string
+	inf    IssueInfos
+	// reqs are all requests the action should send, in order
+	reqs []fakeReq
+	// err is the error expected from the action, if any
+	err error
+	// chk checks results, if not nil
+	chk func(*testing.T, IssueInfoSlc)
+}
+
+// fakeNow is the current time of offline tests, a Wednesday
+var fakeNow = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+
+// fakeCases are offline tests for all server types
+var fakeCases = map[string][]fakeCase{
+	CategoryJira:     jiraFakeCases,
+	CategoryGerrit:   gerritFakeCases,
+	CategoryJenkins:  jenkinsFakeCases,
+	CategoryBugzilla: bugzillaFakeCases,
+}
+
+// fakeSvr stands in for a server of any type,
+// replying fixtures to expected requests in order
+type fakeSvr struct {
+	*httptest.Server
+	t    *testing.T
+	svr  svrs
+	dir  string
+	reqs []fakeReq
+	mu   sync.Mutex
+	got  int
+}
+
+func newFakeSvr(t *testing.T, tp, dir string, reqs []fakeReq) *fakeSvr {
+	f := &fakeSvr{t: t, dir: dir, reqs: reqs}
+	f.Server = httptest.NewServer(f)
+	f.svr = svrs{Type: tp, Name: "fake" + tp, URL: f.URL + "/",
+		User: fakeUser, Pass: passwords{Type: PassBasic, Pass: fakePass}}
+	switch tp {
+	case CategoryJira:
+		// same as IDs in cases, so that no config is saved
+		f.svr.Proj = "X"
+		f.svr.State = []states{
+			{StateTypeNotOpn, "Closed"},
+			{StateTypeTranRej, "Reject"},
+			{StateTypeTranCls, "Resolve"}}
+		f.svr.Flds.TstStep = "customfield_10200"
+	case CategoryGerrit:
+		f.svr.URL += "a/"
+		f.svr.Magic = fakeMagic
+	case CategoryBugzilla:
+		f.svr.Pass = passwords{Type: PassToken, Pass: fakeToken}
+		f.svr.State = []states{
+			{StateTypeNotOpn, "CLOSED"},
+			{StateTypeTranRej, "REJECTED"},
+			{StateTypeResolutionRej, "INVALID"},
+			{StateTypeTranCls, "RESOLVED"},
+			{StateTypeResolutionRes, "FIXED"}}
+	}
+	return f
+}
+
+// authed checks the api key of Bugzilla, or basic auth of others
+func (f *fakeSvr) authed(r *http.Request) bool {
+	if f.svr.Pass.Type == PassToken {
+		return r.URL.Query().Get("Bugzilla_api_key") == fakeToken
+	}
+	user, pass, ok := r.BasicAuth()
+	return ok && user == fakeUser && pass == fakePass
+}
+
+func (f *fakeSvr) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	got := r.Method + " " + r.RequestURI
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.authed(r) {
+		f.t.Error("unauthorized request", got)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if f.got >= len(f.reqs) {
+		f.t.Error("unexpected request", got)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	exp := f.reqs[f.got]
+	f.got++
+	parts := strings.SplitN(exp.req, " ", 3)
+	if got != parts[0]+" "+parts[1] {
+		f.t.Errorf("request %d: %q expected, got %q", f.got, exp.req, got)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if len(parts) > 2 && !bytes.Contains(body, []byte(parts[2])) {
+		f.t.Errorf("request %d: body with %q expected, got %q",
+			f.got, parts[2], body)
+	}
+	if name, val, ok := strings.Cut(exp.reqHdr, ": "); ok &&
+		r.Header.Get(name) != val {
+		f.t.Errorf("request %d: header %q expected, got %q",
+			f.got, exp.reqHdr, r.Header.Get(name))
+	}
+	f.reply(w, exp)
+}
+
+func (f *fakeSvr) reply(w http.ResponseWriter, exp fakeReq) {
+	for k, v := range exp.hdr {
+		w.Header().Set(k, strings.ReplaceAll(v, fakeURL, f.URL))
+	}
+	code := exp.code
+	if len(exp.resp) < 1 {
+		if code == 0 {
+			code = http.StatusNoContent
+		}
+		w.WriteHeader(code)
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(f.dir, exp.resp))
+	if err != nil {
+		f.t.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	switch {
+	case filepath.Dir(exp.resp) == fakeFiles:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition",
+			`attachment; filename="`+filepath.Base(exp.resp)+`"`)
+	case filepath.Ext(exp.resp) == ".json":
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		b = bytes.ReplaceAll(b, []byte(fakeURL), []byte(f.URL))
+		if len(f.svr.Magic) > 0 {
+			b = append([]byte(f.svr.Magic+"\n"), b...)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	}
+	if code != 0 {
+		w.WriteHeader(code)
+	}
+	w.Write(b)
+}
+
+// run runs an action against the fake server, as silent mode does
+func (f *fakeSvr) run(action string, inf IssueInfos) (IssueInfoSlc, []error) {
+	authInfo, hdr, err := cfg2AuthInfo(f.svr, cfg)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	funs := matchFuncFromParam(action, &f.svr, makeCat2Act())
+	if funs == nil {
+		f.t.Fatal("no action matched", action)
+	}
+	looper := DefLooper{svr: &f.svr, authInfo: authInfo, hdr: hdr,
+		maxResults: -1}
+	errs := LoopActions(context.Background(), &f.svr, funs, inf, &looper, nil)
+	return looper.GetIssueInfo(), errs
+}
+
+// fakeRun runs cases of a server type, each against a new fake server,
+// in a temporary working directory for files to upload or download
+func fakeRun(t *testing.T, tp string, cases []fakeCase) {
+	dir, err := filepath.Abs(filepath.Join("testdata", strings.ToLower(tp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	silent, user := uiSilent, cfg.User
+	defer func() {
+		uiSilent, cfg.User = silent, user
+		jiraNow = time.Now
+	}()
+	cfg.User = fakeUser
+	jiraNow = func() time.Time { return fakeNow }
+	for _, c := range cases {
+		t.Run(c.action, func(t *testing.T) {
+			if err := os.Chdir(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(wd)
+			if err := os.WriteFile(fakeUpload, []byte("to upload\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			f := newFakeSvr(t, tp, dir, c.reqs)
+			defer f.Close()
+			// never nil, as from mkIssueinfo
+			inf := make(IssueInfos)
+			maps.Copy(inf, c.inf)
+			res, errs := f.run(c.action, inf)
+			f.mu.Lock()
+			for _, req := range f.reqs[f.got:] {
+				t.Error("request not sent", req.req)
+			}
+			f.mu.Unlock()
+			switch {
+			case c.err == nil:
+				for _, err := range errs {
+					t.Error(err)
+				}
+			case !slices.ContainsFunc(errs, func(err error) bool {
+				return errors.Is(err, c.err)
+			}):
+				t.Error(c.err, "expected, got", errs)
+			}
+			if c.chk != nil {
+				c.chk(t, res)
+			}
+		})
+	}
+}
+
+// fakeChk makes a check of the number of results,
+// and values of the first one
+func fakeChk(n int, first IssueInfos) func(*testing.T, IssueInfoSlc) {
+	return func(t *testing.T, res IssueInfoSlc) {
+		if len(res) != n {
+			t.Fatal(n, "results expected, got", res)
+		}
+		for k, v := range first {
+			if res[0][k] != v {
+				t.Errorf("%s=%q expected, got %q", k, v, res[0][k])
+			}
+		}
+	}
+}
+
+// fakeChkFile makes a check of a file saved in working directory
+func fakeChkFile(name, content string) func(*testing.T, IssueInfoSlc) {
+	return func(t *testing.T, _ IssueInfoSlc) {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("%q expected in %s, got %q", content, name, b)
+		}
+	}
+}
+
+func TestFakeCoverage(t *testing.T) {
+	for cat, acts := range makeCat2Act() {
+		for _, act := range acts {
+			if !slices.ContainsFunc(fakeCases[cat], func(c fakeCase) bool {
+				return c.action == act.n
+			}) {
+				t.Error("NO offline test for", cat, act.n)
+			}
+		}
+	}
+}
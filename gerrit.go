@@ -2,7 +2,9 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -13,13 +15,13 @@ import (
 	"gitee.com/bon-ami/eztools/v6"
 )
 
-func gerritRest4Maps(method, url, magic string,
+func gerritRest4Maps(ctx context.Context, method, url, magic string,
 	authInfo eztools.AuthInfo, fun func(map[string]interface{},
 		IssueInfoSlc) IssueInfoSlc) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	body, err := restSth(method, url, authInfo, nil, magic)
+	body, err := restSth(ctx, method, url, authInfo, nil, magic)
 	if err != nil || body == nil {
 		return nil, err
 	}
@@ -61,10 +63,10 @@ func gerritParseIssuesOrReviews(m map[string]interface{},
 		Log(true, true, eztools.GetCaller(1))
 	}
 	if eztools.Debugging && eztools.Verbose > 1 {
-		eztools.ShowStr("parsing ")
-		eztools.ShowSthln(strs)
-		eztools.ShowStr("from ")
-		eztools.ShowSthln(m)
+		showStr("parsing ")
+		showStrln(strs)
+		showStr("from ")
+		showStrln(m)
 	}
 	if issue == nil {
 		issue = make(IssueInfos)
@@ -75,7 +77,7 @@ func gerritParseIssuesOrReviews(m map[string]interface{},
 		}
 		if m[str1] == nil {
 			if eztools.Debugging && eztools.Verbose > 2 {
-				eztools.ShowStrln("unmatching " + str1)
+				showStrln("unmatching " + str1)
 			}
 			continue
 		}
@@ -86,7 +88,7 @@ func gerritParseIssuesOrReviews(m map[string]interface{},
 		case string:
 			str := m[str1].(string)
 			if eztools.Debugging && eztools.Verbose > 2 {
-				eztools.ShowStrln("matching " +
+				showStrln("matching " +
 					str1 + " <- " + str)
 			}
 			issue[str1] = str
@@ -102,7 +104,7 @@ func gerritParseIssuesOrReviews(m map[string]interface{},
 				issue[str1] = "false"
 			}
 			if eztools.Debugging && eztools.Verbose > 2 {
-				eztools.ShowStrln("matched " +
+				showStrln("matched " +
 					str1 + "=" + issue[str1])
 			}
 		default:
@@ -144,36 +146,36 @@ func gerritParseAuthor(i interface{}, issues IssueInfoSlc) IssueInfoSlc {
 }
 
 // no ID will return, since not in replies
-func gerritGetReviews(url, magic string, authInfo eztools.AuthInfo) (
-	IssueInfoSlc, error) {
+func gerritGetReviews(ctx context.Context, url, magic string,
+	authInfo eztools.AuthInfo) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	return gerritRest4Maps(http.MethodGet, url,
+	return gerritRest4Maps(ctx, http.MethodGet, url,
 		magic, authInfo,
 		func(m map[string]interface{}, issues IssueInfoSlc) IssueInfoSlc {
 			return gerritParseIssuesOrReviews(m, issues, reviewInfoTxt, nil)
 		})
 }
 
-func gerritGetDetails(url, magic string, authInfo eztools.AuthInfo) (
-	IssueInfoSlc, error) {
+func gerritGetDetails(ctx context.Context, url, magic string,
+	authInfo eztools.AuthInfo) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	return gerritRest4Maps(http.MethodGet, url,
+	return gerritRest4Maps(ctx, http.MethodGet, url,
 		magic, authInfo,
 		func(m map[string]interface{}, issues IssueInfoSlc) IssueInfoSlc {
 			return gerritParseIssuesOrReviews(m, issues, issueDetailsTxt, nil)
 		})
 }
 
-func gerritGetHistory(url, magic string, authInfo eztools.AuthInfo) (
-	IssueInfoSlc, error) {
+func gerritGetHistory(ctx context.Context, url, magic string,
+	authInfo eztools.AuthInfo) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	return gerritRest4Maps(http.MethodGet, url,
+	return gerritRest4Maps(ctx, http.MethodGet, url,
 		magic, authInfo,
 		func(m map[string]interface{},
 			issues IssueInfoSlc) IssueInfoSlc {
@@ -200,17 +202,17 @@ func gerritGetHistory(url, magic string, authInfo eztools.AuthInfo) (
 				ret = gerritParseAuthor(m[IssueinfoStrAuthor],
 					ret)
 			}
-			eztools.ShowSthln(ret)
+			showStrln(ret)
 			return ret
 		})
 }
 
-func gerritGetIssues(url, magic string, authInfo eztools.AuthInfo) (
-	IssueInfoSlc, error) {
+func gerritGetIssues(ctx context.Context, url, magic string,
+	authInfo eztools.AuthInfo) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	return gerritRest4Maps(http.MethodGet, url,
+	return gerritRest4Maps(ctx, http.MethodGet, url,
 		magic, authInfo,
 		func(m map[string]interface{}, issues IssueInfoSlc) IssueInfoSlc {
 			return gerritParseIssuesOrReviews(m, issues, issueInfoTxt, nil)
@@ -218,13 +220,13 @@ func gerritGetIssues(url, magic string, authInfo eztools.AuthInfo) (
 }
 
 // param: issueInfo[ISSUEINFO_IND_ID] any ID acceptable
-func gerritQuery1(svr *svrs, authInfo eztools.AuthInfo,
+func gerritQuery1(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, opt string) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
 	const RestAPIStr = "changes/?q="
-	return gerritGetDetails(svr.URL+RestAPIStr+
+	return gerritGetDetails(ctx, svr.URL+RestAPIStr+
 		issueInfo[IssueinfoStrID]+opt,
 		svr.Magic, authInfo)
 }
@@ -338,13 +340,13 @@ func gerritParseRevs(m map[string]interface{},
 		})
 }
 
-func GerritRevs(svr *svrs, authInfo eztools.AuthInfo,
+func GerritRevs(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
 	const RestAPIStr = "changes/?q="
-	ret, err := gerritRest4Maps(http.MethodGet, svr.URL+RestAPIStr+
+	ret, err := gerritRest4Maps(ctx, http.MethodGet, svr.URL+RestAPIStr+
 		issueInfo[IssueinfoStrID]+
 		"&o=ALL_REVISIONS",
 		svr.Magic, authInfo, gerritParseRevs)
@@ -354,7 +356,7 @@ func GerritRevs(svr *svrs, authInfo eztools.AuthInfo,
 	return ret, nil
 }
 
-func GerritMyOpenCmts(svr *svrs, authInfo eztools.AuthInfo,
+func GerritMyOpenCmts(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
@@ -363,21 +365,21 @@ func GerritMyOpenCmts(svr *svrs, authInfo eztools.AuthInfo,
 		issueInfo IssueInfos, res IssueInfoSlc) IssueInfoSlc {
 		return append(res, issueInfo)
 	}
-	return gerritProcRevLoopMyOpen(svr, authInfo,
+	return gerritProcRevLoopMyOpen(ctx, svr, authInfo,
 		issueInfo, f)
 }
 
-func GerritRev(svr *svrs, authInfo eztools.AuthInfo,
+func GerritRev(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	/*if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}*/
-	if useInputOrPrompt4ID(svr, authInfo, issueInfo) {
+	if useInputOrPrompt4ID(ctx, svr, authInfo, issueInfo) {
 		return nil, eztools.ErrInvalidInput
 	}
 	looper := func(issueInfo IssueInfos) (IssueInfoSlc, error) {
 		const RestAPIStr = "changes/?q="
-		ret, err := gerritRest4Maps(http.MethodGet, svr.URL+RestAPIStr+
+		ret, err := gerritRest4Maps(ctx, http.MethodGet, svr.URL+RestAPIStr+
 			issueInfo[IssueinfoStrID]+
 			"&o=CURRENT_REVISION&o=DOWNLOAD_COMMANDS",
 			svr.Magic, authInfo, gerritParseDlds)
@@ -396,19 +398,20 @@ func GerritRev(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		return ret, err
 	}
-	return loopIssues(svr, issueInfo, looper)
+	return loopIssues(ctx, svr, issueInfo, looper)
 }
 
-func GerritDetailOnCurrRev(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func GerritDetailOnCurrRev(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	if useInputOrPrompt4ID(svr, authInfo, issueInfo) {
+	if useInputOrPrompt4ID(ctx, svr, authInfo, issueInfo) {
 		return nil, eztools.ErrInvalidInput
 	}
 	looper := func(issueInfo IssueInfos) (IssueInfoSlc, error) {
-		inf, err := gerritQuery1(svr, authInfo, issueInfo, "&o=CURRENT_REVISION")
+		inf, err := gerritQuery1(ctx, svr, authInfo, issueInfo,
+			"&o=CURRENT_REVISION")
 		if err != nil {
 			return inf, err
 		}
@@ -420,7 +423,7 @@ func GerritDetailOnCurrRev(svr *svrs, authInfo eztools.AuthInfo,
 			return inf, nil
 		}
 		const RestAPIStr = "changes/"
-		if more, err := gerritRest4Maps(http.MethodGet, svr.URL+RestAPIStr+
+		if more, err := gerritRest4Maps(ctx, http.MethodGet, svr.URL+RestAPIStr+
 			inf[0][IssueinfoStrID]+"/revisions/current/mergeable",
 			svr.Magic, authInfo,
 			func(m map[string]interface{}, issues IssueInfoSlc) IssueInfoSlc {
@@ -430,7 +433,7 @@ func GerritDetailOnCurrRev(svr *svrs, authInfo eztools.AuthInfo,
 				inf[0][IssueinfoStrMergeable] = more[0][IssueinfoStrMergeable]
 			}
 		}
-		if more, err := gerritRest4Maps(http.MethodGet, svr.URL+RestAPIStr+
+		if more, err := gerritRest4Maps(ctx, http.MethodGet, svr.URL+RestAPIStr+
 			inf[0][IssueinfoStrID]+"/revisions/current/actions",
 			svr.Magic, authInfo,
 			func(m map[string]interface{}, issues IssueInfoSlc) IssueInfoSlc {
@@ -446,13 +449,13 @@ func GerritDetailOnCurrRev(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		return inf, err
 	}
-	return loopIssues(svr, issueInfo, looper)
+	return loopIssues(ctx, svr, issueInfo, looper)
 }
 
-func GerritHistory(svr *svrs, authInfo eztools.AuthInfo,
+func GerritHistory(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	const RestAPIStr = "changes/"
-	return gerritGetHistory(svr.URL+RestAPIStr+
+	return gerritGetHistory(ctx, svr.URL+RestAPIStr+
 		issueInfo[IssueinfoStrID]+"/detail",
 		svr.Magic, authInfo)
 }
@@ -460,11 +463,11 @@ func GerritHistory(svr *svrs, authInfo eztools.AuthInfo,
 type scores2Marshal map[string]int
 
 // gerritGetScores run detail on the issue to list all fields needing scores
-func gerritGetScores(svr *svrs, authInfo eztools.AuthInfo,
+func gerritGetScores(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (scores []scores2Marshal,
 	rejected map[string]struct{}, err error) {
 	const RestAPIStr = "changes/"
-	body, err := restSth(http.MethodGet, svr.URL+RestAPIStr+
+	body, err := restSth(ctx, http.MethodGet, svr.URL+RestAPIStr+
 		issueInfo[IssueinfoStrID]+"/detail",
 		authInfo, nil, svr.Magic)
 	if err != nil || body == nil {
@@ -497,7 +500,7 @@ func gerritGetScores(svr *svrs, authInfo eztools.AuthInfo,
 		//eztools.ShowStrln(labelName + "=")
 		if label["approved"] != nil {
 			if eztools.Debugging && eztools.Verbose > 2 {
-				eztools.ShowStrln(labelName, " already approved.")
+				showStrln(labelName, " already approved.")
 			}
 			continue
 		}
@@ -540,7 +543,7 @@ func gerritGetScores(svr *svrs, authInfo eztools.AuthInfo,
 	}
 	err = nil
 	if eztools.Debugging && eztools.Verbose > 1 {
-		eztools.ShowSthln(scores)
+		showStrln(scores)
 	}
 	return
 }
@@ -591,17 +594,17 @@ func gerritParseFiles(body map[string]interface{}) IssueInfoSlc {
 		}
 		issues = append(issues, inf)
 		if eztools.Debugging && eztools.Verbose > 2 {
-			eztools.ShowStrln(file1 + " checked")
+			showStrln(file1 + " checked")
 		}
 	}
 	return issues
 }
 
-func GerritListFilesByRev(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func GerritListFilesByRev(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrRevCur]) < 1 {
 		const RestAPIStr = "changes/?q="
-		return gerritRest4Maps(http.MethodGet,
+		return gerritRest4Maps(ctx, http.MethodGet,
 			svr.URL+RestAPIStr+
 				issueInfo[IssueinfoStrID]+
 				"&o=CURRENT_REVISION&o=CURRENT_FILES",
@@ -610,7 +613,7 @@ func GerritListFilesByRev(svr *svrs, authInfo eztools.AuthInfo,
 			})
 	}
 	const RestAPIStr = "changes/"
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		svr.URL+RestAPIStr+
 			issueInfo[IssueinfoStrID]+"/revisions/"+
 			issueInfo[IssueinfoStrRevCur]+"/files/",
@@ -621,10 +624,10 @@ func GerritListFilesByRev(svr *svrs, authInfo eztools.AuthInfo,
 	return gerritParseFiles(bodyMap), nil
 }
 
-func GerritGetFile(svr *svrs, authInfo eztools.AuthInfo,
+func GerritGetFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrRevCur]) < 1 {
-		inf, err := GerritRev(svr, authInfo, issueInfo)
+		inf, err := GerritRev(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			return nil, err
 		}
@@ -635,7 +638,7 @@ func GerritGetFile(svr *svrs, authInfo eztools.AuthInfo,
 	}
 	const RestAPIStr = "changes/"
 	if len(issueInfo[IssueinfoStrFile]) < 1 {
-		bodyMap, err := restMap(http.MethodGet,
+		bodyMap, err := restMap(ctx, http.MethodGet,
 			svr.URL+RestAPIStr+
 				issueInfo[IssueinfoStrID]+"/revisions/"+
 				issueInfo[IssueinfoStrRevCur]+"/files/",
@@ -651,13 +654,13 @@ func GerritGetFile(svr *svrs, authInfo eztools.AuthInfo,
 		for _, f := range inf {
 			files = append(files, f[IssueinfoStrFile])
 		}
-		i, f := eztools.ChooseStrings(files)
+		i, f := chooseStrings(files)
 		if i == eztools.InvalidID {
 			return nil, eztools.ErrInvalidInput
 		}
 		issueInfo[IssueinfoStrFile] = f
 	}
-	_, err := restAttachment(http.MethodGet,
+	_, err := restAttachment(ctx, http.MethodGet,
 		svr.URL+RestAPIStr+
 			issueInfo[IssueinfoStrID]+"/revisions/"+
 			issueInfo[IssueinfoStrRevCur]+"/files/"+
@@ -695,15 +698,16 @@ func gerritParseRecursively(m map[string]interface{}, str []string,
 }
 
 // no ID will return, since not in replies
-func GerritReviews(svr *svrs, authInfo eztools.AuthInfo,
+func GerritReviews(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	const RestAPIStr = "changes/"
-	return gerritGetReviews(svr.URL+RestAPIStr+
+	return gerritGetReviews(ctx, svr.URL+RestAPIStr+
 		issueInfo[IssueinfoStrID]+"/reviewers/",
 		svr.Magic, authInfo)
 }
 
-func gerritGetIssuesWtOwner(svr *svrs, authInfo eztools.AuthInfo,
+func gerritGetIssuesWtOwner(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo,
 	status string, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	useInputOrPromptStr(svr, issueInfo,
 		IssueinfoStrID, IssueinfoStrAssignee)
@@ -730,58 +734,58 @@ func gerritGetIssuesWtOwner(svr *svrs, authInfo eztools.AuthInfo,
 		return nil, eztools.ErrInvalidInput
 	}
 	const RestAPIStr = "changes/?q="
-	return gerritGetIssues(svr.URL+RestAPIStr+urlAffix, svr.Magic, authInfo)
+	return gerritGetIssues(ctx, svr.URL+RestAPIStr+urlAffix, svr.Magic, authInfo)
 }
 
-func GerritSbMerged(svr *svrs, authInfo eztools.AuthInfo,
+func GerritSbMerged(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return gerritGetIssuesWtOwner(svr, authInfo, "merged", issueInfo)
+	return gerritGetIssuesWtOwner(ctx, svr, authInfo, "merged", issueInfo)
 }
 
-func GerritAllOpen(svr *svrs, authInfo eztools.AuthInfo,
+func GerritAllOpen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if !uiSilent {
-		if cfm := eztools.PromptStr("This may take quite a while..." +
+		if cfm := promptStr("This may take quite a while..." +
 			"Confirm to continue. ([Enter]=Y/y)"); cfm == "N" || cfm == "n" {
 			return nil, eztools.ErrInvalidInput
 		}
 	}
 	const RestAPIStr = "changes/"
-	return gerritGetIssues(svr.URL+RestAPIStr, svr.Magic, authInfo)
+	return gerritGetIssues(ctx, svr.URL+RestAPIStr, svr.Magic, authInfo)
 }
 
-func GerritSbOpen(svr *svrs, authInfo eztools.AuthInfo,
+func GerritSbOpen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return gerritGetIssuesWtOwner(svr, authInfo, "open", issueInfo)
+	return gerritGetIssuesWtOwner(ctx, svr, authInfo, "open", issueInfo)
 }
 
-func GerritMyOpen(svr *svrs, authInfo eztools.AuthInfo,
+func GerritMyOpen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	issueInfo[IssueinfoStrID] = authInfo.User
 	defer func() {
 		issueInfo[IssueinfoStrID] = ""
 	}()
-	return gerritGetIssuesWtOwner(svr, authInfo, "open", issueInfo)
+	return gerritGetIssuesWtOwner(ctx, svr, authInfo, "open", issueInfo)
 }
 
-func GerritRebase(svr *svrs, authInfo eztools.AuthInfo,
+func GerritRebase(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return gerritActOn1WtAnyID(svr, authInfo, issueInfo, nil, "/rebase")
+	return gerritActOn1WtAnyID(ctx, svr, authInfo, issueInfo, nil, "/rebase")
 }
 
-func GerritRevert(svr *svrs, authInfo eztools.AuthInfo,
+func GerritRevert(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return gerritActOn1WtAnyID(svr, authInfo, issueInfo, nil, "/revert")
+	return gerritActOn1WtAnyID(ctx, svr, authInfo, issueInfo, nil, "/revert")
 }
 
-func GerritMerge(svr *svrs, authInfo eztools.AuthInfo,
+func GerritMerge(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	if useInputOrPrompt4ID(svr, authInfo, issueInfo) {
+	if useInputOrPrompt4ID(ctx, svr, authInfo, issueInfo) {
 		return nil, eztools.ErrInvalidInput
 	}
 	looper := func(issueInfo IssueInfos) (IssueInfoSlc, error) {
 		// check mergable only, without submittable
-		inf, err := GerritDetailOnCurrRev(svr, authInfo, issueInfo)
+		inf, err := GerritDetailOnCurrRev(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			return nil, err
 		}
@@ -792,39 +796,44 @@ func GerritMerge(svr *svrs, authInfo eztools.AuthInfo,
 		if inf[0][IssueinfoStrSubmittable] != "false" &&
 			inf[0][IssueinfoStrMergeable] != "false" {
 			// either empty(=not supported or already merged) or true will do
-			return gerritActOn1(svr, authInfo, issueInfo, nil, "/submit")
+			return gerritActOn1(ctx, svr, authInfo, issueInfo, nil, "/submit")
 		}
 		return nil, eztools.ErrNoValidResults
 	}
-	return loopIssues(svr, issueInfo, looper)
+	return loopIssues(ctx, svr, issueInfo, looper)
 }
 
-func GerritAbandon(svr *svrs, authInfo eztools.AuthInfo,
+func GerritAbandon(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return gerritActOn1WtAnyID(svr, authInfo, issueInfo, nil, "/abandon")
+	return gerritActOn1WtAnyID(ctx, svr, authInfo, issueInfo, nil, "/abandon")
 }
 
-func GerritAbandonMyOpen(svr *svrs, authInfo eztools.AuthInfo,
+func GerritRestore(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return gerritActOnMyOpen(svr, authInfo, issueInfo, "/abandon")
+	return gerritActOn1WtAnyID(ctx, svr, authInfo, issueInfo, nil, "/restore")
+}
+
+func GerritAbandonMyOpen(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	return gerritActOnMyOpen(ctx, svr, authInfo, issueInfo, "/abandon")
 }
 
-func GerritPick(svr *svrs, authInfo eztools.AuthInfo,
+func GerritPick(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrRevCur]) < 1 {
-		inf, err := GerritRev(svr, authInfo, issueInfo)
+		inf, err := GerritRev(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			return nil, err
 		}
 		// should be only one or same among all
 		issueInfo[IssueinfoStrRevCur] = inf[0][IssueinfoStrRevCur]
 	}
-	return gerritPick1(svr, authInfo, issueInfo, nil)
+	return gerritPick1(ctx, svr, authInfo, issueInfo, nil)
 }
 
-func gerritPick1(svr *svrs, authInfo eztools.AuthInfo,
+func gerritPick1(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, res IssueInfoSlc) (IssueInfoSlc, error) {
-	if useInputOrPrompt4ID(svr, authInfo, issueInfo) {
+	if useInputOrPrompt4ID(ctx, svr, authInfo, issueInfo) {
 		return nil, eztools.ErrInvalidInput
 	}
 	looper := func(issueInfo IssueInfos) (IssueInfoSlc, error) {
@@ -852,7 +861,7 @@ func gerritPick1(svr *svrs, authInfo eztools.AuthInfo,
 		jsonValue, _ := json.Marshal(map[string]string{
 			//"message": "testing", // if this is a must, I have to read original submit message
 			"destination": issueInfo[IssueinfoStrBranch]})
-		bodyMap, err := restMap(http.MethodPost, svr.URL+
+		bodyMap, err := restMap(ctx, http.MethodPost, svr.URL+
 			RestAPIStr+issueInfo[IssueinfoStrID]+
 			"/revisions/"+issueInfo[IssueinfoStrRevCur]+
 			"/cherrypick",
@@ -862,22 +871,22 @@ func gerritPick1(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		return gerritParseIssuesOrReviews(bodyMap, res, issueInfoTxt, nil), err
 	}
-	return loopIssues(svr, issueInfo, looper)
+	return loopIssues(ctx, svr, issueInfo, looper)
 }
 
 // gerritProcRevLoopMyOpen run a func on all my open issues
 // with current revision/commit info
-func gerritProcRevLoopMyOpen(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos,
+func gerritProcRevLoopMyOpen(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos,
 	f func(*svrs, eztools.AuthInfo, IssueInfos,
 		IssueInfoSlc) IssueInfoSlc) (res IssueInfoSlc,
 	err error) {
-	issues, err := GerritMyOpen(svr, authInfo, issueInfo)
+	issues, err := GerritMyOpen(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return
 	}
 	for _, issueInfo := range issues {
-		inf, err := GerritRev(svr, authInfo, issueInfo)
+		inf, err := GerritRev(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			Log(true, false, err)
 			continue
@@ -889,28 +898,29 @@ func gerritProcRevLoopMyOpen(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // GerritPickMyOpen cherry picks all my open submits
-func GerritPickMyOpen(svr *svrs, authInfo eztools.AuthInfo,
+func GerritPickMyOpen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	branch := issueInfo[IssueinfoStrBranch]
 	f := func(svr *svrs, authInfo eztools.AuthInfo,
 		issueInfo IssueInfos,
 		res IssueInfoSlc) IssueInfoSlc {
 		issueInfo[IssueinfoStrBranch] = branch
-		resO, _ := gerritPick1(svr, authInfo, issueInfo, res)
+		resO, _ := gerritPick1(ctx, svr, authInfo, issueInfo, res)
 		return resO
 	}
-	return gerritProcRevLoopMyOpen(svr, authInfo,
+	return gerritProcRevLoopMyOpen(ctx, svr, authInfo,
 		issueInfo, f)
 }
 
-func gerritActOnMyOpen(svr *svrs, authInfo eztools.AuthInfo,
+func gerritActOnMyOpen(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, action string) (res IssueInfoSlc, err error) {
-	issues, err := GerritMyOpen(svr, authInfo, issueInfo)
+	issues, err := GerritMyOpen(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return
 	}
 	for _, issueInfo := range issues {
-		res, err = gerritActOn1(svr, authInfo, issueInfo, res, action)
+		res, err = gerritActOn1(ctx, svr, authInfo, issueInfo, res, action)
 		if err != nil {
 			return
 		}
@@ -919,42 +929,43 @@ func gerritActOnMyOpen(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // gerritActOn1WtAnyID POST changes/ID from input/action
-func gerritActOn1WtAnyID(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos, _ IssueInfoSlc,
+func gerritActOn1WtAnyID(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos, _ IssueInfoSlc,
 	action string) (IssueInfoSlc, error) {
-	return gerritActOn1(svr, authInfo, issueInfo, nil, action)
+	return gerritActOn1(ctx, svr, authInfo, issueInfo, nil, action)
 }
 
 // gerritActOn1 POST changes/ID/action
 // param: issueInfo[ISSUEINFO_IND_ID] unique ID
 // TODO: should returned slice mean anything when input slice is nil?
 // Currently all discarded
-func gerritActOn1(svr *svrs, authInfo eztools.AuthInfo,
+func gerritActOn1(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, issues IssueInfoSlc,
 	action string) (IssueInfoSlc, error) {
 	if eztools.Debugging && !uiSilent {
-		if !eztools.ChkCfmNPrompt(action+" "+
+		if !chkCfmNPrompt(action+" "+
 			issueInfo[IssueinfoStrID], "n") {
 			return nil, nil
 		}
 	}
 	const RestAPIStr = "changes/"
-	bodyMap, err := restMap(http.MethodPost, svr.URL+
+	bodyMap, err := restMap(ctx, http.MethodPost, svr.URL+
 		RestAPIStr+issueInfo[IssueinfoStrID]+action,
 		authInfo, nil, svr.Magic)
 	return gerritParseIssuesOrReviews(bodyMap, issues, issueInfoTxt, nil),
 		err
 }
 
-func gerritScoreNGetRej(svr *svrs, authInfo eztools.AuthInfo,
+func gerritScoreNGetRej(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (rejectedAft map[string]struct{},
 	failed map[string]struct{}, err error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	scores, rejectedB4, err := gerritGetScores(svr, authInfo, issueInfo)
+	scores, rejectedB4, err := gerritGetScores(ctx, svr, authInfo, issueInfo)
 	if err != nil {
-		if err == eztools.ErrInExistence {
+		if errors.Is(err, eztools.ErrInExistence) {
 			return nil, nil, nil
 		}
 		return
@@ -973,14 +984,16 @@ func gerritScoreNGetRej(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		//eztools.ShowStrln(string(jsonValue))
 		/*if eztools.Debugging && !uiSilent {
-			if !eztools.ChkCfmNPrompt("continue to +2/1 to "+
+			if !chkCfmNPrompt("continue to +2/1 to "+
 				infWtRev[IssueinfoStrID], "n") {
 				break
 			}
 		}*/
-		body, err1 := restSth("POST", svr.URL+RestAPIStr+
-			issueInfo[IssueinfoStrID]+"/revisions/"+
-			issueInfo[IssueinfoStrRevCur]+"/review",
+		// same scores can be set again
+		body, err1 := restSth(retrySafe(ctx), http.MethodPost,
+			svr.URL+RestAPIStr+
+				issueInfo[IssueinfoStrID]+"/revisions/"+
+				issueInfo[IssueinfoStrRevCur]+"/review",
 			authInfo, bytes.NewBuffer(jsonValue), svr.Magic)
 		if err1 == nil {
 			// response only contain scores for a success, so it is not parsed
@@ -1021,16 +1034,16 @@ func gerritScoreNGetRej(svr *svrs, authInfo eztools.AuthInfo,
 //	inf = nil if success
 //	inf = info of current revision if more than one / no revisions found?
 //	inf = rejected fields that needs to approve but failed
-func GerritScore(svr *svrs, authInfo eztools.AuthInfo,
+func GerritScore(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (inf IssueInfoSlc, err error) {
 	/*if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}*/
-	inf, err = GerritRev(svr, authInfo, issueInfo)
+	inf, err = GerritRev(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return
 	}
-	_, failed, err := gerritScoreNGetRej(svr, authInfo, inf[0])
+	_, failed, err := gerritScoreNGetRej(ctx, svr, authInfo, inf[0])
 	if failed != nil {
 		inf = nil
 		for i := range failed {
@@ -1040,21 +1053,21 @@ func GerritScore(svr *svrs, authInfo eztools.AuthInfo,
 	return
 }
 
-func GerritRelated(svr *svrs, authInfo eztools.AuthInfo,
+func GerritRelated(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (inf IssueInfoSlc, err error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	if useInputOrPrompt4ID(svr, authInfo, issueInfo) {
+	if useInputOrPrompt4ID(ctx, svr, authInfo, issueInfo) {
 		return nil, eztools.ErrInvalidInput
 	}
 	looper := func(issueInfo IssueInfos) (inf IssueInfoSlc, err error) {
-		inf, err = GerritRev(svr, authInfo, issueInfo)
+		inf, err = GerritRev(ctx, svr, authInfo, issueInfo)
 		if err != nil || len(inf) != 1 {
 			return
 		}
 		const RestAPIStr = "changes/"
-		bodyMap, err := restMap(http.MethodGet, svr.URL+
+		bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+
 			RestAPIStr+inf[0][IssueinfoStrID]+
 			"/revisions/"+inf[0][IssueinfoStrRevCur]+"/related",
 			authInfo, nil, svr.Magic)
@@ -1105,21 +1118,21 @@ func GerritRelated(svr *svrs, authInfo eztools.AuthInfo,
 			})
 		return
 	}
-	return loopIssues(svr, issueInfo, looper)
+	return loopIssues(ctx, svr, issueInfo, looper)
 }
 
-func gerritFuncLoopSbOpen(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos, fun func(*svrs, eztools.AuthInfo,
-		IssueInfos) (IssueInfoSlc, error)) (res IssueInfoSlc, err error) {
+func gerritFuncLoopSbOpen(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo,
+	issueInfo IssueInfos, fun actionFunc) (res IssueInfoSlc, err error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	issues, err := GerritSbOpen(svr, authInfo, issueInfo)
+	issues, err := GerritSbOpen(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return
 	}
 	for _, issueInfo := range issues {
-		res, err = fun(svr, authInfo, issueInfo)
+		res, err = fun(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			return
 		}
@@ -1127,26 +1140,26 @@ func gerritFuncLoopSbOpen(svr *svrs, authInfo eztools.AuthInfo,
 	return
 }
 
-func GerritWaitNMergeSb(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func GerritWaitNMergeSb(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if uiSilent || !eztools.Debugging {
 		Log(true, false, "bulk wait and merge supported in interaction+debugging mode")
 		return nil, eztools.ErrAccess
 	}
-	return gerritFuncLoopSbOpen(svr, authInfo, issueInfo, GerritWaitNMerge)
+	return gerritFuncLoopSbOpen(ctx, svr, authInfo, issueInfo, GerritWaitNMerge)
 }
 
-func GerritWaitNMerge(svr *svrs, authInfo eztools.AuthInfo,
+func GerritWaitNMerge(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
-	if useInputOrPrompt4ID(svr, authInfo, issueInfo) {
+	if useInputOrPrompt4ID(ctx, svr, authInfo, issueInfo) {
 		return nil, eztools.ErrInvalidInput
 	}
 	looper := func(issueInfo IssueInfos) (IssueInfoSlc, error) {
 		var ret IssueInfoSlc
-		cur, err := GerritDetailOnCurrRev(svr, authInfo, issueInfo)
+		cur, err := GerritDetailOnCurrRev(ctx, svr, authInfo, issueInfo)
 		if err != nil || len(cur) < 1 {
 			Log(true, false, "no details available for", issueInfo[IssueinfoStrID], err)
 			return nil, eztools.ErrAccess
@@ -1157,7 +1170,7 @@ func GerritWaitNMerge(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		// match commit number=cur[IssueinfoStr_Nmb] or
 		// commit id=gerritRev(svr, authInfo, inf[i])[IssueinfoStrID]
-		inf, err := GerritRelated(svr, authInfo, issueInfo)
+		inf, err := GerritRelated(ctx, svr, authInfo, issueInfo)
 		if err != nil || len(inf) < 1 {
 			Log(true, false, "no related commits for", issueInfo[IssueinfoStrID], err)
 			return nil, eztools.ErrAccess
@@ -1167,7 +1180,7 @@ func GerritWaitNMerge(svr *svrs, authInfo eztools.AuthInfo,
 				continue
 			}
 			if len(inf[i][IssueinfoStrParents]) > 0 {
-				if parent1, err := GerritWaitNMerge(svr, authInfo,
+				if parent1, err := GerritWaitNMerge(ctx, svr, authInfo,
 					IssueInfos{IssueinfoStrID: inf[i][IssueinfoStrParents]}); err != nil {
 					Log(true, false, "parent",
 						inf[i][IssueinfoStrParents],
@@ -1177,7 +1190,7 @@ func GerritWaitNMerge(svr *svrs, authInfo eztools.AuthInfo,
 					ret = append(ret, parent1...)
 				}
 			}
-			if ret1, err1 := gerritWaitNMerge1(svr, authInfo,
+			if ret1, err1 := gerritWaitNMerge1(ctx, svr, authInfo,
 				issueInfo); err1 != nil {
 				err = err1
 			} else {
@@ -1187,11 +1200,11 @@ func GerritWaitNMerge(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		return ret, err
 	}
-	return loopIssues(svr, issueInfo, looper)
+	return loopIssues(ctx, svr, issueInfo, looper)
 }
 
-func gerritWaitNMerge1(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
+func gerritWaitNMerge1(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
 	}
@@ -1202,10 +1215,10 @@ func gerritWaitNMerge1(svr *svrs, authInfo eztools.AuthInfo,
 		rejected, rejects map[string]struct{}
 		scores            []scores2Marshal
 	)
-	eztools.ShowStr("waiting for ", issueInfo,
+	showStr("waiting for ", issueInfo,
 		" to be submittable/mergeable.")
 	for err == nil {
-		inf, err = GerritDetailOnCurrRev(svr, authInfo, issueInfo)
+		inf, err = GerritDetailOnCurrRev(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			break
 		}
@@ -1239,11 +1252,11 @@ func gerritWaitNMerge1(svr *svrs, authInfo eztools.AuthInfo,
 		          values:map[ 0:No score +1:Looks good to me, but someone else must approve +2:Looks good to me, approved -1:I would prefer this is not merged as is -2:This shall not be merged]]*/
 
 		if !scored {
-			rev, err = GerritRev(svr, authInfo, issueInfo)
+			rev, err = GerritRev(ctx, svr, authInfo, issueInfo)
 			if err != nil {
 				return nil, err
 			}
-			rejected, _, err = gerritScoreNGetRej(svr, authInfo, rev[0])
+			rejected, _, err = gerritScoreNGetRej(ctx, svr, authInfo, rev[0])
 			scored = true
 			if err != nil {
 				Log(false, false,
@@ -1253,7 +1266,7 @@ func gerritWaitNMerge1(svr *svrs, authInfo eztools.AuthInfo,
 				continue
 			}
 		} else {
-			scores, rejects, err = gerritGetScores(svr, authInfo, rev[0])
+			scores, rejects, err = gerritGetScores(ctx, svr, authInfo, rev[0])
 			/* 			if rejects != nil {
 				for i := range rejects {
 					if _, ok := rejected[i]; !ok {
@@ -1283,22 +1296,27 @@ func gerritWaitNMerge1(svr *svrs, authInfo eztools.AuthInfo,
 				}
 			}
 		}
-		time.Sleep(intGerritMerge * time.Second)
-		eztools.ShowStr(".")
+		select {
+		case <-time.After(intGerritMerge * time.Second):
+		case <-ctx.Done():
+			showStrln("")
+			return nil, context.Cause(ctx)
+		}
+		showStr(".")
 	}
-	eztools.ShowStrln("")
-	if err != nil && err != eztools.ErrInExistence {
+	showStrln("")
+	if err != nil && !errors.Is(err, eztools.ErrInExistence) {
 		// when no scores got, ErrInExistence. Then will try to submit it.
-		if err == eztools.ErrOutOfBound {
+		if errors.Is(err, eztools.ErrOutOfBound) {
 			Log(true, false, "Conflict to merge?")
 		}
 		return nil, err
 	}
 	// _, err = gerritMerge(svr, authInfo, issueInfo) not used because of redundant steps of checking
-	return gerritActOn1(svr, authInfo, issueInfo, nil, "/submit")
+	return gerritActOn1(ctx, svr, authInfo, issueInfo, nil, "/submit")
 }
 
-func GerritListPrj(svr *svrs, authInfo eztools.AuthInfo,
+func GerritListPrj(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		Log(true, true, eztools.GetCaller(1))
@@ -1308,7 +1326,7 @@ func GerritListPrj(svr *svrs, authInfo eztools.AuthInfo,
 		return nil, eztools.ErrInvalidInput
 	}
 	const RestAPIStr = "projects/"
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		svr.URL+RestAPIStr+
 			url.QueryEscape(issueInfo[IssueinfoStrProj])+"/config",
 		authInfo, nil, svr.Magic)
@@ -1340,3 +1358,102 @@ func GerritListPrj(svr *svrs, authInfo eztools.AuthInfo,
 			return issueInfo.ToSlc()
 		}), nil
 }
+
+// gerritBackend is the backend of Gerrit servers
+type gerritBackend struct{}
+
+func init() {
+	regBackend(gerritBackend{})
+}
+
+// Type is CategoryGerrit
+func (gerritBackend) Type() string {
+	return CategoryGerrit
+}
+
+// PassType is PassDigest, HTTP password from Settings
+func (gerritBackend) PassType() string {
+	return PassDigest
+}
+
+// Magic is the prefix of responses against XSSI
+func (gerritBackend) Magic() string {
+	return ")]}'"
+}
+
+// Actions of submits
+func (gerritBackend) Actions() []action2Func {
+	return []action2Func{
+		{"list merged submits of someone", GerritSbMerged},
+		{"list my open submits", GerritMyOpen},
+		{"list sbs open submits", GerritSbOpen},
+		{"list all open submits", GerritAllOpen},
+		{"list my open commits", GerritMyOpenCmts},
+		{"show details of a submit", GerritDetailOnCurrRev},
+		{"show revisions of a submit", GerritRevs},
+		{"show history of a submit", GerritHistory},
+		{"show reviewers and scores of a submit", GerritReviews},
+		{"show current revision or commit of a submit", GerritRev},
+		{"rebase a submit", GerritRebase},
+		{"merge a submit", GerritMerge},
+		{"show related submits of one", GerritRelated},
+		{"add scores to a submit", GerritScore},
+		{"add scores, wait for it to be mergable and merge a submit", GerritWaitNMerge},
+		{"wait for mergable and merge sbs submits", GerritWaitNMergeSb},
+		{"abandon all my open submits", GerritAbandonMyOpen},
+		{"abandon a submit", GerritAbandon},
+		{"restore a submit", GerritRestore},
+		{"cherry pick all my open submits", GerritPickMyOpen},
+		{"cherry pick a submit", GerritPick},
+		{"revert a submit", GerritRevert},
+		{"list files of a submit by revision", GerritListFilesByRev},
+		{"list config of a project", GerritListPrj},
+		{"download a file of a submit", GerritGetFile}}
+}
+
+// ListMine lists my open submits with branches and subjects
+func (gerritBackend) ListMine() (actionFunc, string, string) {
+	return GerritMyOpen, IssueinfoStrBranch, IssueinfoStrSubject
+}
+
+// Input asks for input of actions of submits
+func (gerritBackend) Input(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, action string, inf IssueInfos) bool {
+	switch action {
+	case "rebase a submit",
+		"revert a submit",
+		"abandon a submit",
+		"restore a submit",
+		"show reviewers and scores of a submit",
+		"add scores to a submit",
+		"show revisions of a submit",
+		"show history of a submit":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "list files of a submit by revision":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf, IssueinfoStrRevCur,
+			"revision(empty for current)")
+	case "download a file of a submit":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf, IssueinfoStrRevCur,
+			"revision(empty for current)")
+		useInputOrPrompt(svr, inf, IssueinfoStrFile)
+	case "cherry pick a submit":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf, IssueinfoStrRevCur,
+			"revision(empty for current)")
+		useInputOrPrompt(svr, inf, IssueinfoStrBranch)
+		if len(inf[IssueinfoStrBranch]) < 1 {
+			return true
+		}
+	}
+	return false
+}
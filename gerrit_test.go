@@ -1,8 +1,10 @@
 package main
 
 import (
+	"slices"
 	"testing"
 
+	"gitee.com/bon-ami/eztools/v6"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -95,6 +97,10 @@ func TestGerritAbandonSubmit(t *testing.T) {
 	GerritTests(t, "abandon a submit", false)
 }
 
+func TestGerritRestoreSubmit(t *testing.T) {
+	GerritTests(t, "restore a submit", false)
+}
+
 func TestGerritCherryPickMyOpenSubmits(t *testing.T) {
 	GerritTests(t, "cherry pick all my open submits", false)
 }
@@ -118,3 +124,177 @@ func TestGerritDownloadFileOfSubmit(t *testing.T) {
 func TestGerritMultiple(t *testing.T) {
 	GerritTests(t, "list my open submits;show current revision or commit of a submit", false)
 }
+
+const (
+	gerritFakeID     = "proj~master~I0123456789abcdef0123456789abcdef01234567"
+	gerritFakeChg    = "/a/changes/" + gerritFakeID
+	gerritFakeMyOpen = "GET /a/changes/?q=status:open+owner:tester"
+	gerritFakeCur    = "GET /a/changes/?q=1001&o=CURRENT_REVISION&o=DOWNLOAD_COMMANDS"
+	gerritFakeCherry = "git fetch ssh://tester@gerrit.example.com:29418/proj " +
+		"refs/changes/01/1001/2 && git cherry-pick FETCH_HEAD"
+)
+
+// gerritFakeDetail are requests to show details of a submit
+var gerritFakeDetail = []fakeReq{
+	{req: "GET /a/changes/?q=1001&o=CURRENT_REVISION", resp: "current.json"},
+	{req: "GET " + gerritFakeChg + "/revisions/current/mergeable",
+		resp: "mergeable.json"},
+	{req: "GET " + gerritFakeChg + "/revisions/current/actions",
+		resp: "actions.json"}}
+
+var gerritFakeCases = []fakeCase{
+	{action: "list merged submits of someone",
+		inf: IssueInfos{IssueinfoStrID: "alice"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/?q=status:merged+owner:alice",
+				resp: "changes.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: gerritFakeID,
+			IssueinfoStrSubject: "Fix crash on start", IssueinfoStrState: "NEW"})},
+	{action: "list my open submits",
+		reqs: []fakeReq{
+			{req: gerritFakeMyOpen, resp: "changes.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrProj: "proj",
+			IssueinfoStrBranch: "master"})},
+	{action: "list sbs open submits",
+		inf: IssueInfos{IssueinfoStrID: "alice", IssueinfoStrBranch: "master"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/?q=status:open+branch:master+owner:alice",
+				resp: "changes.json"}},
+		chk: fakeChk(1, nil)},
+	{action: "list all open submits",
+		reqs: []fakeReq{
+			{req: "GET /a/changes/", resp: "changes.json"}},
+		chk: fakeChk(1, nil)},
+	{action: "list my open commits",
+		reqs: []fakeReq{
+			{req: gerritFakeMyOpen, resp: "changes.json"},
+			{req: "GET /a/changes/?q=" + gerritFakeID +
+				"&o=CURRENT_REVISION&o=DOWNLOAD_COMMANDS", resp: "current.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrRevCur: "abc123",
+			IssueinfoStrNmb: "2", IssueinfoStrCherry: gerritFakeCherry})},
+	{action: "show details of a submit",
+		inf:  IssueInfos{IssueinfoStrID: "1001"},
+		reqs: gerritFakeDetail,
+		chk: fakeChk(1, IssueInfos{IssueinfoStr_Nmb: "1001",
+			IssueinfoStrMergeable: "true", IssueinfoStrSubmittable: "true"})},
+	{action: "show revisions of a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/?q=1001&o=ALL_REVISIONS", resp: "revisions.json"}},
+		chk: fakeChk(2, nil)},
+	{action: "show history of a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/1001/detail", resp: "detail.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "m1",
+			IssueinfoStrMsg: "Uploaded patch set 1.", IssueinfoStrAuthor: "Test Er"})},
+	{action: "show reviewers and scores of a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/1001/reviewers/", resp: "reviewers.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrName: "Alice",
+			IssueinfoStrCodereview: "+2", IssueinfoStrVerified: "+1"})},
+	{action: "show current revision or commit of a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: gerritFakeCur, resp: "current.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: gerritFakeID,
+			IssueinfoStrRevCur: "abc123", IssueinfoStrCherry: gerritFakeCherry})},
+	{action: "rebase a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "POST /a/changes/1001/rebase", resp: "change.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: gerritFakeID})},
+	{action: "merge a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: append(slices.Clone(gerritFakeDetail),
+			fakeReq{req: "POST /a/changes/1001/submit", resp: "merged.json"}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrState: IssueinfoStrMerged})},
+	{action: "show related submits of one",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: gerritFakeCur, resp: "current.json"},
+			{req: "GET " + gerritFakeChg + "/revisions/abc123/related",
+				resp: "related.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStr_Chg_Nmb: "1001",
+			IssueinfoStrCommit: "abc123", IssueinfoStrAuthor: "Test Er"})},
+	{action: "add scores to a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: gerritFakeCur, resp: "current.json"},
+			{req: "GET " + gerritFakeChg + "/detail", resp: "detail.json"},
+			{req: "POST " + gerritFakeChg + `/revisions/abc123/review {"labels":{"Code-Review":2}}`,
+				resp: "review.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrRevCur: "abc123"})},
+	{action: "add scores, wait for it to be mergable and merge a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: slices.Concat(gerritFakeDetail, []fakeReq{
+			{req: gerritFakeCur, resp: "current.json"},
+			{req: "GET " + gerritFakeChg + "/revisions/abc123/related",
+				resp: "related.json"}},
+			gerritFakeDetail, []fakeReq{
+				{req: "POST /a/changes/1001/submit", resp: "merged.json"}}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrState: IssueinfoStrMerged})},
+	// supported in interaction mode only
+	{action: "wait for mergable and merge sbs submits",
+		inf: IssueInfos{IssueinfoStrID: "alice"},
+		err: eztools.ErrAccess},
+	{action: "abandon all my open submits",
+		reqs: []fakeReq{
+			{req: gerritFakeMyOpen, resp: "changes.json"},
+			{req: "POST " + gerritFakeChg + "/abandon", resp: "change.json"}},
+		chk: fakeChk(1, nil)},
+	{action: "abandon a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "POST /a/changes/1001/abandon", resp: "change.json"}}},
+	{action: "restore a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "POST /a/changes/1001/restore", resp: "change.json"}}},
+	{action: "cherry pick all my open submits",
+		inf: IssueInfos{IssueinfoStrBranch: "release"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/?q=status:open+branch:release+owner:tester",
+				resp: "changes.json"},
+			{req: "GET /a/changes/?q=" + gerritFakeID +
+				"&o=CURRENT_REVISION&o=DOWNLOAD_COMMANDS", resp: "current.json"},
+			{req: "POST " + gerritFakeChg + `/revisions/abc123/cherrypick {"destination":"release"}`,
+				resp: "picked.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrBranch: "release"})},
+	{action: "cherry pick a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001", IssueinfoStrBranch: "release"},
+		reqs: []fakeReq{
+			{req: gerritFakeCur, resp: "current.json"},
+			{req: `POST /a/changes/1001/revisions/abc123/cherrypick {"destination":"release"}`,
+				resp: "picked.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrBranch: "release"})},
+	{action: "revert a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "POST /a/changes/1001/revert", resp: "change.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrState: "NEW"})},
+	{action: "list files of a submit by revision",
+		inf: IssueInfos{IssueinfoStrID: "1001"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/?q=1001&o=CURRENT_REVISION&o=CURRENT_FILES",
+				resp: "files_current.json"}},
+		chk: fakeChk(2, nil)},
+	{action: "list config of a project",
+		inf: IssueInfos{IssueinfoStrProj: "proj"},
+		reqs: []fakeReq{
+			{req: "GET /a/projects/proj/config", resp: "config.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrMatch: "([A-Z]+-[0-9]+)",
+			IssueinfoStrLink: "https://jira.example.com/browse/$1"})},
+	{action: "download a file of a submit",
+		inf: IssueInfos{IssueinfoStrID: "1001", IssueinfoStrRevCur: "abc123",
+			IssueinfoStrFile: "src/a.go"},
+		reqs: []fakeReq{
+			{req: "GET /a/changes/1001/revisions/abc123/files/src%2Fa.go/download",
+				resp: "files/a.go"}},
+		chk: fakeChkFile("a.go", "package a\n")},
+}
+
+func TestGerritOffline(t *testing.T) {
+	fakeRun(t, CategoryGerrit, gerritFakeCases)
+}
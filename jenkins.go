@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"os"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"gitee.com/bon-ami/eztools/v6"
@@ -59,9 +66,9 @@ func jenkinsParseBlds(i interface{}) (IssueInfoSlc, error) {
 	return issues, nil
 }
 
-func JenkinsListBlds(svr *svrs, authInfo eztools.AuthInfo,
+func JenkinsListBlds(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	issueInfo, err := jenkinsChooseJob(svr, authInfo, issueInfo)
+	issueInfo, err := jenkinsChooseJob(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +79,7 @@ func JenkinsListBlds(svr *svrs, authInfo eztools.AuthInfo,
 	}
 	var RestAPIStr = "/api/json?tree=builds[number,url]{," +
 		issueInfo[IssueinfoStrSize] + "}"
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		svr.URL+"job/"+issueInfo[IssueinfoStrProj]+RestAPIStr, authInfo, nil, svr.Magic)
 	if err != nil || nil == bodyMap || len(bodyMap) < 1 {
 		return nil, err
@@ -80,18 +87,18 @@ func JenkinsListBlds(svr *svrs, authInfo eztools.AuthInfo,
 	return jenkinsParseBlds(bodyMap[IssueinfoStrBld])
 }
 
-func jenkinsChooseBld(svr *svrs, authInfo eztools.AuthInfo,
+func jenkinsChooseBld(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfos, error) {
-	issueInfo, err := jenkinsChooseJob(svr, authInfo, issueInfo)
+	issueInfo, err := jenkinsChooseJob(ctx, svr, authInfo, issueInfo)
 	if err != nil || len(issueInfo[IssueinfoStrID]) > 0 {
 		return issueInfo, err
 	}
 
-	issues, err := JenkinsListBlds(svr, authInfo, issueInfo)
+	issues, err := JenkinsListBlds(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return issueInfo, eztools.ErrNoValidResults
 	}
-	ind := eztools.ChooseStringsWtIDs(
+	ind := chooseStringsWtIDs(
 		func() int {
 			return len(issues)
 		},
@@ -108,17 +115,17 @@ func jenkinsChooseBld(svr *svrs, authInfo eztools.AuthInfo,
 	return issueInfo, nil
 }
 
-func jenkinsChooseJob(svr *svrs, authInfo eztools.AuthInfo,
+func jenkinsChooseJob(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfos, error) {
 	if len(issueInfo[IssueinfoStrProj]) > 0 {
 		return issueInfo, nil
 	}
 
-	issues, err := JenkinsListJobs(svr, authInfo, issueInfo)
+	issues, err := JenkinsListJobs(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return issueInfo, eztools.ErrNoValidResults
 	}
-	ind := eztools.ChooseStringsWtIDs(
+	ind := chooseStringsWtIDs(
 		func() int {
 			return len(issues)
 		},
@@ -192,10 +199,10 @@ func jenkinsParseJobs(i interface{}, num string) (IssueInfoSlc, error) {
 	return issues, nil
 }
 
-func JenkinsListJobs(svr *svrs, authInfo eztools.AuthInfo,
+func JenkinsListJobs(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	const RestAPIStr = "api/json"
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		svr.URL+RestAPIStr, authInfo, nil, svr.Magic)
 	if err != nil || nil == bodyMap || len(bodyMap) < 1 {
 		return nil, err
@@ -419,9 +426,9 @@ func jenkinsParseDtlBld(bodyMap map[string]interface{}) (IssueInfos, error) {
 	return issueInfo, nil
 }
 
-func JenkinsDetailOnBld(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	issueInfo, err := jenkinsChooseBld(svr, authInfo, issueInfo)
+func JenkinsDetailOnBld(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	issueInfo, err := jenkinsChooseBld(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -429,7 +436,7 @@ func JenkinsDetailOnBld(svr *svrs, authInfo eztools.AuthInfo,
 		return nil, eztools.ErrInvalidInput
 	}
 	const RestAPIStr = "/api/json"
-	bodyMap, err := restMap(http.MethodGet,
+	bodyMap, err := restMap(ctx, http.MethodGet,
 		svr.URL+"job/"+issueInfo[IssueinfoStrProj]+"/"+
 			issueInfo[IssueinfoStrID]+RestAPIStr,
 		authInfo, nil, svr.Magic)
@@ -440,17 +447,20 @@ func JenkinsDetailOnBld(svr *svrs, authInfo eztools.AuthInfo,
 	return issueInfo.ToSlc(), err
 }
 
-func JenkinsLogOfBld(svr *svrs, authInfo eztools.AuthInfo,
+func JenkinsLogOfBld(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	issueInfo, err := jenkinsChooseBld(svr, authInfo, issueInfo)
+	issueInfo, err := jenkinsChooseBld(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
 	if len(issueInfo[IssueinfoStrProj]) < 1 || len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
+	if jenkinsFollow {
+		return jenkinsFollowLog(ctx, svr, authInfo, issueInfo)
+	}
 	const RestAPIStr = "/consoleText"
-	body, err := restSth(http.MethodGet,
+	body, err := restSth(ctx, http.MethodGet,
 		svr.URL+"job/"+issueInfo[IssueinfoStrProj]+
 			"/"+issueInfo[IssueinfoStrID]+RestAPIStr,
 		authInfo, nil, svr.Magic)
@@ -470,6 +480,460 @@ func JenkinsLogOfBld(svr *svrs, authInfo eztools.AuthInfo,
 			return nil, err
 		}
 	}
-	eztools.ShowStrln(string(bodyBytes))
+	showStrln(string(bodyBytes))
 	return nil, err
 }
+
+var (
+	// jenkinsPollWait is the interval to check a queue item,
+	// or more console output
+	jenkinsPollWait = 2 * time.Second
+	// jenkinsQueueRe matches the ID of a queue item in its URL
+	jenkinsQueueRe = regexp.MustCompile(`/queue/item/(\d+)/?$`)
+)
+
+// jenkinsCrumb gets the crumb of CSRF protection once for the session
+// with a server, or again if renew, and adds it to headers of following
+// requests. A server not issuing crumbs, replying 404, needs none.
+func jenkinsCrumb(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, renew bool) error {
+	return setSess(svr, "crumb", renew, func() (http.Header, error) {
+		const RestAPIStr = "crumbIssuer/api/json"
+		bodyMap, err := restMap(ctx, http.MethodGet,
+			svr.URL+RestAPIStr, authInfo, nil, svr.Magic)
+		switch {
+		case errors.Is(err, eztools.ErrNoValidResults):
+			Log(false, false, "NO crumbs needed for", svr.Name)
+			return nil, nil
+		case err != nil:
+			return nil, err
+		}
+		field, _ := bodyMap["crumbRequestField"].(string)
+		crumb, _ := bodyMap["crumb"].(string)
+		if len(field) < 1 || len(crumb) < 1 {
+			Log(false, false, "NO crumbs got", bodyMap)
+			return nil, eztools.ErrNoValidResults
+		}
+		return http.Header{field: {crumb}}, nil
+	})
+}
+
+// jenkinsNoCrumb checks whether a request is rejected for its crumb,
+// such as one of an expired session
+func jenkinsNoCrumb(err error) bool {
+	var re *restErr
+	return errors.As(err, &re) && re.status == http.StatusForbidden &&
+		strings.Contains(re.body, "No valid crumb")
+}
+
+// jenkinsPost sends a POST request with the crumb, getting it again
+// and sending the request once more, if it is rejected for the crumb.
+// Return: headers of the response
+func jenkinsPost(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, url string) (hdr http.Header, err error) {
+	for renew := false; ; renew = true {
+		if err = jenkinsCrumb(ctx, svr, authInfo, renew); err != nil {
+			return nil, err
+		}
+		_, hdr, err = restHdr(ctx, http.MethodPost, url, authInfo, nil,
+			svr.Magic)
+		if renew || !jenkinsNoCrumb(err) {
+			return hdr, err
+		}
+		Log(false, false, "crumb rejected by", svr.Name, "getting a new one")
+	}
+}
+
+// jenkinsParameterized checks whether a job has parameters
+func jenkinsParameterized(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, job string) (bool, error) {
+	const RestAPIStr = "/api/json?tree=property[parameterDefinitions[name]]"
+	bodyMap, err := restMap(ctx, http.MethodGet,
+		svr.URL+"job/"+job+RestAPIStr, authInfo, nil, svr.Magic)
+	if err != nil {
+		return false, err
+	}
+	props, _ := bodyMap["property"].([]interface{})
+	for _, prop := range props {
+		propMap, _ := prop.(map[string]interface{})
+		defs, _ := propMap["parameterDefinitions"].([]interface{})
+		if len(defs) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// jenkinsFollowQueue waits for a queue item, by its URL from Location,
+// to be a build.
+// Return: number and URL of the build
+func jenkinsFollowQueue(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, loc string) (IssueInfos, error) {
+	m := jenkinsQueueRe.FindStringSubmatch(loc)
+	if m == nil {
+		Log(false, false, "NO queue item in", loc)
+		return nil, eztools.ErrNoValidResults
+	}
+	defer showStrln("")
+	for {
+		bodyMap, err := restMap(ctx, http.MethodGet,
+			svr.URL+"queue/item/"+m[1]+"/api/json",
+			authInfo, nil, svr.Magic)
+		if err != nil {
+			return nil, err
+		}
+		if cancelled, _ := bodyMap["cancelled"].(bool); cancelled {
+			Log(true, false, "queue item", m[1], "cancelled")
+			return nil, eztools.ErrNoValidResults
+		}
+		if exe, ok := bodyMap["executable"].(map[string]interface{}); ok {
+			issues, err := jenkinsParseBlds([]interface{}{exe})
+			if err != nil || len(issues) < 1 {
+				return nil, eztools.ErrNoValidResults
+			}
+			return issues[0], nil
+		}
+		if why, ok := bodyMap["why"].(string); ok {
+			Log(false, false, "queue item", m[1], why)
+		}
+		select {
+		case <-time.After(jenkinsPollWait):
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+		showStr(".")
+	}
+}
+
+// jenkinsTrigger triggers a build of a job with parameters, if any,
+// or defaults of those of a parameterized job,
+// and follows it in the queue.
+// Return: the job, with number and URL of the build,
+// or without them in dry run
+func jenkinsTrigger(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, job string, params url.Values) (
+	IssueInfos, error) {
+	RestAPIStr := "/buildWithParameters"
+	if len(params) > 0 {
+		RestAPIStr += "?" + params.Encode()
+	} else {
+		parameterized, err := jenkinsParameterized(ctx, svr, authInfo, job)
+		if err != nil {
+			return nil, err
+		}
+		if !parameterized {
+			RestAPIStr = "/build"
+		}
+	}
+	hdr, err := jenkinsPost(ctx, svr, authInfo, svr.URL+"job/"+job+RestAPIStr)
+	if err != nil {
+		return nil, err
+	}
+	issueInfo := IssueInfos{IssueinfoStrProj: job}
+	loc := hdr.Get("Location")
+	if len(loc) < 1 {
+		if dryRun {
+			return issueInfo, nil
+		}
+		Log(false, false, "NO queue item replied")
+		return nil, eztools.ErrNoValidResults
+	}
+	bld, err := jenkinsFollowQueue(ctx, svr, authInfo, loc)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(issueInfo, bld)
+	return issueInfo, nil
+}
+
+// JenkinsTriggerBld triggers a build of a job, with parameters
+// as NAME=VALUE separated by & in IssueinfoStrLink, if any
+func JenkinsTriggerBld(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	issueInfo, err := jenkinsChooseJob(ctx, svr, authInfo, issueInfo)
+	if err != nil {
+		return nil, err
+	}
+	if len(issueInfo[IssueinfoStrProj]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	params, err := url.ParseQuery(issueInfo[IssueinfoStrLink])
+	if err != nil {
+		Log(true, false, "parameters must be NAME=VALUE separated by &:", err)
+		return nil, eztools.ErrInvalidInput
+	}
+	issueInfo, err = jenkinsTrigger(ctx, svr, authInfo,
+		issueInfo[IssueinfoStrProj], params)
+	if err != nil {
+		return nil, err
+	}
+	return issueInfo.ToSlc(), nil
+}
+
+// JenkinsStopBld aborts a build
+func JenkinsStopBld(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	issueInfo, err := jenkinsChooseBld(ctx, svr, authInfo, issueInfo)
+	if err != nil {
+		return nil, err
+	}
+	if len(issueInfo[IssueinfoStrProj]) < 1 || len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	const RestAPIStr = "/stop"
+	if _, err = jenkinsPost(ctx, svr, authInfo,
+		svr.URL+"job/"+issueInfo[IssueinfoStrProj]+"/"+
+			issueInfo[IssueinfoStrID]+RestAPIStr); err != nil {
+		return nil, err
+	}
+	return IssueInfos{IssueinfoStrProj: issueInfo[IssueinfoStrProj],
+		IssueinfoStrID: issueInfo[IssueinfoStrID]}.ToSlc(), nil
+}
+
+// jenkinsBldParams gets string and text parameters of a build
+func jenkinsBldParams(bodyMap map[string]interface{}) url.Values {
+	params := make(url.Values)
+	actSlc, _ := bodyMap["actions"].([]interface{})
+	for _, act1Any := range actSlc {
+		act1Map, ok := act1Any.(map[string]interface{})
+		if !ok || act1Map["_class"] != "hudson.model.ParametersAction" {
+			continue
+		}
+		issueInfo := make(IssueInfos)
+		jenkinsParseBldParams(act1Map, issueInfo)
+		for k, v := range issueInfo {
+			params.Set(k, v)
+		}
+	}
+	return params
+}
+
+// JenkinsRebuildBld triggers a build of the same job,
+// with the same string and text parameters of a build
+func JenkinsRebuildBld(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	issueInfo, err := jenkinsChooseBld(ctx, svr, authInfo, issueInfo)
+	if err != nil {
+		return nil, err
+	}
+	if len(issueInfo[IssueinfoStrProj]) < 1 || len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	const RestAPIStr = "/api/json"
+	bodyMap, err := restMap(ctx, http.MethodGet,
+		svr.URL+"job/"+issueInfo[IssueinfoStrProj]+"/"+
+			issueInfo[IssueinfoStrID]+RestAPIStr,
+		authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	if len(bodyMap) < 1 {
+		return nil, eztools.ErrNoValidResults
+	}
+	issueInfo, err = jenkinsTrigger(ctx, svr, authInfo,
+		issueInfo[IssueinfoStrProj], jenkinsBldParams(bodyMap))
+	if err != nil {
+		return nil, err
+	}
+	return issueInfo.ToSlc(), nil
+}
+
+var (
+	// jenkinsFollow is -follow, to follow console output of builds
+	jenkinsFollow bool
+	// jenkinsFollowRe is -follow-match, to show matching lines only
+	jenkinsFollowRe *regexp.Regexp
+	errBldUnstable  = errors.New("build unstable")
+	errBldFailure   = errors.New("build failed")
+	errBldAborted   = errors.New("build aborted")
+	errBldOther     = errors.New("build not successful")
+)
+
+// setFollow checks and sets -follow and -follow-match
+func setFollow(follow bool, match string) (err error) {
+	jenkinsFollow, jenkinsFollowRe = follow, nil
+	if len(match) < 1 {
+		return nil
+	}
+	if !follow {
+		Log(true, false, "-follow-match is to be used with -follow")
+		return eztools.ErrInvalidInput
+	}
+	if jenkinsFollowRe, err = regexp.Compile(match); err != nil {
+		Log(true, false, err)
+		return eztools.ErrInvalidInput
+	}
+	return nil
+}
+
+// jenkinsFollowLog shows console output of a build as it goes,
+// or saves it to the file, if any, till the build finishes.
+// Lines not matching -follow-match, if any, are skipped.
+// Return: details of the build, with an error for results other than
+// SUCCESS
+func jenkinsFollowLog(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	bldURL := svr.URL + "job/" + issueInfo[IssueinfoStrProj] + "/" +
+		issueInfo[IssueinfoStrID]
+	write := func(s string) error {
+		showStr(s)
+		return nil
+	}
+	if len(issueInfo[IssueinfoStrFile]) > 0 {
+		f, err := os.Create(issueInfo[IssueinfoStrFile])
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		write = func(s string) error {
+			_, err := f.WriteString(s)
+			return err
+		}
+	}
+	// partial is the last line without a line break yet
+	var partial string
+	show := func(s string, last bool) error {
+		lines := strings.SplitAfter(partial+s, "\n")
+		partial = ""
+		if !last {
+			partial = lines[len(lines)-1]
+			lines = lines[:len(lines)-1]
+		}
+		for _, line := range lines {
+			if len(line) < 1 || (jenkinsFollowRe != nil &&
+				!jenkinsFollowRe.MatchString(
+					strings.TrimRight(line, "\r\n"))) {
+				continue
+			}
+			if err := write(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	const RestAPIStr = "/logText/progressiveText?start="
+	for start := "0"; ; {
+		body, hdr, err := restHdr(ctx, http.MethodGet,
+			bldURL+RestAPIStr+start, authInfo, nil, svr.Magic)
+		if err != nil {
+			return nil, err
+		}
+		if err = show(string(body), false); err != nil {
+			return nil, err
+		}
+		if size := hdr.Get("X-Text-Size"); len(size) > 0 {
+			start = size
+		}
+		if hdr.Get("X-More-Data") != "true" {
+			break
+		}
+		select {
+		case <-time.After(jenkinsPollWait):
+		case <-ctx.Done():
+			show("", true)
+			return nil, context.Cause(ctx)
+		}
+	}
+	if err := show("", true); err != nil {
+		return nil, err
+	}
+	// the result is recorded after the log ends
+	for {
+		bodyMap, err := restMap(ctx, http.MethodGet, bldURL+"/api/json",
+			authInfo, nil, svr.Magic)
+		if err != nil {
+			return nil, err
+		}
+		if issueInfo, err = jenkinsParseDtlBld(bodyMap); err != nil {
+			return nil, err
+		}
+		if building, _ := bodyMap["building"].(bool); !building {
+			break
+		}
+		select {
+		case <-time.After(jenkinsPollWait):
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+	var err error
+	switch issueInfo[IssueinfoStrResult] {
+	case "SUCCESS":
+	case "UNSTABLE":
+		err = errBldUnstable
+	case "FAILURE":
+		err = errBldFailure
+	case "ABORTED":
+		err = errBldAborted
+	default:
+		// such as NOT_BUILT
+		err = errBldOther
+	}
+	if err != nil {
+		Log(true, false, "build", bldURL, issueInfo[IssueinfoStrResult])
+		return nil, err
+	}
+	return issueInfo.ToSlc(), nil
+}
+
+// jenkinsBackend is the backend of Jenkins servers
+type jenkinsBackend struct{}
+
+func init() {
+	regBackend(jenkinsBackend{})
+}
+
+// Type is CategoryJenkins
+func (jenkinsBackend) Type() string {
+	return CategoryJenkins
+}
+
+// PassType is PassBasic
+func (jenkinsBackend) PassType() string {
+	return PassBasic
+}
+
+// PassHint is about API tokens
+func (jenkinsBackend) PassHint() string {
+	return "Use " + PassBasic + " with an API token from user settings, " +
+		"or " + PassBearer + " if the server takes bearer tokens."
+}
+
+// Magic is not needed
+func (jenkinsBackend) Magic() string {
+	return ""
+}
+
+// Actions of jobs and builds
+func (jenkinsBackend) Actions() []action2Func {
+	return []action2Func{
+		{"list jobs", JenkinsListJobs},
+		{"show details of a build", JenkinsDetailOnBld},
+		{"get log of a build", JenkinsLogOfBld},
+		{"list builds", JenkinsListBlds},
+		{"trigger a build", JenkinsTriggerBld},
+		{"stop a build", JenkinsStopBld},
+		{"rebuild a build", JenkinsRebuildBld}}
+}
+
+// ListMine is not supported, since IDs of builds are chosen among jobs
+func (jenkinsBackend) ListMine() (actionFunc, string, string) {
+	return nil, "", ""
+}
+
+// Input asks for input of actions of jobs and builds
+func (jenkinsBackend) Input(ctx context.Context, svr *svrs, _ eztools.AuthInfo,
+	action string, inf IssueInfos) bool {
+	switch action {
+	case "list jobs", "list builds":
+		useInputOrPromptStr(svr, inf, IssueinfoStrSize,
+			"max number of results")
+	case "get log of a build":
+		useInputOrPromptStr(svr, inf, IssueinfoStrFile,
+			"log file name to save as")
+	case "trigger a build":
+		useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+			"parameters, as NAME=VALUE separated by &")
+	}
+	return false
+}
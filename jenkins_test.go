@@ -1,8 +1,13 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	"gitee.com/bon-ami/eztools/v6"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -31,3 +36,169 @@ func TestJenkinsShowBuildDetails(t *testing.T) {
 func TestJenkinsGetBuildLog(t *testing.T) {
 	JenkinsTests(t, "get log of a build")
 }
+
+var jenkinsFakeCases = []fakeCase{
+	{action: "list jobs",
+		reqs: []fakeReq{
+			{req: "GET /api/json", resp: "jobs.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrName: "job1"})},
+	{action: "show details of a build",
+		inf: IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "5"},
+		reqs: []fakeReq{
+			{req: "GET /job/job1/5/api/json", resp: "build.json"}},
+		chk: fakeChk(1, IssueInfos{"BRANCH": "master",
+			IssueinfoStrAuthor: "Test Er", IssueinfoStrBldin: "false",
+			IssueinfoStrResult: "SUCCESS"})},
+	{action: "get log of a build",
+		inf: IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "5",
+			IssueinfoStrFile: "build.log"},
+		reqs: []fakeReq{
+			{req: "GET /job/job1/5/consoleText", resp: "console.txt"}},
+		chk: fakeChkFile("build.log",
+			"Started by user Test Er\nFinished: SUCCESS\n")},
+	{action: "list builds",
+		inf: IssueInfos{IssueinfoStrProj: "job1"},
+		reqs: []fakeReq{
+			{req: "GET /job/job1/api/json?tree=builds[number,url]{,10}",
+				resp: "builds.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "5"})},
+	{action: "list builds",
+		inf: IssueInfos{IssueinfoStrProj: "nojob"},
+		reqs: []fakeReq{
+			{req: "GET /job/nojob/api/json?tree=builds[number,url]{,10}",
+				code: http.StatusNotFound}},
+		err: eztools.ErrNoValidResults},
+	{action: "trigger a build",
+		inf: IssueInfos{IssueinfoStrProj: "job1",
+			IssueinfoStrLink: "BRANCH=dev&TAG=v 1"},
+		reqs: []fakeReq{
+			{req: "GET /crumbIssuer/api/json", resp: "crumb.json"},
+			{req: "POST /job/job1/buildWithParameters?BRANCH=dev&TAG=v+1",
+				reqHdr: jenkinsFakeCrumb, code: http.StatusCreated,
+				hdr: map[string]string{"Location": jenkinsFakeQueue}},
+			{req: "GET /queue/item/12/api/json", resp: "queue_waiting.json",
+				reqHdr: jenkinsFakeCrumb, noDry: true},
+			{req: "GET /queue/item/12/api/json", resp: "queue_left.json",
+				noDry: true}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrProj: "job1",
+			IssueinfoStrID: "6"})},
+	{action: "trigger a build",
+		inf: IssueInfos{IssueinfoStrProj: "job2"},
+		reqs: []fakeReq{
+			{req: "GET " + jenkinsFakeParams("job2"), resp: "job_plain.json"},
+			// no CSRF protection
+			{req: "GET /crumbIssuer/api/json", code: http.StatusNotFound},
+			{req: "POST /job/job2/build", code: http.StatusCreated,
+				hdr: map[string]string{"Location": jenkinsFakeQueue}},
+			{req: "GET /queue/item/12/api/json", resp: "queue_cancelled.json",
+				noDry: true}},
+		err: eztools.ErrNoValidResults},
+	{action: "trigger a build",
+		inf: IssueInfos{IssueinfoStrProj: "job1"},
+		reqs: []fakeReq{
+			{req: "GET " + jenkinsFakeParams("job1"), resp: "job.json"},
+			{req: "GET /crumbIssuer/api/json", resp: "crumb.json",
+				hdr: map[string]string{"Set-Cookie": "JSESSIONID=s0; Path=/"}},
+			// of defaults, with the crumb of an expired session
+			{req: "POST /job/job1/buildWithParameters",
+				reqHdr: "Cookie: JSESSIONID=s0",
+				code:   http.StatusForbidden, resp: "no_crumb.html"},
+			{req: "GET /crumbIssuer/api/json", resp: "crumb_new.json",
+				hdr:   map[string]string{"Set-Cookie": "JSESSIONID=s1; Path=/"},
+				noDry: true},
+			{req: "POST /job/job1/buildWithParameters",
+				reqHdr: "Jenkins-Crumb: decaf", code: http.StatusCreated,
+				hdr: map[string]string{"Location": jenkinsFakeQueue}},
+			{req: "GET /queue/item/12/api/json", resp: "queue_left.json",
+				reqHdr: "Cookie: JSESSIONID=s1", noDry: true}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrProj: "job1",
+			IssueinfoStrID: "6"})},
+	{action: "stop a build",
+		inf: IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "6"},
+		reqs: []fakeReq{
+			{req: "GET /crumbIssuer/api/json", resp: "crumb.json"},
+			{req: "POST /job/job1/6/stop", reqHdr: jenkinsFakeCrumb}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrProj: "job1",
+			IssueinfoStrID: "6"})},
+	{action: "rebuild a build",
+		inf: IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "5"},
+		reqs: []fakeReq{
+			{req: "GET /job/job1/5/api/json", resp: "build.json"},
+			{req: "GET /crumbIssuer/api/json", resp: "crumb.json"},
+			// only string parameters
+			{req: "POST /job/job1/buildWithParameters?BRANCH=master",
+				reqHdr: jenkinsFakeCrumb, code: http.StatusCreated,
+				hdr: map[string]string{"Location": jenkinsFakeQueue}},
+			{req: "GET /queue/item/12/api/json", resp: "queue_left.json",
+				noDry: true}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrProj: "job1",
+			IssueinfoStrID: "6"})},
+}
+
+const (
+	jenkinsFakeCrumb = "Jenkins-Crumb: c0ffee"
+	jenkinsFakeQueue = fakeURL + "/queue/item/12/"
+)
+
+// jenkinsFakeParams is the path of parameters of a job
+func jenkinsFakeParams(job string) string {
+	return "/job/" + job + "/api/json?tree=property[parameterDefinitions[name]]"
+}
+
+func TestJenkinsOffline(t *testing.T) {
+	wait := jenkinsPollWait
+	defer func() {
+		jenkinsPollWait = wait
+	}()
+	jenkinsPollWait = time.Millisecond
+	fakeRun(t, CategoryJenkins, jenkinsFakeCases)
+}
+
+func TestJenkinsFollow(t *testing.T) {
+	wait := jenkinsPollWait
+	defer func() {
+		jenkinsPollWait = wait
+		setFollow(false, "")
+	}()
+	jenkinsPollWait = time.Millisecond
+	if err := setFollow(false, "^Step"); !errors.Is(err,
+		eztools.ErrInvalidInput) {
+		t.Error("invalid input expected without -follow, got", err)
+	}
+	if err := setFollow(true, "^Step"); err != nil {
+		t.Fatal(err)
+	}
+	const progressive = "GET /job/job1/%d/logText/progressiveText?start=%d"
+	reqs := func(id int, bld string) []fakeReq {
+		return []fakeReq{
+			{req: fmt.Sprintf(progressive, id, 0), resp: "progressive1.txt",
+				hdr: map[string]string{"X-Text-Size": "37",
+					"X-More-Data": "true"}},
+			{req: fmt.Sprintf(progressive, id, 37), resp: "progressive2.txt",
+				hdr: map[string]string{"X-Text-Size": "61"}},
+			{req: fmt.Sprintf("GET /job/job1/%d/api/json", id), resp: bld}}
+	}
+	fakeRun(t, CategoryJenkins, []fakeCase{
+		{action: "get log of a build",
+			inf: IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "5",
+				IssueinfoStrFile: "build.log"},
+			reqs: reqs(5, "build.json"),
+			chk:  fakeChkFile("build.log", "Step 1 ok\nStep 2 ok\n")},
+		{action: "get log of a build",
+			inf:  IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "7"},
+			reqs: reqs(7, "build_failed.json"),
+			err:  errBldFailure},
+		// output ends before the result is recorded
+		{action: "get log of a build",
+			inf: IssueInfos{IssueinfoStrProj: "job1", IssueinfoStrID: "8"},
+			reqs: append(reqs(8, "build_building.json"), fakeReq{
+				req: "GET /job/job1/8/api/json", resp: "build_not_built.json"}),
+			err: errBldOther},
+	})
+	if exitCode(&actErr{err: errBldFailure}) != extBldFailure {
+		t.Error("exit code of failed builds expected")
+	}
+	if exitCode(&actErr{err: errBldOther}) != extBldOther {
+		t.Error("exit code of other results of builds expected")
+	}
+}
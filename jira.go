@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
-	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -49,7 +51,7 @@ func changeTypicalJiraNum(svr *svrs, num, base string, smart, changes bool) (str
 		inf := base + num
 		if changes {
 			//if eztools.Verbose > 0 {
-			eztools.ShowStrln("Auto changed to " + inf)
+			showStrln("Auto changed to " + inf)
 			//}
 		}
 		return inf, true
@@ -87,7 +89,7 @@ func parseTypicalJiraNum(svr *svrs, num string) (nonDigit,
 				// parts[0]=""
 				if len(parts) == 2 && len(parts[1]) > 0 {
 					if eztools.Debugging && eztools.Verbose > 1 {
-						eztools.ShowStrln("Auto changing to " +
+						showStrln("Auto changing to " +
 							svr.Proj + typicalJiraSeparator + parts[1])
 					}
 					return svr.Proj + typicalJiraSeparator, parts[1], true, true
@@ -114,8 +116,9 @@ func jiraParse1Field(m map[string]interface{}) (issueInfoOut IssueInfos) {
 		switch i {
 		case IssueinfoStrAssignee:
 			val := chkNLoopStringMap(v, "",
-				[]string{IssueinfoStrDispname})
+				[]string{IssueinfoStrDispname, IssueinfoStrName})
 			issueInfoOut[IssueinfoStrDispname] = val[0]
+			issueInfoOut[IssueinfoStrAssignee] = val[1]
 		case IssueinfoStrProj:
 			val := chkNLoopStringMap(v, "",
 				[]string{IssueinfoStrKey})
@@ -154,7 +157,10 @@ func jiraParse1Issue(m map[string]interface{}) (issueInfoOut IssueInfos) {
 	return
 }
 
-func jiraParseTrans(m map[string]interface{}) (tranNames, tranIDs []string) {
+// jiraParseTrans parses transitions
+// Return values: names, IDs, and names of states to, of transitions
+func jiraParseTrans(m map[string]interface{}) (tranNames, tranIDs,
+	tranTos []string) {
 	loopStringMap(m, "transitions", nil,
 		func(i string, v interface{}) bool {
 			arrI, ok := v.([]interface{})
@@ -169,15 +175,6 @@ func jiraParseTrans(m map[string]interface{}) (tranNames, tranIDs []string) {
 						"map[string]interface{}")
 					continue
 				}
-				/*to, ok := tran1["to"].(map[string]interface{})
-				if !ok { // to contains description, id, name and statusCategory=id+key+name
-					// TODO: dynamically calculate path to resolved status
-					LogTypeErr(stdOutput, false,
-						reflect.TypeOf(tran1["to"]).
-							String() +
-							" got instead of string")
-					return false
-				}*/
 				tranN, ok := tran1["name"].(string)
 				if !ok {
 					LogTypeErr(tran1["name"], "string")
@@ -188,21 +185,85 @@ func jiraParseTrans(m map[string]interface{}) (tranNames, tranIDs []string) {
 					LogTypeErr(tran1["id"], "string")
 					return false
 				}
+				// to contains description, id, name and statusCategory=id+key+name
+				var tranT string
+				if to, ok := tran1["to"].(map[string]interface{}); ok {
+					tranT, _ = to["name"].(string)
+				}
 				tranNames = append(tranNames, tranN)
 				tranIDs = append(tranIDs, tranI)
+				tranTos = append(tranTos, tranT)
 				//eztools.ShowStrln("ID=" + tranI + ", name=" + tranN)
 			}
 			return true
 		})
 	if eztools.Debugging && eztools.Verbose > 1 {
-		eztools.ShowSthln(tranNames)
-		eztools.ShowSthln(tranIDs)
+		showStrln(tranNames)
+		showStrln(tranIDs)
 	}
 	return
 }
 
-func jiraParseIssues(m map[string]interface{}) IssueInfoSlc {
-	return parseIssues("issues", m, jiraParse1Issue)
+// jiraParseIssues parses cases, with values of fields, if any,
+// besides those of jiraParse1Field
+func jiraParseIssues(m map[string]interface{},
+	fields ...string) IssueInfoSlc {
+	if len(fields) < 1 {
+		return parseIssues("issues", m, jiraParse1Issue)
+	}
+	return parseIssues("issues", m,
+		func(m map[string]interface{}) IssueInfos {
+			issueInfo := jiraParse1Issue(m)
+			fieldMap, ok := m["fields"].(map[string]interface{})
+			if issueInfo == nil || !ok {
+				return issueInfo
+			}
+			for _, field := range fields {
+				if _, ok := issueInfo[field]; ok {
+					continue
+				}
+				if val := jiraFieldStr(fieldMap[field]); len(val) > 0 {
+					issueInfo[field] = val
+				}
+			}
+			return issueInfo
+		})
+}
+
+// jiraFieldStr gets a string of a value of any field, such as
+// a custom one, by value, name or displayName of an object,
+// joining those of arrays by commas
+func jiraFieldStr(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		vals := make([]string, 0, len(v))
+		for _, v1 := range v {
+			if val := jiraFieldStr(v1); len(val) > 0 {
+				vals = append(vals, val)
+			}
+		}
+		return strings.Join(vals, ",")
+	case map[string]interface{}:
+		for _, k := range []string{"value", IssueinfoStrName,
+			IssueinfoStrDispname, IssueinfoStrKey} {
+			if val, ok := v[k].(string); ok {
+				return val
+			}
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
 }
 
 // jiraParse1Cmt parses
@@ -264,7 +325,7 @@ func jiraParseCmts(m map[string]interface{}) (IssueInfoSlc, error) {
 	return issues, nil
 }
 
-func JiraTransfer(svr *svrs, authInfo eztools.AuthInfo,
+func JiraTransfer(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrSummary]) < 1 {
@@ -320,22 +381,33 @@ func JiraTransfer(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, issueInfo[IssueinfoStrID]+" in transition")
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPut,
+	_, err = restSth(ctx, http.MethodPut,
 		svr.URL+urlAPI4JR+issueInfo[IssueinfoStrID],
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
 	// result/body is []uint8, if success
 	return nil, err
 }
 
+// mustFlds is a field, required or not, from metadata of
+// transitions or creation
 type mustFlds struct {
-	name    string
+	key, name string
+	// tp and items are of the schema, such as "array" of "string"
+	tp, items string
+	required  bool
+	// hasDef is true if the server sets a default value
+	hasDef bool
+	// ops are operations of editing, such as set, add and remove
+	ops []string
+	// choices are from allowedValues, with IDs and values, if any
 	choices IssueInfoSlc
 }
 
-func jiraParseAllowedVal(vals interface{}) (musts []mustFlds) {
+// jiraParseAllowedVal gets IDs, and values or names, of allowedValues
+func jiraParseAllowedVal(vals interface{}) (choices IssueInfoSlc) {
 	valsSlc, ok := vals.([]interface{})
 	if !ok {
 		LogTypeErr(vals, "slice")
@@ -347,28 +419,61 @@ func jiraParseAllowedVal(vals interface{}) (musts []mustFlds) {
 			LogTypeErr(val1, "map of string to interface")
 			continue
 		}
-		for _, i := range [...]string{"id", "value"} {
-			val1Any := val1Map[i]
-			if val1Any == nil {
-				Log(stdOutput, false, "NO "+i+" found")
+		id, _ := val1Map["id"].(string)
+		if len(id) < 1 {
+			Log(stdOutput, false, "NO id found")
+			continue
+		}
+		for _, i := range [...]string{"value", IssueinfoStrName,
+			IssueinfoStrKey} {
+			if val1Str, ok := val1Map[i].(string); ok {
+				choices = append(choices, IssueInfos{
+					IssueinfoStrID: id, IssueinfoStrVal: val1Str})
 				break
 			}
-			val1Str, ok := val1Any.(string)
-			if !ok {
-				Log(stdOutput, false, reflect.TypeOf(val1Any).String()+
-					" got instead of string")
-				break
+		}
+	}
+	return
+}
+
+// jiraParseFlds parses fields of metadata, sorted by keys
+func jiraParseFlds(fieldMap map[string]interface{}) (flds []mustFlds) {
+	for fldKey, fldVal := range fieldMap {
+		fldValMap, ok := fldVal.(map[string]interface{})
+		if !ok {
+			LogTypeErr(fldVal, "map[string]interface{}")
+			continue
+		}
+		fld := mustFlds{key: fldKey}
+		fld.name, _ = fldValMap[IssueinfoStrName].(string)
+		fld.required, _ = fldValMap["required"].(bool)
+		fld.hasDef, _ = fldValMap["hasDefaultValue"].(bool)
+		if schema, ok := fldValMap["schema"].(map[string]interface{}); ok {
+			fld.tp, _ = schema["type"].(string)
+			fld.items, _ = schema["items"].(string)
+		}
+		if vals := fldValMap["allowedValues"]; vals != nil {
+			fld.choices = jiraParseAllowedVal(vals)
+		}
+		ops, _ := fldValMap["operations"].([]interface{})
+		for _, op := range ops {
+			if opStr, ok := op.(string); ok {
+				fld.ops = append(fld.ops, opStr)
 			}
-			eztools.ShowStrln(i + "=" + val1Str)
-			//musts = append(musts, issueInfoSlc)//here
 		}
+		flds = append(flds, fld)
 	}
+	slices.SortFunc(flds, func(a, b mustFlds) int {
+		return strings.Compare(a.key, b.key)
+	})
 	return
 }
 
-func jiraGetTransMustFlds(svr *svrs, authInfo eztools.AuthInfo,
-	id string) (mustMap []mustFlds, err error) {
-	bodyMap, err := jiraGetTransExpanded(svr, authInfo, id, "?expand=transitions.fields")
+// jiraGetTransMustFlds gets required fields with choices of transitions
+func jiraGetTransMustFlds(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, id string) (mustMap []mustFlds, err error) {
+	bodyMap, err := jiraGetTransExpanded(ctx, svr, authInfo, id,
+		"?expand=transitions.fields")
 	if err != nil {
 		return nil, err
 	}
@@ -400,47 +505,12 @@ func jiraGetTransMustFlds(svr *svrs, authInfo eztools.AuthInfo,
 						"map[string]interface{}")
 					return false
 				}
-			FLD_MAP_IN_TRAN_MUST_FLDS:
-				for _ /*fldName*/, fldVal := range fieldMap { //here
-					//eztools.ShowStrln(fldName)
-					fldValMap, ok := fldVal.(map[string]interface{})
-					if !ok {
-						LogTypeErr(fldVal,
-							"map[string]interface{}")
-						continue
-					}
-					fldReq := fldValMap["required"]
-					if fldReq == nil {
+				for _, fld := range jiraParseFlds(fieldMap) {
+					if !fld.required || len(fld.name) < 1 ||
+						len(fld.choices) < 1 {
 						continue
 					}
-					fldReqBl, ok := fldReq.(bool)
-					if !ok {
-						LogTypeErr(fldReq, "bool")
-						continue
-					}
-					if !fldReqBl {
-						continue
-					}
-					var mustFld1 mustFlds
-					//var pairs issueInfoSlc //here
-					for fld1Name, fld1Val := range fldValMap {
-						switch fld1Name {
-						case "name":
-							fld1ValStr, ok := fld1Val.(string)
-							if !ok {
-								LogTypeErr(fld1Val,
-									"string for "+fld1Name)
-								continue FLD_MAP_IN_TRAN_MUST_FLDS
-							}
-							mustFld1.name = fld1ValStr
-						case "allowedValues":
-							jiraParseAllowedVal(fld1Val)
-						}
-					}
-					if len(mustFld1.name) < 1 || len(mustFld1.choices) < 1 {
-						continue
-					}
-					mustMap = append(mustMap, mustFld1)
+					mustMap = append(mustMap, fld)
 				}
 			}
 			return true
@@ -448,20 +518,21 @@ func jiraGetTransMustFlds(svr *svrs, authInfo eztools.AuthInfo,
 	return mustMap, err
 }
 
-func jiraGetTransExpanded(svr *svrs, authInfo eztools.AuthInfo,
+func jiraGetTransExpanded(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo,
 	id, exp string) (bodyMap map[string]interface{}, err error) {
-	return restMap(http.MethodGet, svr.URL+urlAPI4JR+
+	return restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
 		id+"/transitions"+exp, authInfo, nil, svr.Magic)
 }
 
-func jiraGetTrans(svr *svrs, authInfo eztools.AuthInfo,
-	id string) (tranNames, tranIDs []string, err error) {
-	bodyMap, err := jiraGetTransExpanded(svr, authInfo, id, "")
+func jiraGetTrans(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	id string) (tranNames, tranIDs, tranTos []string, err error) {
+	bodyMap, err := jiraGetTransExpanded(ctx, svr, authInfo, id, "")
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	tranNames, tranIDs = jiraParseTrans(bodyMap)
-	return tranNames, tranIDs, nil
+	tranNames, tranIDs, tranTos = jiraParseTrans(bodyMap)
+	return tranNames, tranIDs, tranTos, nil
 }
 
 func jiraChooseTran(tranName string, tranNames, tranIDs []string) (string, error) {
@@ -481,9 +552,9 @@ func jiraChooseTran(tranName string, tranNames, tranIDs []string) (string, error
 				noInteractionAllowed()
 				return "", eztools.ErrInvalidInput
 			}
-			eztools.ShowStrln(
+			showStrln(
 				"There are following transitions available.")
-			i, _ := eztools.ChooseStrings(tranNames)
+			i, _ := chooseStrings(tranNames)
 			if i == eztools.InvalidID {
 				return "", eztools.ErrInvalidInput
 			}
@@ -497,7 +568,7 @@ func jiraChooseTran(tranName string, tranNames, tranIDs []string) (string, error
 }
 
 // jiraTranExec transition issue {id} to state {tranID}
-func jiraTranExec(svr *svrs, authInfo eztools.AuthInfo,
+func jiraTranExec(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	id, tranID string) (err error) {
 	type tranJsons struct {
 		Transition struct {
@@ -513,10 +584,10 @@ func jiraTranExec(svr *svrs, authInfo eztools.AuthInfo,
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, id+" in transition")
 		if eztools.Verbose > 1 {
-			eztools.ShowByteln(jsonStr)
+			showByteln(jsonStr)
 		}
 	}
-	_, err = restSth(http.MethodPost, svr.URL+urlAPI4JR+
+	_, err = restSth(ctx, http.MethodPost, svr.URL+urlAPI4JR+
 		id+"/transitions", authInfo,
 		bytes.NewReader(jsonStr), svr.Magic)
 	// replies to transitions contains no body
@@ -531,10 +602,10 @@ func jiraTranExec(svr *svrs, authInfo eztools.AuthInfo,
 }
 
 // jiraCmtNTran is transitions for reject & close, adding comments
-func jiraCmtNTran(svr *svrs, authInfo eztools.AuthInfo,
+func jiraCmtNTran(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, steps []string) (err error) {
 	if len(issueInfo[IssueinfoStrComments]) > 0 {
-		_, err := jiraAddComment1(svr, authInfo, issueInfo)
+		_, err := jiraAddComment1(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			Log(true, false, err)
 		}
@@ -542,11 +613,11 @@ func jiraCmtNTran(svr *svrs, authInfo eztools.AuthInfo,
 	var tranNames, tranIDs []string
 	for i, tran := range steps {
 		if eztools.Debugging && eztools.Verbose > 1 {
-			eztools.ShowStrln("Trying " + tran)
+			showStrln("Trying " + tran)
 		}
 		if tranNames == nil || tranIDs == nil ||
 			len(tranNames) < 1 || len(tranIDs) < 1 {
-			tranNames, tranIDs, err = jiraGetTrans(svr, authInfo,
+			tranNames, tranIDs, _, err = jiraGetTrans(ctx, svr, authInfo,
 				issueInfo[IssueinfoStrID])
 			if err != nil {
 				return err
@@ -562,7 +633,7 @@ func jiraCmtNTran(svr *svrs, authInfo eztools.AuthInfo,
 			if i == len(steps)-1 {
 				// return error if the last step fails,
 				// since it is a key one
-				if err == eztools.ErrNoValidResults {
+				if errors.Is(err, eztools.ErrNoValidResults) {
 					Log(true, false, "No available transitions. Check permission!")
 				}
 				return err
@@ -571,11 +642,11 @@ func jiraCmtNTran(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		tranNames = nil
 		tranIDs = nil
-		err = jiraTranExec(svr, authInfo,
+		err = jiraTranExec(ctx, svr, authInfo,
 			issueInfo[IssueinfoStrID], tranID)
 		if err != nil {
-			if err == errGram {
-				flds, err := jiraGetTransMustFlds(svr, authInfo,
+			if errors.Is(err, errGram) {
+				flds, err := jiraGetTransMustFlds(ctx, svr, authInfo,
 					issueInfo[IssueinfoStrID])
 				if err != nil {
 					Log(true, false, err)
@@ -597,14 +668,14 @@ func jiraConstructFields(in string) string {
 }`
 }
 
-func jiraEditWtFields(svr *svrs, authInfo eztools.AuthInfo,
+func jiraEditWtFields(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, jsonInner string) error {
 	jsonStr := jiraConstructFields(jsonInner)
 	if eztools.Debugging && eztools.Verbose > 0 {
 		Log(false, false, "Processing "+issueInfo[IssueinfoStrID])
 	}
 	//eztools.ShowStrln(jsonStr)
-	_, err := restSth(http.MethodPut,
+	_, err := restSth(ctx, http.MethodPut,
 		svr.URL+urlAPI4JR+
 			issueInfo[IssueinfoStrID],
 		authInfo, strings.NewReader(jsonStr),
@@ -612,8 +683,196 @@ func jiraEditWtFields(svr *svrs, authInfo eztools.AuthInfo,
 	return err
 }
 
-func jiraEditMeta(svr *svrs, authInfo eztools.AuthInfo, id, filter string) (interface{}, error) {
-	bodyMap, err := restMap(http.MethodGet, svr.URL+urlAPI4JR+
+// jiraEdit1 is an edit of a field, by name or key, with an operation
+type jiraEdit1 struct {
+	name, op, val string
+}
+
+// jiraEditOps are operations of edits by their signs
+var jiraEditOps = map[string]string{"=": "set", "+=": "add", "-=": "remove"}
+
+// jiraParseEdit parses an edit as NAME=VALUE, NAME+=VALUE or NAME-=VALUE
+func jiraParseEdit(s string) (jiraEdit1, error) {
+	name, val, ok := strings.Cut(s, "=")
+	op := "="
+	if strings.HasSuffix(name, "+") || strings.HasSuffix(name, "-") {
+		op = name[len(name)-1:] + op
+		name = name[:len(name)-1]
+	}
+	if name = strings.TrimSpace(name); !ok || len(name) < 1 {
+		Log(true, false, "edits must be NAME=VALUE, NAME+=VALUE "+
+			"or NAME-=VALUE, not", s)
+		return jiraEdit1{}, eztools.ErrInvalidInput
+	}
+	return jiraEdit1{name, jiraEditOps[op], val}, nil
+}
+
+// jiraFldIDs gets IDs of fields by their names, in lower case,
+// among all fields of the server
+func jiraFldIDs(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo) (map[string][]string, error) {
+	const RestAPIStr = "rest/api/latest/field"
+	body, err := restSth(ctx, http.MethodGet, svr.URL+RestAPIStr,
+		authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	flds, ok := body.([]interface{})
+	if !ok {
+		LogTypeErr(body, "[]interface{}")
+		return nil, eztools.ErrNoValidResults
+	}
+	ids := make(map[string][]string)
+	for _, fld := range flds {
+		inf := chkNLoopStringMap(fld, "", []string{"id", IssueinfoStrName})
+		if inf == nil || len(inf[0]) < 1 {
+			continue
+		}
+		name := strings.ToLower(inf[1])
+		ids[name] = append(ids[name], inf[0])
+	}
+	return ids, nil
+}
+
+// jiraEditFlds resolves names of fields of edits to editable fields,
+// by keys or names in editmeta, or names of all fields
+func jiraEditFlds(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	id string, edits []jiraEdit1) ([]mustFlds, error) {
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
+		id+"/editmeta", authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	fieldMap, _ := bodyMap["fields"].(map[string]interface{})
+	meta := jiraParseFlds(fieldMap)
+	var ids map[string][]string
+	ret := make([]mustFlds, len(edits))
+	for i, edit := range edits {
+		j := slices.IndexFunc(meta, func(fld mustFlds) bool {
+			return fld.key == edit.name ||
+				strings.EqualFold(fld.name, edit.name)
+		})
+		if j < 0 {
+			if ids == nil {
+				if ids, err = jiraFldIDs(ctx, svr, authInfo); err != nil {
+					return nil, err
+				}
+			}
+			switch byName := ids[strings.ToLower(edit.name)]; len(byName) {
+			case 0:
+				Log(true, false, "NO field", edit.name)
+			case 1:
+				Log(true, false, edit.name, "("+byName[0]+")",
+					"NOT editable for", id)
+			default:
+				Log(true, false, "multiple fields named", edit.name+
+					":", byName)
+			}
+			return nil, eztools.ErrInvalidInput
+		}
+		ret[i] = meta[j]
+	}
+	return ret, nil
+}
+
+// JiraEditFlds edits fields of a case, by -s as NAME=VALUE to set,
+// NAME+=VALUE to add to, or NAME-=VALUE to remove from,
+// multi-value fields, or those prompted for.
+// NAME is the name or key of a field, such as "Fix Version/s"
+// or customfield_10901.
+// Values are checked against allowed ones, if any.
+func JiraEditFlds(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	strs := fieldsOf(issueInfo)
+	if len(strs) < 1 {
+		if uiSilent {
+			noInteractionAllowed()
+			return nil, eztools.ErrInvalidInput
+		}
+		for {
+			s := promptStr("edit, as NAME=VALUE, NAME+=VALUE " +
+				"or NAME-=VALUE. empty to end")
+			if len(s) < 1 {
+				break
+			}
+			strs = append(strs, s)
+		}
+	}
+	var edits []jiraEdit1
+	for _, s := range strs {
+		edit, err := jiraParseEdit(s)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	if len(edits) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	flds, err := jiraEditFlds(ctx, svr, authInfo,
+		issueInfo[IssueinfoStrID], edits)
+	if err != nil {
+		return nil, err
+	}
+	update := make(map[string][]map[string]interface{})
+	res := IssueInfos{IssueinfoStrID: issueInfo[IssueinfoStrID]}
+	for i, edit := range edits {
+		fld := flds[i]
+		if len(fld.ops) > 0 && !slices.Contains(fld.ops, edit.op) {
+			Log(true, false, edit.op, "NOT allowed for", fld.name,
+				"but", fld.ops)
+			return nil, eztools.ErrInvalidInput
+		}
+		var val interface{}
+		switch {
+		case edit.op != "set" && fld.tp != "array":
+			Log(true, false, edit.op, "for multi-value fields only, "+
+				"NOT", fld.name)
+			return nil, eztools.ErrInvalidInput
+		case len(strings.TrimSpace(edit.val)) < 1:
+			// to clear it
+			if fld.tp == "array" {
+				val = []interface{}{}
+			}
+		default:
+			if val, err = jiraFldVal(fld, edit.val); err != nil {
+				return nil, err
+			}
+		}
+		vals, ok := val.([]interface{})
+		if !ok || edit.op == "set" {
+			vals = []interface{}{val}
+		}
+		for _, v := range vals {
+			update[fld.key] = append(update[fld.key],
+				map[string]interface{}{edit.op: v})
+		}
+		if len(res[fld.key]) > 0 {
+			res[fld.key] += "; "
+		}
+		res[fld.key] += edit.op + " " + edit.val
+	}
+	jsonStr, err := json.Marshal(map[string]interface{}{"update": update})
+	if err != nil {
+		return nil, err
+	}
+	if eztools.Debugging && eztools.Verbose > 1 {
+		showByteln(jsonStr)
+	}
+	if _, err = restSth(ctx, http.MethodPut,
+		svr.URL+urlAPI4JR+issueInfo[IssueinfoStrID],
+		authInfo, bytes.NewReader(jsonStr), svr.Magic); err != nil {
+		return nil, err
+	}
+	return res.ToSlc(), nil
+}
+
+func jiraEditMeta(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	id, filter string) (interface{}, error) {
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
 		id+"/editmeta", authInfo, nil, svr.Magic)
 	if err != nil {
 		return nil, err
@@ -639,13 +898,13 @@ func jiraEditMeta(svr *svrs, authInfo eztools.AuthInfo, id, filter string) (inte
 
 // jiraEditMeta get possible reject reasons
 // reject reason stored in IssueinfoStrKey
-func jiraGetDesc(svr *svrs, authInfo eztools.AuthInfo,
+func jiraGetDesc(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (jsonStr string) {
 	if len(svr.Flds.RejectRsn) > 0 {
 		if len(issueInfo[IssueinfoStrKey]) < 1 {
 			// get all possible reasons
-			field, err := jiraEditMeta(svr, authInfo, issueInfo[IssueinfoStrID],
-				svr.Flds.RejectRsn)
+			field, err := jiraEditMeta(ctx, svr, authInfo,
+				issueInfo[IssueinfoStrID], svr.Flds.RejectRsn)
 			if err != nil {
 				Log(false, false, err)
 			} else {
@@ -674,7 +933,7 @@ func jiraGetDesc(svr *svrs, authInfo eztools.AuthInfo,
 	return
 }
 
-func JiraReject(svr *svrs, authInfo eztools.AuthInfo,
+func JiraReject(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	Steps := makeStates(svr, StateTypeTranRej)
 	if Steps == nil {
@@ -682,17 +941,17 @@ func JiraReject(svr *svrs, authInfo eztools.AuthInfo,
 		return nil, errCfg
 	}
 	// set reject reason
-	if jsonStr := jiraGetDesc(svr, authInfo, issueInfo); len(jsonStr) > 0 {
-		if err := jiraEditWtFields(svr,
+	if jsonStr := jiraGetDesc(ctx, svr, authInfo, issueInfo); len(jsonStr) > 0 {
+		if err := jiraEditWtFields(ctx, svr,
 			authInfo, issueInfo,
 			jsonStr); err != nil {
 			return nil, err
 		}
 	}
-	return nil, jiraCmtNTran(svr, authInfo, issueInfo, Steps)
+	return nil, jiraCmtNTran(ctx, svr, authInfo, issueInfo, Steps)
 }
 
-func jiraCloseWtQA(svr *svrs, authInfo eztools.AuthInfo,
+func jiraCloseWtQA(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos, qa string) (IssueInfoSlc, error) {
 	Steps := makeStates(svr, StateTypeTranCls)
 	if Steps == nil {
@@ -711,47 +970,54 @@ func jiraCloseWtQA(svr *svrs, authInfo eztools.AuthInfo,
 			Log(true, false,
 				"NO Tst* fields defined for this server")
 		} else {
-			if err := jiraEditWtFields(svr, authInfo,
+			if err := jiraEditWtFields(ctx, svr, authInfo,
 				issueInfo, jsonStr); err != nil {
 				return nil, err
 			}
 		}
 	}
-	return nil, jiraCmtNTran(svr, authInfo, issueInfo, Steps)
+	return nil, jiraCmtNTran(ctx, svr, authInfo, issueInfo, Steps)
 }
 
-func JiraClose(svr *svrs, authInfo eztools.AuthInfo,
+func JiraClose(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return jiraCloseWtQA(svr, authInfo, issueInfo, issueInfo[IssueinfoStrLink])
+	return jiraCloseWtQA(ctx, svr, authInfo,
+		issueInfo, issueInfo[IssueinfoStrLink])
 }
 
-func JiraCloseDef(svr *svrs, authInfo eztools.AuthInfo,
+func JiraCloseDef(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return jiraCloseWtQA(svr, authInfo,
+	return jiraCloseWtQA(ctx, svr, authInfo,
 		issueInfo, "default AOSP/vendor/design")
 }
 
-func JiraCloseGen(svr *svrs, authInfo eztools.AuthInfo,
+func JiraCloseGen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	return jiraCloseWtQA(svr, authInfo,
+	return jiraCloseWtQA(ctx, svr, authInfo,
 		issueInfo, "general requirement")
 }
 
-func JiraTransition(svr *svrs, authInfo eztools.AuthInfo,
+func JiraTransition(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	names, ids, err := jiraGetTrans(svr, authInfo,
+	names, ids, tos, err := jiraGetTrans(ctx, svr, authInfo,
 		issueInfo[IssueinfoStrID])
 	if err != nil {
 		return nil, err
 	}
-	tranID, err := jiraChooseTran("", names, ids)
+	var tranName string
+	// the transition to the state, if any, instead of choosing one
+	if i := slices.Index(tos, issueInfo[IssueinfoStrToState]); i >= 0 &&
+		len(issueInfo[IssueinfoStrToState]) > 0 {
+		tranName = names[i]
+	}
+	tranID, err := jiraChooseTran(tranName, names, ids)
 	if err != nil {
 		return nil, err
 	}
-	err = jiraTranExec(svr, authInfo,
+	err = jiraTranExec(ctx, svr, authInfo,
 		issueInfo[IssueinfoStrID], tranID)
 	if err != nil {
 		return nil, err
@@ -759,51 +1025,141 @@ func JiraTransition(svr *svrs, authInfo eztools.AuthInfo,
 	return nil, err
 }
 
-func JiraLink(svr *svrs, authInfo eztools.AuthInfo,
+// jiraLinkType is a type of links between cases,
+// with descriptions of both directions, such as "is blocked by"
+type jiraLinkType struct {
+	name, inward, outward string
+}
+
+// jiraLinkTypeDef is the type of links by default in silent mode
+const jiraLinkTypeDef = "Blocks"
+
+// jiraLinkTypes gets types of links of the server
+func jiraLinkTypes(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo) (types []jiraLinkType, err error) {
+	const RestAPIStr = "rest/api/latest/issueLinkType"
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+RestAPIStr,
+		authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	typesI, _ := bodyMap["issueLinkTypes"].([]interface{})
+	for _, typeI := range typesI {
+		inf := chkNLoopStringMap(typeI, "", []string{IssueinfoStrName,
+			"inward", "outward"})
+		if inf == nil || len(inf[0]) < 1 {
+			continue
+		}
+		types = append(types, jiraLinkType{inf[0], inf[1], inf[2]})
+	}
+	if len(types) < 1 {
+		Log(true, false, "NO link types got")
+		return nil, eztools.ErrNoValidResults
+	}
+	return types, nil
+}
+
+// jiraChooseLink chooses a type of links, and its direction,
+// by a description of either direction, such as "is blocked by",
+// or a name for the outward one, such as "Blocks" for "blocks",
+// or jiraLinkTypeDef in silent mode.
+// Return: the type, and whether the other case is the inward one,
+// as "this case is blocked by the other"
+func jiraChooseLink(types []jiraLinkType, in string) (jiraLinkType,
+	bool, error) {
+	if len(in) < 1 && uiSilent {
+		in = jiraLinkTypeDef
+	}
+	if len(in) > 0 {
+		// exact matches first, as "Blocks" and "blocks"
+		for _, eq := range []func(string, string) bool{
+			func(a, b string) bool { return a == b }, strings.EqualFold} {
+			for _, tp := range types {
+				switch {
+				case eq(in, tp.name), eq(in, tp.outward):
+					return tp, false, nil
+				case eq(in, tp.inward):
+					return tp, true, nil
+				}
+			}
+		}
+		Log(true, false, "NO link type", in)
+		return jiraLinkType{}, false, eztools.ErrInvalidInput
+	}
+	// outward and inward ones of each type
+	choices := make([]string, 0, len(types)*2)
+	for _, tp := range types {
+		choices = append(choices, tp.outward+" ("+tp.name+")",
+			tp.inward+" ("+tp.name+")")
+	}
+	showStrln("This case ... the other one?")
+	i, _ := chooseStrings(choices)
+	if i == eztools.InvalidID {
+		return jiraLinkType{}, false, eztools.ErrInvalidInput
+	}
+	return types[i/2], i%2 == 1, nil
+}
+
+// jiraIsURL checks whether a link is a URL, for remote links
+func jiraIsURL(link string) bool {
+	return strings.HasPrefix(link, "http://") ||
+		strings.HasPrefix(link, "https://")
+}
+
+// jiraLinkRemote links a case to a URL, such as a change of Gerrit,
+// with a title in IssueinfoStrKey, or the URL
+func jiraLinkRemote(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	title := issueInfo[IssueinfoStrKey]
+	if len(title) < 1 {
+		title = issueInfo[IssueinfoStrLink]
+	}
+	jsonStr, err := json.Marshal(map[string]interface{}{
+		"object": map[string]string{
+			IssueinfoStrURL: issueInfo[IssueinfoStrLink],
+			"title":         title}})
+	if err != nil {
+		return nil, err
+	}
+	bodyMap, err := restMap(ctx, http.MethodPost, svr.URL+urlAPI4JR+
+		issueInfo[IssueinfoStrID]+"/remotelink",
+		authInfo, bytes.NewReader(jsonStr), svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	res := IssueInfos{IssueinfoStrID: issueInfo[IssueinfoStrID],
+		IssueinfoStrLink:    issueInfo[IssueinfoStrLink],
+		IssueinfoStrSummary: title}
+	if id, ok := bodyMap["id"].(float64); ok {
+		res[IssueinfoStrLinkID] = strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return res.ToSlc(), nil
+}
+
+// JiraLink links a case to another, or a URL as a remote link.
+// Type and direction of issue links are by IssueinfoStrKey,
+// as jiraChooseLink does.
+func JiraLink(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	// TODO: get these from server
-	linkChoices := []struct {
-		name, inward, outward string
-	}{
-		{inward: "is blocked by",
-			name:    "Blocks",
-			outward: "blocks"}}
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrLink]) < 1 ||
 		issueInfo[IssueinfoStrLink] ==
 			issueInfo[IssueinfoStrID] {
 		return nil, eztools.ErrInvalidInput
 	}
-	var linkType int
-	switch len(linkChoices) {
-	case 0:
-		return nil, eztools.ErrOutOfBound
-	case 1:
-		linkType = 0
-	default:
-		if uiSilent { // TODO: command params
-			noInteractionAllowed()
-			return nil, eztools.ErrInvalidInput
-		}
-		linkType = eztools.ChooseStringsWtIDs(
-			func() int {
-				return len(linkChoices)
-			},
-			func(i int) int {
-				return i
-			},
-			func(i int) string {
-				return linkChoices[i].name
-			}, "link type")
-		if linkType == eztools.InvalidID {
-			return nil, eztools.ErrInvalidInput
-		}
+	if jiraIsURL(issueInfo[IssueinfoStrLink]) {
+		return jiraLinkRemote(ctx, svr, authInfo, issueInfo)
+	}
+	types, err := jiraLinkTypes(ctx, svr, authInfo)
+	if err != nil {
+		return nil, err
+	}
+	linkType, inward, err := jiraChooseLink(types, issueInfo[IssueinfoStrKey])
+	if err != nil {
+		return nil, err
 	}
 	type ils struct {
-		Add struct {
-			Type map[string]string `json:"type"`
-			II   map[string]string `json:"inwardIssue"`
-		} `json:"add"`
+		Add map[string]map[string]string `json:"add"`
 	}
 	type jsonStru struct {
 		Update struct {
@@ -812,44 +1168,176 @@ func JiraLink(svr *svrs, authInfo eztools.AuthInfo,
 	}
 	var (
 		jsonStr []byte
-		err     error
 		jstru   jsonStru
-		il      ils
 	)
-	il.Add.II = make(map[string]string)
-	il.Add.Type = make(map[string]string)
-	//il.Add.Type["id"] = svr.Flds.LinkType[linkType].Value
-	il.Add.Type["inward"] = linkChoices[linkType].inward
-	il.Add.Type["name"] = linkChoices[linkType].name
-	il.Add.Type["outward"] = linkChoices[linkType].outward
-	il.Add.II[IssueinfoStrKey] = issueInfo[IssueinfoStrLink]
-	jstru.Update.IL = append(jstru.Update.IL, il)
+	issueKey, direction := "outwardIssue", linkType.outward
+	if inward {
+		issueKey, direction = "inwardIssue", linkType.inward
+	}
+	jstru.Update.IL = append(jstru.Update.IL, ils{
+		Add: map[string]map[string]string{
+			"type": {
+				"inward":  linkType.inward,
+				"name":    linkType.name,
+				"outward": linkType.outward},
+			issueKey: {IssueinfoStrKey: issueInfo[IssueinfoStrLink]}}})
 	jsonStr, err = json.Marshal(jstru)
 	if err != nil {
 		return nil, err
 	}
 	//eztools.ShowByteln(jsonStr)
-	_, err = restMap(http.MethodPut, svr.URL+urlAPI4JR+
+	if _, err = restMap(ctx, http.MethodPut, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID],
-		authInfo, bytes.NewReader(jsonStr), svr.Magic)
-	// TODO: parse result
-	return nil, err
+		authInfo, bytes.NewReader(jsonStr), svr.Magic); err != nil {
+		return nil, err
+	}
+	return IssueInfos{IssueinfoStrID: issueInfo[IssueinfoStrID],
+		IssueinfoStrLink:      issueInfo[IssueinfoStrLink],
+		IssueinfoStrType:      linkType.name,
+		IssueinfoStrDirection: direction}.ToSlc(), nil
+}
+
+// jiraParseLink parses an issue link of a case,
+// with the key, status and summary of the other case
+func jiraParseLink(id string, v interface{}) IssueInfos {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		LogTypeErr(v, "map[string]interface{}")
+		return nil
+	}
+	tp := chkNLoopStringMap(m["type"], "", []string{IssueinfoStrName,
+		"inward", "outward"})
+	if tp == nil {
+		return nil
+	}
+	res := IssueInfos{IssueinfoStrID: id, IssueinfoStrType: tp[0]}
+	res[IssueinfoStrLinkID], _ = m["id"].(string)
+	other, ok := m["outwardIssue"].(map[string]interface{})
+	res[IssueinfoStrDirection] = tp[2]
+	if !ok {
+		if other, ok = m["inwardIssue"].(map[string]interface{}); !ok {
+			Log(false, false, "NO cases linked in", m)
+			return nil
+		}
+		res[IssueinfoStrDirection] = tp[1]
+	}
+	res[IssueinfoStrLink], _ = other[IssueinfoStrKey].(string)
+	if fields, ok := other["fields"].(map[string]interface{}); ok {
+		for k, v := range jiraParse1Field(fields) {
+			switch k {
+			case IssueinfoStrState, IssueinfoStrSummary:
+				res[k] = v
+			}
+		}
+	}
+	return res
+}
+
+// JiraListLinks lists issue links and remote links of a case
+func JiraListLinks(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
+		issueInfo[IssueinfoStrID]+"?fields=issuelinks",
+		authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	var res IssueInfoSlc
+	fields, _ := bodyMap["fields"].(map[string]interface{})
+	links, _ := fields["issuelinks"].([]interface{})
+	for _, link := range links {
+		if inf := jiraParseLink(issueInfo[IssueinfoStrID], link); inf != nil {
+			res = append(res, inf)
+		}
+	}
+	body, err := restSth(ctx, http.MethodGet, svr.URL+urlAPI4JR+
+		issueInfo[IssueinfoStrID]+"/remotelink",
+		authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	remotes, _ := body.([]interface{})
+	for _, remote := range remotes {
+		m, ok := remote.(map[string]interface{})
+		if !ok {
+			LogTypeErr(remote, "map[string]interface{}")
+			continue
+		}
+		obj := chkNLoopStringMap(m["object"], "",
+			[]string{IssueinfoStrURL, "title"})
+		if obj == nil {
+			continue
+		}
+		inf := IssueInfos{IssueinfoStrID: issueInfo[IssueinfoStrID],
+			IssueinfoStrType: "remote", IssueinfoStrLink: obj[0],
+			IssueinfoStrSummary: obj[1]}
+		inf[IssueinfoStrDirection], _ = m["relationship"].(string)
+		if id, ok := m["id"].(float64); ok {
+			inf[IssueinfoStrLinkID] = strconv.FormatFloat(id, 'f', -1, 64)
+		}
+		res = append(res, inf)
+	}
+	return res, nil
+}
+
+// JiraDelLink removes links of a case to another or a URL,
+// in IssueinfoStrLink, or the one by IssueinfoStrLinkID,
+// as in results of JiraListLinks.
+func JiraDelLink(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrLink]) < 1 &&
+		len(issueInfo[IssueinfoStrLinkID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	links, err := JiraListLinks(ctx, svr, authInfo, issueInfo)
+	if err != nil {
+		return nil, err
+	}
+	var res IssueInfoSlc
+	for _, link := range links {
+		if id := issueInfo[IssueinfoStrLinkID]; len(id) > 0 {
+			if link[IssueinfoStrLinkID] != id {
+				continue
+			}
+		} else if link[IssueinfoStrLink] != issueInfo[IssueinfoStrLink] {
+			continue
+		}
+		RestAPIStr := "rest/api/latest/issueLink/" + link[IssueinfoStrLinkID]
+		if link[IssueinfoStrType] == "remote" {
+			RestAPIStr = urlAPI4JR + issueInfo[IssueinfoStrID] +
+				"/remotelink/" + link[IssueinfoStrLinkID]
+		}
+		if _, err = restSth(ctx, http.MethodDelete, svr.URL+RestAPIStr,
+			authInfo, nil, svr.Magic); err != nil {
+			return res, err
+		}
+		res = append(res, link)
+	}
+	if len(res) < 1 {
+		Log(true, false, "NO links to", issueInfo[IssueinfoStrLink],
+			issueInfo[IssueinfoStrLinkID])
+		return nil, eztools.ErrNoValidResults
+	}
+	return res, nil
 }
 
-func JiraModComment(svr *svrs, authInfo eztools.AuthInfo,
+func JiraModComment(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrComments]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
 	if len(issueInfo[IssueinfoStrKey]) < 1 {
-		inf, err := JiraComments(svr, authInfo, issueInfo)
+		inf, err := JiraComments(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			return nil, err
 		}
 		/*var a []map[string]string
 		a = ((interface{})(inf)).([]map[string]string)*/
-		i := eztools.ChooseMaps(inf.ToMapSlc(), " (",
+		i := chooseMaps(inf.ToMapSlc(), " (",
 			IssueinfoStrComments, IssueinfoStrID)
 		if i == eztools.InvalidID {
 			return nil, eztools.ErrInvalidInput
@@ -867,7 +1355,7 @@ func JiraModComment(svr *svrs, authInfo eztools.AuthInfo,
 	if err != nil {
 		return nil, err
 	}
-	_, err = restMap(http.MethodPut,
+	_, err = restMap(ctx, http.MethodPut,
 		svr.URL+urlAPI4JR+issueInfo[IssueinfoStrID]+"/comment/"+
 			issueInfo[IssueinfoStrKey], authInfo,
 		bytes.NewReader(jsonStr), svr.Magic)
@@ -875,44 +1363,44 @@ func JiraModComment(svr *svrs, authInfo eztools.AuthInfo,
 	return nil, err
 }
 
-func JiraDelComment(svr *svrs, authInfo eztools.AuthInfo,
+func JiraDelComment(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	// TODO: select key
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrKey]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	_, err := restMap(http.MethodDelete, svr.URL+urlAPI4JR+
+	_, err := restMap(ctx, http.MethodDelete, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/comment/"+issueInfo[IssueinfoStrKey],
 		authInfo, nil, svr.Magic)
 	// TODO: parse result
 	return nil, err
 }
 
-func JiraAddComment(svr *svrs, authInfo eztools.AuthInfo,
+func JiraAddComment(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrComments]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	inf, err := jiraAddComment1(svr, authInfo, issueInfo)
+	inf, err := jiraAddComment1(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
 	return inf.ToSlc(), err
 }
 
-func jiraPostSth(svr *svrs, urlSuffix string, authInfo eztools.AuthInfo,
-	stru interface{}, id string) (bodyMap map[string]interface{}, err error) {
+func jiraPostSth(ctx context.Context, svr *svrs, urlSuffix string,
+	authInfo eztools.AuthInfo, stru interface{}, id string) (bodyMap map[string]interface{}, err error) {
 	var jsonStr []byte
 	jsonStr, err = json.Marshal(stru)
 	if err != nil {
 		return
 	}
 	if eztools.Debugging && eztools.Verbose > 0 {
-		eztools.ShowByteln(jsonStr)
+		showByteln(jsonStr)
 	}
-	bodyMap, err = restMap(http.MethodPost, svr.URL+urlAPI4JR+
+	bodyMap, err = restMap(ctx, http.MethodPost, svr.URL+urlAPI4JR+
 		id+"/"+urlSuffix,
 		authInfo, bytes.NewReader(jsonStr), svr.Magic)
 	if err != nil {
@@ -921,7 +1409,7 @@ func jiraPostSth(svr *svrs, urlSuffix string, authInfo eztools.AuthInfo,
 	return
 }
 
-func jiraAddComment1(svr *svrs, authInfo eztools.AuthInfo,
+func jiraAddComment1(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfos, error) {
 	type comment1 struct {
 		Comment1 string `json:"body"`
@@ -930,19 +1418,24 @@ func jiraAddComment1(svr *svrs, authInfo eztools.AuthInfo,
 		cmt comment1
 	)
 	cmt.Comment1 = issueInfo[IssueinfoStrComments]
-	body, err := jiraPostSth(svr, "comment", authInfo, cmt, issueInfo[IssueinfoStrID])
+	body, err := jiraPostSth(ctx, svr, "comment", authInfo, cmt,
+		issueInfo[IssueinfoStrID])
 	if err != nil {
 		return nil, err
 	}
+	if body == nil && dryRun {
+		// nothing replied for a request not sent
+		return IssueInfos{IssueinfoStrComments: cmt.Comment1}, nil
+	}
 	return jiraParse1Cmt(body)
 }
 
-func JiraComments(svr *svrs, authInfo eztools.AuthInfo,
+func JiraComments(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := restMap(http.MethodGet, svr.URL+urlAPI4JR+
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/comment",
 		authInfo, nil, svr.Magic)
 	if err != nil {
@@ -951,12 +1444,12 @@ func JiraComments(svr *svrs, authInfo eztools.AuthInfo,
 	return jiraParseCmts(bodyMap)
 }
 
-func jiraDetailExec(svr *svrs, authInfo eztools.AuthInfo,
+func jiraDetailExec(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (map[string]interface{}, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := restMap(http.MethodGet, svr.URL+urlAPI4JR+
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID], authInfo, nil, svr.Magic)
 	if err != nil {
 		return nil, err
@@ -964,9 +1457,9 @@ func jiraDetailExec(svr *svrs, authInfo eztools.AuthInfo,
 	return bodyMap, err
 }
 
-func JiraDetail(svr *svrs, authInfo eztools.AuthInfo,
+func JiraDetail(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	bodyMap, err := jiraDetailExec(svr, authInfo, issueInfo)
+	bodyMap, err := jiraDetailExec(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -978,7 +1471,7 @@ func JiraDetail(svr *svrs, authInfo eztools.AuthInfo,
 	//return jiraParseIssues(svr, bodyMap), err
 }
 
-func JiraMyOpen(svr *svrs, authInfo eztools.AuthInfo,
+func JiraMyOpen(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	const RestAPIStr = "rest/api/latest/search?jql="
 	var states string
@@ -989,7 +1482,7 @@ func JiraMyOpen(svr *svrs, authInfo eztools.AuthInfo,
 			}
 		}
 	}
-	bodyMap, err := restMap(http.MethodGet, svr.URL+RestAPIStr+
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+RestAPIStr+
 		url.QueryEscape("assignee="+authInfo.User+states),
 		authInfo, nil, svr.Magic)
 	if err != nil {
@@ -998,12 +1491,312 @@ func JiraMyOpen(svr *svrs, authInfo eztools.AuthInfo,
 	return jiraParseIssues(bodyMap), err
 }
 
-func JiraWatcherList(svr *svrs, authInfo eztools.AuthInfo,
+// jiraSearchPage is the max number of cases in each page of searches
+const jiraSearchPage = 100
+
+// JiraSearch lists cases by JQL in IssueinfoStrKey, of all pages,
+// with fields separated by commas in IssueinfoStrLink, if any,
+// besides default ones
+func JiraSearch(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrKey]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	var fields []string
+	for _, field := range strings.Split(issueInfo[IssueinfoStrLink], ",") {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	query := url.Values{"jql": {issueInfo[IssueinfoStrKey]},
+		"maxResults": {strconv.Itoa(jiraSearchPage)}}
+	if len(fields) > 0 {
+		// with those parsed by jiraParse1Field
+		query.Set("fields", strings.Join(append([]string{
+			IssueinfoStrSummary, IssueinfoStrState, IssueinfoStrProj,
+			IssueinfoStrAssignee, IssueinfoStrDesc}, fields...), ","))
+	}
+	const RestAPIStr = "rest/api/latest/search?"
+	var issues IssueInfoSlc
+	for startAt := 0; ; {
+		query.Set("startAt", strconv.Itoa(startAt))
+		bodyMap, err := restMap(ctx, http.MethodGet,
+			svr.URL+RestAPIStr+query.Encode(), authInfo, nil, svr.Magic)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, jiraParseIssues(bodyMap, fields...)...)
+		page, _ := bodyMap["issues"].([]interface{})
+		total, _ := bodyMap["total"].(float64)
+		startAt += len(page)
+		if len(page) < 1 || startAt >= int(total) {
+			break
+		}
+		if eztools.Debugging && eztools.Verbose > 0 {
+			Log(false, false, "got", startAt, "of", total, "cases")
+		}
+	}
+	return issues, nil
+}
+
+// jiraCreatePairs gets -s as NAME=VALUE, for fields to create a case with
+func jiraCreatePairs(issueInfo IssueInfos) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range fieldsOf(issueInfo) {
+		name, val, ok := strings.Cut(pair, "=")
+		if name = strings.TrimSpace(name); !ok || len(name) < 1 {
+			Log(true, false, "fields must be NAME=VALUE, not", pair)
+			return nil, eztools.ErrInvalidInput
+		}
+		pairs[name] = val
+	}
+	return pairs, nil
+}
+
+// jiraFldVal makes a value of a field in JSON by its schema,
+// choosing by IDs, values or names of allowedValues, if any,
+// with multiple values separated by commas for arrays
+func jiraFldVal(fld mustFlds, val string) (interface{}, error) {
+	one := func(val string, tp string) (interface{}, error) {
+		val = strings.TrimSpace(val)
+		if len(fld.choices) > 0 {
+			for _, choice := range fld.choices {
+				if choice[IssueinfoStrID] == val ||
+					strings.EqualFold(choice[IssueinfoStrVal], val) {
+					return map[string]string{"id": choice[IssueinfoStrID]}, nil
+				}
+			}
+			Log(true, false, val, "NOT allowed for", fld.key)
+			return nil, eztools.ErrInvalidInput
+		}
+		switch tp {
+		case "number":
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				Log(true, false, fld.key, "NOT a number:", val)
+				return nil, eztools.ErrInvalidInput
+			}
+			return f, nil
+		case "user", "priority", "option", "version", "component":
+			return map[string]string{IssueinfoStrName: val}, nil
+		}
+		return val, nil
+	}
+	if fld.tp != "array" {
+		return one(val, fld.tp)
+	}
+	var ret []interface{}
+	for _, v := range strings.Split(val, ",") {
+		if len(strings.TrimSpace(v)) < 1 {
+			continue
+		}
+		v1, err := one(v, fld.items)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, v1)
+	}
+	return ret, nil
+}
+
+// jiraCreatePrompt asks for the value of a field,
+// among choices, if any
+func jiraCreatePrompt(fld mustFlds) string {
+	prompt := fld.name + " (" + fld.key + ")"
+	if len(fld.choices) < 1 {
+		if fld.tp == "array" {
+			prompt += ", separated by commas"
+		}
+		return promptStr(prompt)
+	}
+	choices := make([]string, len(fld.choices))
+	for i, choice := range fld.choices {
+		choices[i] = choice[IssueinfoStrVal]
+	}
+	showStrln(prompt)
+	i, _ := chooseStrings(choices)
+	if i == eztools.InvalidID {
+		return ""
+	}
+	return fld.choices[i][IssueinfoStrID]
+}
+
+// jiraCreateMeta gets values of createmeta of a project,
+// for issue types, or fields of one
+func jiraCreateMeta(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, proj, tpID string) ([]interface{}, error) {
+	RestAPIStr := "createmeta/" + url.PathEscape(proj) + "/issuetypes"
+	if len(tpID) > 0 {
+		RestAPIStr += "/" + url.PathEscape(tpID)
+	}
+	bodyMap, err := restMap(ctx, http.MethodGet,
+		svr.URL+urlAPI4JR+RestAPIStr+"?maxResults="+
+			strconv.Itoa(jiraSearchPage), authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	vals, _ := bodyMap["values"].([]interface{})
+	if len(vals) < 1 {
+		Log(true, false, "NO metadata to create in", proj, tpID)
+		return nil, eztools.ErrNoValidResults
+	}
+	return vals, nil
+}
+
+// jiraCreateTypes gets names and IDs of issue types of a project
+func jiraCreateTypes(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, proj string) (names, ids []string,
+	err error) {
+	vals, err := jiraCreateMeta(ctx, svr, authInfo, proj, "")
+	for _, val := range vals {
+		inf := chkNLoopStringMap(val, "", []string{IssueinfoStrName, "id"})
+		if inf == nil || len(inf[1]) < 1 {
+			continue
+		}
+		names = append(names, inf[0])
+		ids = append(ids, inf[1])
+	}
+	return
+}
+
+// jiraCreateFlds gets fields of an issue type of a project
+func jiraCreateFlds(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, proj, tpID string) ([]mustFlds, error) {
+	vals, err := jiraCreateMeta(ctx, svr, authInfo, proj, tpID)
+	if err != nil {
+		return nil, err
+	}
+	fieldMap := make(map[string]interface{})
+	for _, val := range vals {
+		valMap, ok := val.(map[string]interface{})
+		if !ok {
+			LogTypeErr(val, "map[string]interface{}")
+			continue
+		}
+		if key, ok := valMap["fieldId"].(string); ok {
+			fieldMap[key] = valMap
+		}
+	}
+	return jiraParseFlds(fieldMap), nil
+}
+
+// JiraCreate creates a case in the project, or the one saved,
+// with fields from -s as NAME=VALUE, or params of the same keys,
+// and prompts for required ones.
+// NAME is the key or name of a field, such as customfield_10300,
+// or issuetype for the issue type, which is chosen if not provided.
+// The project is saved as the prefix of IDs.
+func JiraCreate(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	proj := issueInfo[IssueinfoStrProj]
+	if len(proj) < 1 {
+		proj = svr.Proj
+	}
+	if len(proj) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	pairs, err := jiraCreatePairs(issueInfo)
+	if err != nil {
+		return nil, err
+	}
+	// lookup gets and removes the value of a field from pairs,
+	// or gets it from params of its key
+	lookup := func(key, name string) (string, bool) {
+		for k, v := range pairs {
+			if k == key || (len(name) > 0 && strings.EqualFold(k, name)) {
+				delete(pairs, k)
+				return v, true
+			}
+		}
+		v := issueInfo[key]
+		return v, len(v) > 0
+	}
+	tpNames, tpIDs, err := jiraCreateTypes(ctx, svr, authInfo, proj)
+	if err != nil {
+		return nil, err
+	}
+	tp := -1
+	if tpIn, ok := lookup("issuetype", ""); ok {
+		for i := range tpIDs {
+			if tpIDs[i] == tpIn || strings.EqualFold(tpNames[i], tpIn) {
+				tp = i
+				break
+			}
+		}
+	}
+	if tp < 0 {
+		if uiSilent {
+			Log(true, false, "issue type must be one of", tpNames)
+			return nil, eztools.ErrInvalidInput
+		}
+		showStrln("Which issue type?")
+		if tp, _ = chooseStrings(tpNames); tp == eztools.InvalidID {
+			return nil, eztools.ErrInvalidInput
+		}
+	}
+	flds, err := jiraCreateFlds(ctx, svr, authInfo, proj, tpIDs[tp])
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{
+		IssueinfoStrProj: map[string]string{IssueinfoStrKey: proj},
+		"issuetype":      map[string]string{"id": tpIDs[tp]}}
+	var missing []string
+	for _, fld := range flds {
+		if _, ok := fields[fld.key]; ok {
+			continue
+		}
+		val, ok := lookup(fld.key, fld.name)
+		if !ok && fld.required && !fld.hasDef {
+			if uiSilent {
+				missing = append(missing, fld.key+"("+fld.name+")")
+				continue
+			}
+			val, ok = jiraCreatePrompt(fld), true
+		}
+		if !ok || len(val) < 1 {
+			continue
+		}
+		if fields[fld.key], err = jiraFldVal(fld, val); err != nil {
+			return nil, err
+		}
+	}
+	if len(missing) > 0 {
+		noInteractionAllowed()
+		Log(true, false, "fields required:", strings.Join(missing, ", "))
+		return nil, eztools.ErrInvalidInput
+	}
+	for k := range pairs {
+		Log(true, false, "NO field", k, "to create", tpNames[tp])
+		return nil, eztools.ErrInvalidInput
+	}
+	jsonStr, err := json.Marshal(map[string]interface{}{"fields": fields})
+	if err != nil {
+		return nil, err
+	}
+	bodyMap, err := restMap(ctx, http.MethodPost,
+		svr.URL+strings.TrimSuffix(urlAPI4JR, "/"),
+		authInfo, bytes.NewReader(jsonStr), svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	res := IssueInfos{IssueinfoStrProj: proj}
+	if summary, ok := fields[IssueinfoStrSummary].(string); ok {
+		res[IssueinfoStrSummary] = summary
+	}
+	if key, ok := bodyMap[IssueinfoStrKey].(string); ok {
+		res[IssueinfoStrID] = key
+	}
+	saveProj(svr, proj)
+	return res.ToSlc(), nil
+}
+
+func JiraWatcherList(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := restMap(http.MethodGet, svr.URL+urlAPI4JR+
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/watchers", authInfo, nil, svr.Magic)
 	if err != nil {
 		return nil, err
@@ -1031,12 +1824,12 @@ func JiraWatcherList(svr *svrs, authInfo eztools.AuthInfo,
 	return res, nil
 }
 
-func JiraWatcherCheck(svr *svrs, authInfo eztools.AuthInfo,
+func JiraWatcherCheck(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	bodyMap, err := restMap(http.MethodGet, svr.URL+urlAPI4JR+
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/watchers", authInfo, nil, svr.Magic)
 	if err != nil {
 		return nil, err
@@ -1053,23 +1846,23 @@ func JiraWatcherCheck(svr *svrs, authInfo eztools.AuthInfo,
 	return res, nil
 }
 
-func JiraWatcherAdd(svr *svrs, authInfo eztools.AuthInfo,
+func JiraWatcherAdd(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	_, err := restMap(http.MethodPost, svr.URL+urlAPI4JR+
+	_, err := restMap(ctx, http.MethodPost, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/watchers",
 		authInfo, strings.NewReader("\""+cfg.User+"\""), svr.Magic)
 	return nil, err
 }
 
-func JiraWatcherDel(svr *svrs, authInfo eztools.AuthInfo,
+func JiraWatcherDel(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	_, err := restMap(http.MethodDelete, svr.URL+urlAPI4JR+
+	_, err := restMap(ctx, http.MethodDelete, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/watchers?username="+cfg.User,
 		authInfo, nil, svr.Magic)
 	if err != nil {
@@ -1126,13 +1919,13 @@ func jiraParseAttachments(bodyMap map[string]interface{}) (issues IssueInfoSlc)
 	return
 }
 
-func JiraAddFile(svr *svrs, authInfo eztools.AuthInfo,
+func JiraAddFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrID]) < 1 ||
 		len(issueInfo[IssueinfoStrFile]) < 1 {
 		return nil, eztools.ErrInvalidInput
 	}
-	_, err := restFile(http.MethodPost, svr.URL+urlAPI4JR+
+	_, err := restFile(ctx, http.MethodPost, svr.URL+urlAPI4JR+
 		issueInfo[IssueinfoStrID]+"/attachments",
 		authInfo, "file", issueInfo[IssueinfoStrFile],
 		map[string]string{"X-Atlassian-Token": "nocheck"}, svr.Magic)
@@ -1142,18 +1935,18 @@ func JiraAddFile(svr *svrs, authInfo eztools.AuthInfo,
 	return nil, err
 }
 
-func JiraListFile(svr *svrs, authInfo eztools.AuthInfo,
+func JiraListFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
-	bodyMap, err := jiraDetailExec(svr, authInfo, issueInfo)
+	bodyMap, err := jiraDetailExec(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
 	return jiraParseAttachments(bodyMap), nil
 }
 
-func jiraGetFileInf(svr *svrs, authInfo eztools.AuthInfo,
+func jiraGetFileInf(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfos, error) {
-	inf, err := JiraListFile(svr, authInfo, issueInfo)
+	inf, err := JiraListFile(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return issueInfo, err
 	}
@@ -1169,7 +1962,7 @@ func jiraGetFileInf(svr *svrs, authInfo eztools.AuthInfo,
 			}
 		}
 	} else {
-		i := eztools.ChooseMaps(inf.ToMapSlc(), " (",
+		i := chooseMaps(inf.ToMapSlc(), " (",
 			IssueinfoStrFile, IssueinfoStrSize)
 		if i == eztools.InvalidID {
 			return issueInfo, eztools.ErrInvalidInput
@@ -1181,7 +1974,7 @@ func jiraGetFileInf(svr *svrs, authInfo eztools.AuthInfo,
 	return issueInfo, nil
 }
 
-func JiraGetFile(svr *svrs, authInfo eztools.AuthInfo,
+func JiraGetFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	/*isDir := false
 	fi, err := os.Stat(issueInfo[IssueinfoStrFile])
@@ -1192,14 +1985,15 @@ func JiraGetFile(svr *svrs, authInfo eztools.AuthInfo,
 		}
 		isDir = true
 	}*/
-	issueInfo, err := jiraGetFileInf(svr, authInfo, issueInfo)
+	issueInfo, err := jiraGetFileInf(ctx, svr, authInfo, issueInfo)
 	if err != nil {
 		return nil, err
 	}
 	if len(issueInfo[IssueinfoStrLink]) < 1 {
 		return nil, eztools.ErrNoValidResults
 	}
-	_, err = restAttachment(http.MethodGet, issueInfo[IssueinfoStrLink], authInfo, nil, svr.Magic)
+	_, err = restAttachment(ctx, http.MethodGet, issueInfo[IssueinfoStrLink],
+		authInfo, nil, svr.Magic)
 	/*if len(issueInfo[IssueinfoStrFile]) < 1 || isDir {
 		issueInfo[IssueinfoStrFile] = filepath.Join(issueInfo[IssueinfoStrFile],
 			issueInfo[IssueinfoStrName])
@@ -1225,22 +2019,246 @@ func JiraGetFile(svr *svrs, authInfo eztools.AuthInfo,
 	return issueInfo.ToSlc(), err
 }
 
-func JiraDelFile(svr *svrs, authInfo eztools.AuthInfo,
+func JiraDelFile(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
 	issueInfo IssueInfos) (IssueInfoSlc, error) {
 	if len(issueInfo[IssueinfoStrKey]) < 1 {
 		if len(issueInfo[IssueinfoStrID]) < 1 {
 			return nil, eztools.ErrInvalidInput
 		}
 		var err error
-		issueInfo, err = jiraGetFileInf(svr, authInfo, issueInfo)
+		issueInfo, err = jiraGetFileInf(ctx, svr, authInfo, issueInfo)
 		if err != nil {
 			return nil, err
 		}
 	}
 	// https://developer.atlassian.com/static/rest/jira/5.1.6.html#id127779
 	const RestAPIStr = "rest/api/latest/attachment/"
-	_, err := restSth(http.MethodDelete,
+	_, err := restSth(ctx, http.MethodDelete,
 		svr.URL+RestAPIStr+issueInfo[IssueinfoStrKey],
 		authInfo, nil, svr.Magic)
 	return nil, err
 }
+
+// jiraBackend is the backend of JIRA servers
+type jiraBackend struct{}
+
+func init() {
+	regBackend(jiraBackend{})
+}
+
+// Type is CategoryJira
+func (jiraBackend) Type() string {
+	return CategoryJira
+}
+
+// PassType is PassBasic
+func (jiraBackend) PassType() string {
+	return PassBasic
+}
+
+// PassHint is about Atlassian Cloud and Jira Data Center
+func (jiraBackend) PassHint() string {
+	return "Use " + PassBasic + " with an API token and the email as user " +
+		"for Atlassian Cloud, or " + PassBearer + " with a personal " +
+		"access token for Jira Data Center."
+}
+
+// Magic is not needed
+func (jiraBackend) Magic() string {
+	return ""
+}
+
+// jiraActsAfter are specific actions following shared ones of cases,
+// in order of choices
+var jiraActsAfter = map[string][]action2Func{
+	"add a comment to a case": {
+		{"delete a comment from a case", JiraDelComment},
+		{"change a comment from a case", JiraModComment}},
+	"list watchers of a case": {
+		{"check whether watching a case", JiraWatcherCheck}},
+	"get a file to a case": {
+		{"remove a file attached to a case", JiraDelFile}},
+}
+
+// Actions are shared ones of cases, with specific ones among them
+func (b jiraBackend) Actions() (ret []action2Func) {
+	for _, act := range caseActsOf(b) {
+		ret = append(ret, act)
+		ret = append(ret, jiraActsAfter[act.n]...)
+	}
+	return append(ret,
+		// the two are to be hidden from choices,
+		// if lack of configuration of Tst*
+		action2Func{"close a case with default design as steps", JiraCloseDef},
+		action2Func{"close a case with general requirement as steps", JiraCloseGen},
+		action2Func{"create a case", JiraCreate},
+		action2Func{"edit fields of a case", JiraEditFlds},
+		action2Func{"search cases by JQL", JiraSearch},
+		action2Func{"list links of a case", JiraListLinks},
+		action2Func{"remove a link", JiraDelLink},
+		action2Func{"add a worklog to a case", JiraAddWorklog},
+		action2Func{"list worklogs of a case", JiraWorklogs},
+		action2Func{"change a worklog of a case", JiraModWorklog},
+		action2Func{"delete a worklog from a case", JiraDelWorklog},
+		action2Func{"start work on a case", JiraStartWork},
+		action2Func{"stop work on a case", JiraStopWork},
+		action2Func{"report time logged today and this week", JiraReportWork})
+}
+
+// hideAct hides closing with test steps, if lack of configuration of Tst*
+func (jiraBackend) hideAct(svr svrs, action string) bool {
+	switch action {
+	case "close a case with default design as steps",
+		"close a case with general requirement as steps":
+		return len(svr.Flds.TstExp+svr.Flds.TstPre+svr.Flds.TstStep) < 1
+	}
+	return false
+}
+
+// ListMine lists my open cases with projects and summaries
+func (jiraBackend) ListMine() (actionFunc, string, string) {
+	return JiraMyOpen, IssueinfoStrProj, IssueinfoStrSummary
+}
+
+// Input asks for input of shared actions of cases, and specific ones
+func (jiraBackend) Input(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, action string, inf IssueInfos) bool {
+	if action == "link a case to another" {
+		// of any types of links, chosen afterwards
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+			"the other case (not indexes above, if any), or a URL")
+		return false
+	}
+	if inputIssueInfo4Case(ctx, svr, authInfo, action, inf) {
+		return true
+	}
+	switch action {
+	case "close a case to resolved from any known statuses":
+		useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+			"test step for closure")
+	case "move status of a case":
+		useInputOrPrompt(svr, inf, IssueinfoStrComments)
+	case "close a case with default design as steps",
+		"close a case with general requirement as steps":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPrompt(svr, inf, IssueinfoStrComments)
+	case "check whether watching a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "remove a file attached to a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrKey, "file ID")
+	case "change a comment from a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrKey, "comment ID")
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrComments, "comment body")
+	case "delete a comment from a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		useInputOrPromptStr(svr, inf,
+			IssueinfoStrKey, "comment ID")
+	case "list links of a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "remove a link":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		if len(inf[IssueinfoStrLinkID]) < 1 && useInputOrPromptStr(svr,
+			inf, IssueinfoStrLink, "linked ID or URL") {
+			return true
+		}
+	case "list worklogs of a case", "start work on a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "add a worklog to a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) ||
+			useInputOrPromptStr(svr, inf, IssueinfoStrSize,
+				"time spent, such as 1h 30m") {
+			return true
+		}
+		if !uiSilent {
+			useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+				"started at, as "+jiraTmFmtIn+", or now if empty")
+			useInputOrPromptStr(svr, inf, IssueinfoStrComments, "comment")
+		}
+	case "change a worklog of a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) ||
+			useInputOrPromptStr(svr, inf, IssueinfoStrKey, "worklog ID") {
+			return true
+		}
+		if !uiSilent {
+			useInputOrPromptStr(svr, inf, IssueinfoStrSize,
+				"time spent, such as 1h 30m")
+			useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+				"started at, as "+jiraTmFmtIn)
+			useInputOrPromptStr(svr, inf, IssueinfoStrComments, "comment")
+		}
+	case "delete a worklog from a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) ||
+			useInputOrPromptStr(svr, inf, IssueinfoStrKey, "worklog ID") {
+			return true
+		}
+	case "stop work on a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+		if !uiSilent {
+			useInputOrPromptStr(svr, inf, IssueinfoStrComments, "comment")
+		}
+	case "edit fields of a case":
+		if useInputOrPrompt4ID(ctx, svr, authInfo, inf) {
+			return true
+		}
+	case "create a case":
+		if len(svr.Proj) < 1 && useInputOrPrompt(svr, inf, IssueinfoStrProj) {
+			return true
+		}
+	case "search cases by JQL":
+		if useInputOrPromptStr(svr, inf, IssueinfoStrKey, "JQL") {
+			return true
+		}
+		if !uiSilent {
+			useInputOrPromptStr(svr, inf, IssueinfoStrLink,
+				"more fields, separated by commas")
+		}
+	}
+	return false
+}
+
+// parseID parses JIRA number format
+func (jiraBackend) parseID(svr *svrs, id string) (string, bool, bool) {
+	base, _, changes, ok := parseTypicalJiraNum(svr, id)
+	return base, changes, ok
+}
+
+// changeID takes abbreviated input of JIRA number format
+func (jiraBackend) changeID(svr *svrs, s, base string, smart, changes bool) (string, bool) {
+	return changeTypicalJiraNum(svr, s, base, smart, changes)
+}
+
+// cases are Jira* functions
+func (jiraBackend) cases() caseFuncs {
+	return caseFuncs{myOpen: JiraMyOpen, detail: JiraDetail,
+		comments: JiraComments, addComment: JiraAddComment,
+		transition: JiraTransition, reject: JiraReject, close: JiraClose,
+		addFile: JiraAddFile, listFile: JiraListFile, getFile: JiraGetFile,
+		watcherList: JiraWatcherList, watcherAdd: JiraWatcherAdd,
+		watcherDel: JiraWatcherDel, transfer: JiraTransfer, link: JiraLink}
+}
@@ -2,8 +2,10 @@
 package main
 
 import (
+	"net/http"
 	"testing"
 
+	"gitee.com/bon-ami/eztools/v6"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -103,3 +105,393 @@ func TestJiraCloseDef(t *testing.T) {
 func TestJiraCloseGen(t *testing.T) {
 	JiraTests(t, "close a case with general requirement as steps", false)
 }
+
+const (
+	jiraFakeIssue = "/rest/api/latest/issue/X-1"
+	// jiraFakeSearch is the query of searches, but startAt
+	jiraFakeSearch = "fields=summary%2Cstatus%2Cproject%2Cassignee%2C" +
+		"description%2Ccustomfield_10300%2Ccustomfield_10400&" +
+		"jql=project+%3D+X+AND+status+%3D+Open&maxResults=100&startAt="
+)
+
+var (
+	jiraFakeLinkTypes = fakeReq{req: "GET /rest/api/latest/issueLinkType",
+		resp: "linktypes.json"}
+	jiraFakeWorklogs = fakeReq{req: "GET " + jiraFakeIssue + "/worklog",
+		resp: "worklogs.json"}
+	// jiraFakeLinks are requests to list links of X-1
+	jiraFakeLinks = []fakeReq{
+		{req: "GET " + jiraFakeIssue + "?fields=issuelinks", resp: "links.json"},
+		{req: "GET " + jiraFakeIssue + "/remotelink", resp: "remotelinks.json"}}
+)
+
+var jiraFakeCases = []fakeCase{
+	{action: "transfer a case to someone",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrSummary: "alice"},
+		reqs: []fakeReq{
+			{req: "PUT " + jiraFakeIssue + ` {"set":{"name":"alice"}}`}}},
+	{action: "transfer a case to someone",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrSummary: "alice",
+			IssueinfoStrComments: "UI"},
+		reqs: []fakeReq{
+			{req: "PUT " + jiraFakeIssue + ` "components":[{"set":[{"name":"UI"}]}]`}}},
+	// no transition can be chosen without a target state in silent mode
+	{action: "move status of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue + "/transitions", resp: "transitions.json"}},
+		err: eztools.ErrInvalidInput},
+	{action: "move status of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrToState: "Resolved"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue + "/transitions", resp: "transitions.json"},
+			{req: "POST " + jiraFakeIssue + `/transitions {"transition":{"id":"31"}}`}}},
+	{action: "show details of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue, resp: "issue.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrSummary: "Crash on start", IssueinfoStrState: "Open",
+			IssueinfoStrProj: "X", IssueinfoStrDispname: "Test Er",
+			IssueinfoStrAssignee: "tester"})},
+	{action: "show details of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-9"},
+		reqs: []fakeReq{
+			{req: "GET /rest/api/latest/issue/X-9", code: http.StatusNotFound,
+				resp: "error.json"}},
+		err: eztools.ErrNoValidResults},
+	{action: "show details of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1,,2"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue, resp: "issue.json"},
+			{req: "GET /rest/api/latest/issue/X-2", resp: "issue.json"}},
+		chk: fakeChk(2, nil)},
+	{action: "list comments of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue + "/comment", resp: "comments.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "10010",
+			IssueinfoStrComments: "Reproduced.", IssueinfoStrKey: "tester"})},
+	{action: "add a comment to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrComments: "Looking into it."},
+		reqs: []fakeReq{
+			{req: "POST " + jiraFakeIssue + `/comment {"body":"Looking into it."}`,
+				resp: "comment.json", code: http.StatusCreated}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "10012"})},
+	{action: "delete a comment from a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "10010"},
+		reqs: []fakeReq{
+			{req: "DELETE " + jiraFakeIssue + "/comment/10010"}}},
+	{action: "change a comment from a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "10012",
+			IssueinfoStrComments: "Not reproducible."},
+		reqs: []fakeReq{
+			{req: "PUT " + jiraFakeIssue + `/comment/10012 {"body":"Not reproducible."}`,
+				resp: "comment.json"}}},
+	{action: "list my open cases",
+		reqs: []fakeReq{
+			{req: "GET /rest/api/latest/search?jql=" +
+				"assignee%3Dtester%26status%21%3DClosed", resp: "search.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrState: "Open"})},
+	{action: "search cases by JQL",
+		inf: IssueInfos{IssueinfoStrKey: "project = X AND status = Open",
+			IssueinfoStrLink: "customfield_10300, customfield_10400"},
+		reqs: []fakeReq{
+			{req: "GET /rest/api/latest/search?" + jiraFakeSearch + "0",
+				resp: "search_page1.json"},
+			{req: "GET /rest/api/latest/search?" + jiraFakeSearch + "2",
+				resp: "search_page2.json"}},
+		chk: func(t *testing.T, res IssueInfoSlc) {
+			fakeChk(3, IssueInfos{IssueinfoStrID: "X-1",
+				IssueinfoStrState: "Open", "customfield_10300": "High",
+				"customfield_10400": "5"})(t, res)
+			if _, ok := res[1]["customfield_10300"]; ok {
+				t.Error("no values expected for null, got", res[1])
+			}
+			if res[2]["customfield_10300"] != "Low,Web" {
+				t.Error("values of an array expected, got", res[2])
+			}
+		}},
+	{action: "edit fields of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrFields: "Fix " +
+			"Version/s=2.3\nLabels+=regression\nlabels-=old\n" +
+			"customfield_10901=high"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue + "/editmeta", resp: "editmeta.json"},
+			{req: "PUT " + jiraFakeIssue + ` {"update":{` +
+				`"customfield_10901":[{"set":{"id":"20"}}],` +
+				`"fixVersions":[{"set":[{"id":"10010"}]}],` +
+				`"labels":[{"add":"regression"},{"remove":"old"}]}}`}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "X-1",
+			"labels": "add regression; remove old"})},
+	{action: "create a case",
+		inf: IssueInfos{IssueinfoStrFields: "issuetype=bug\n" +
+			"summary=New crash\ncustomfield_10300=High"},
+		reqs: jiraFakeCreate(`{"fields":{"customfield_10300":{"id":"10"},` +
+			`"issuetype":{"id":"1"},"project":{"key":"X"},` +
+			`"summary":"New crash"}}`),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "X-5",
+			IssueinfoStrProj: "X", IssueinfoStrSummary: "New crash"})},
+	{action: "link a case to another",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "X-2"},
+		reqs: []fakeReq{jiraFakeLinkTypes,
+			{req: "PUT " + jiraFakeIssue + ` "outwardIssue":{"key":"X-2"}`}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrType: "Blocks",
+			IssueinfoStrDirection: "blocks"})},
+	{action: "link a case to another",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "X-2",
+			IssueinfoStrKey: "is blocked by"},
+		reqs: []fakeReq{jiraFakeLinkTypes,
+			{req: "PUT " + jiraFakeIssue + ` "inwardIssue":{"key":"X-2"}`}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrType: "Blocks",
+			IssueinfoStrDirection: "is blocked by"})},
+	{action: "link a case to another",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "X-3",
+			IssueinfoStrKey: "Clones"},
+		reqs: []fakeReq{jiraFakeLinkTypes,
+			{req: "PUT " + jiraFakeIssue +
+				` {"outwardIssue":{"key":"X-3"},"type":{"inward":"is cloned by",` +
+				`"name":"Cloners","outward":"clones"}}`}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrType: "Cloners",
+			IssueinfoStrDirection: "clones"})},
+	{action: "link a case to another",
+		inf: IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrLink: "https://gerrit.example.com/c/app/+/124"},
+		reqs: []fakeReq{{req: "POST " + jiraFakeIssue + "/remotelink" +
+			` {"object":{"title":"https://gerrit.example.com/c/app/+/124",` +
+			`"url":"https://gerrit.example.com/c/app/+/124"}}`,
+			resp: "remotelink.json", code: http.StatusCreated}}},
+	{action: "link a case to another",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "X-3",
+			IssueinfoStrKey: "duplicates"},
+		reqs: []fakeReq{jiraFakeLinkTypes}, err: eztools.ErrInvalidInput},
+	{action: "list links of a case",
+		inf:  IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: jiraFakeLinks,
+		chk: fakeChk(3, IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrLink: "X-2", IssueinfoStrLinkID: "20001",
+			IssueinfoStrType: "Blocks", IssueinfoStrDirection: "is blocked by",
+			IssueinfoStrState: "Open", IssueinfoStrSummary: "Crash on start"})},
+	{action: "remove a link",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "X-3"},
+		reqs: append(jiraFakeLinks[:2:2],
+			fakeReq{req: "DELETE /rest/api/latest/issueLink/20002"}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrLink: "X-3",
+			IssueinfoStrDirection: "clones"})},
+	{action: "remove a link",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLinkID: "30001"},
+		reqs: append(jiraFakeLinks[:2:2],
+			fakeReq{req: "DELETE " + jiraFakeIssue + "/remotelink/30001"}),
+		chk: fakeChk(1, IssueInfos{IssueinfoStrType: "remote",
+			IssueinfoStrLink: "https://gerrit.example.com/c/app/+/123"})},
+	{action: "remove a link",
+		inf:  IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "X-9"},
+		reqs: jiraFakeLinks, err: eztools.ErrNoValidResults},
+	{action: "add a worklog to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrSize: "1h 30m",
+			IssueinfoStrLink: "2026-10-14T09:00:00Z", IssueinfoStrComments: "review",
+			IssueinfoStrEstimate: "2h"},
+		reqs: []fakeReq{{req: "POST " + jiraFakeIssue +
+			"/worklog?adjustEstimate=new&newEstimate=2h" +
+			` {"timeSpent":"1h 30m","started":"2026-10-14T09:00:00.000+0000",` +
+			`"comment":"review"}`, resp: "worklog.json", code: http.StatusCreated}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrKey: "10104", IssueinfoStrSize: "1h 30m",
+			IssueinfoStrAuthor: fakeUser})},
+	{action: "add a worklog to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrSize: "1h",
+			IssueinfoStrLink: "yesterday"},
+		err: eztools.ErrInvalidInput},
+	{action: "list worklogs of a case",
+		inf:  IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{jiraFakeWorklogs},
+		chk: fakeChk(4, IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrKey: "10100", IssueinfoStrComments: "review",
+			IssueinfoStrDate: "2026-10-14T09:00:00.000+0000"})},
+	{action: "change a worklog of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "10104",
+			IssueinfoStrComments: "review", IssueinfoStrEstimate: "leave"},
+		reqs: []fakeReq{{req: "PUT " + jiraFakeIssue +
+			`/worklog/10104?adjustEstimate=leave {"comment":"review"}`,
+			resp: "worklog.json"}}},
+	{action: "change a worklog of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "10104"},
+		err: eztools.ErrInvalidInput},
+	{action: "delete a worklog from a case",
+		inf:  IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "10104"},
+		reqs: []fakeReq{{req: "DELETE " + jiraFakeIssue + "/worklog/10104"}}},
+	{action: "start work on a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrDate: "2026-10-14T10:00:00Z"})},
+	{action: "stop work on a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		err: eztools.ErrNoValidResults},
+	{action: "report time logged today and this week",
+		reqs: []fakeReq{
+			{req: "GET /rest/api/latest/myself", resp: "myself.json"},
+			{req: "GET /rest/api/latest/search?jql=worklogAuthor+%3D+" +
+				"currentUser%28%29+AND+worklogDate+%3E%3D+%222026-10-12%22+" +
+				"ORDER+BY+key&maxResults=100&startAt=0", resp: "search_worklog.json"},
+			jiraFakeWorklogs,
+			{req: "GET /rest/api/latest/issue/X-2/worklog", resp: "worklogs.json"}},
+		chk: func(t *testing.T, res IssueInfoSlc) {
+			fakeChk(3, IssueInfos{IssueinfoStrID: "X-1",
+				IssueinfoStrSummary: "Crash on start",
+				IssueinfoStrToday:   "1h 30m", IssueinfoStrWeek: "3h 30m"})(t, res)
+			if res[2][IssueinfoStrToday] != "3h" ||
+				res[2][IssueinfoStrWeek] != "7h" {
+				t.Error("total of 3h and 7h expected, got", res[2])
+			}
+		}},
+	{action: "list watchers of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue + "/watchers", resp: "watchers.json"}},
+		chk: fakeChk(2, IssueInfos{IssueinfoStrID: "tester",
+			IssueinfoStrDispname: "Test Er"})},
+	{action: "check whether watching a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue + "/watchers", resp: "watchers.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrState: "true"})},
+	{action: "watch a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "POST " + jiraFakeIssue + `/watchers "tester"`}}},
+	{action: "unwatch a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "DELETE " + jiraFakeIssue + "/watchers?username=tester"}}},
+	{action: "add a file to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrFile: fakeUpload},
+		reqs: []fakeReq{
+			{req: "POST " + jiraFakeIssue + "/attachments to upload",
+				resp: "attachments.json"}}},
+	{action: "list files attached to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue, resp: "issue.json"}},
+		chk: fakeChk(1, IssueInfos{IssueinfoStrKey: "20",
+			IssueinfoStrFile: "log.txt", IssueinfoStrDesc: "text/plain"})},
+	{action: "get a file to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "20"},
+		reqs: []fakeReq{
+			{req: "GET " + jiraFakeIssue, resp: "issue.json"},
+			{req: "GET /secure/attachment/20/log.txt", resp: "files/log.txt"}},
+		chk: fakeChkFile("log.txt", "started\ncrashed\n")},
+	{action: "remove a file attached to a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrKey: "20"},
+		reqs: []fakeReq{
+			{req: "DELETE /rest/api/latest/attachment/20"}}},
+	{action: "reject a case from any known statuses",
+		inf: IssueInfos{IssueinfoStrID: "X-1",
+			IssueinfoStrComments: "Works as designed."},
+		reqs: []fakeReq{
+			{req: "POST " + jiraFakeIssue + `/comment {"body":"Works as designed."}`,
+				resp: "comment.json", code: http.StatusCreated},
+			{req: "GET " + jiraFakeIssue + "/transitions", resp: "transitions.json"},
+			{req: "POST " + jiraFakeIssue + `/transitions {"transition":{"id":"21"}}`}}},
+	{action: "close a case to resolved from any known statuses",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrLink: "run it"},
+		reqs: []fakeReq{
+			{req: "PUT " + jiraFakeIssue + ` "customfield_10200": "run it"`},
+			{req: "GET " + jiraFakeIssue + "/transitions", resp: "transitions.json"},
+			{req: "POST " + jiraFakeIssue + `/transitions {"transition":{"id":"31"}}`}}},
+	{action: "close a case with default design as steps",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "PUT " + jiraFakeIssue +
+				` "customfield_10200": "default AOSP/vendor/design"`},
+			{req: "GET " + jiraFakeIssue + "/transitions", resp: "transitions.json"},
+			{req: "POST " + jiraFakeIssue + `/transitions {"transition":{"id":"31"}}`}}},
+	{action: "close a case with general requirement as steps",
+		inf: IssueInfos{IssueinfoStrID: "X-1"},
+		reqs: []fakeReq{
+			{req: "PUT " + jiraFakeIssue +
+				` "customfield_10200": "general requirement"`},
+			{req: "GET " + jiraFakeIssue + "/transitions", resp: "transitions.json"},
+			{req: "POST " + jiraFakeIssue + `/transitions {"transition":{"id":"31"}}`}}},
+}
+
+func TestJiraOffline(t *testing.T) {
+	fakeRun(t, CategoryJira, jiraFakeCases)
+}
+
+// jiraFakeCreate makes requests to create a bug with a body
+func jiraFakeCreate(body string) []fakeReq {
+	reqs := []fakeReq{
+		{req: "GET /rest/api/latest/issue/createmeta/X/issuetypes" +
+			"?maxResults=100", resp: "createmeta_types.json"},
+		{req: "GET /rest/api/latest/issue/createmeta/X/issuetypes/1" +
+			"?maxResults=100", resp: "createmeta_bug.json"}}
+	if len(body) < 1 {
+		return reqs
+	}
+	return append(reqs, fakeReq{req: "POST /rest/api/latest/issue " + body,
+		resp: "created.json", code: http.StatusCreated})
+}
+
+func TestJiraCreate(t *testing.T) {
+	for _, c := range []struct {
+		pairs []string
+		body  string
+		err   error
+	}{
+		{[]string{"issuetype=1", "severity=low", "labels=a, b",
+			"customfield_10400=3", "summary=by -s"},
+			`{"fields":{"customfield_10300":{"id":"11"},` +
+				`"customfield_10400":3,"issuetype":{"id":"1"},` +
+				`"labels":["a","b"],"project":{"key":"X"},` +
+				`"summary":"by -s"}}`, nil},
+		// required severity missing
+		{[]string{"issuetype=Bug"}, "", eztools.ErrInvalidInput},
+		{[]string{"issuetype=Bug", "Severity=Medium"}, "",
+			eztools.ErrInvalidInput},
+		{[]string{"issuetype=Bug", "Severity=High", "nofield=1"}, "",
+			eztools.ErrInvalidInput},
+	} {
+		fakeRun(t, CategoryJira, []fakeCase{{action: "create a case",
+			inf: IssueInfos{IssueinfoStrSummary: "by -hd",
+				IssueinfoStrFields: fieldsStr(c.pairs)},
+			reqs: jiraFakeCreate(c.body), err: c.err}})
+	}
+	fakeRun(t, CategoryJira, []fakeCase{{action: "create a case",
+		inf: IssueInfos{IssueinfoStrFields: "issuetype"},
+		err: eztools.ErrInvalidInput},
+		// by params of the same keys, as serve takes
+		{action: "create a case",
+			inf: IssueInfos{"issuetype": "Bug", IssueinfoStrSummary: "by key",
+				"customfield_10300": "Low"},
+			reqs: jiraFakeCreate(`{"fields":{"customfield_10300":{"id":"11"},` +
+				`"issuetype":{"id":"1"},"project":{"key":"X"},` +
+				`"summary":"by key"}}`),
+			chk: fakeChk(1, IssueInfos{IssueinfoStrID: "X-5"})}})
+}
+
+func TestJiraEditFlds(t *testing.T) {
+	editmeta := fakeReq{req: "GET " + jiraFakeIssue + "/editmeta",
+		resp: "editmeta.json"}
+	fields := fakeReq{req: "GET /rest/api/latest/field", resp: "fields.json"}
+	for _, c := range []struct {
+		edit string
+		reqs []fakeReq
+	}{
+		{"Labels", nil},
+		{"Summary+=more", []fakeReq{editmeta}},
+		{"Fix Version/s+=9.9", []fakeReq{editmeta}},
+		{"Sprint=1", []fakeReq{editmeta, fields}},
+		{"Team=core", []fakeReq{editmeta, fields}},
+		{"NoField=1", []fakeReq{editmeta, fields}},
+	} {
+		fakeRun(t, CategoryJira, []fakeCase{{action: "edit fields of a case",
+			inf:  IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrFields: c.edit},
+			reqs: c.reqs, err: eztools.ErrInvalidInput}})
+	}
+	fakeRun(t, CategoryJira, []fakeCase{{action: "edit fields of a case",
+		inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrFields: "Labels="},
+		reqs: []fakeReq{editmeta,
+			{req: "PUT " + jiraFakeIssue + ` {"update":{"labels":[{"set":[]}]}}`}}}})
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
@@ -14,9 +17,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 
 	"gitee.com/bon-ami/eztools/v6"
 )
@@ -29,6 +33,12 @@ const ( // exit codes
 	extRslt
 	extGram
 	extSrvr
+	extIntr
+	extBldUnstable
+	extBldFailure
+	extBldAborted
+	extBldOther
+	extOthr
 )
 
 const (
@@ -51,6 +61,8 @@ const (
 	PassDigest = "digest"
 	// PassToken for headers in xml
 	PassToken = "token"
+	// PassBearer personal access token in xml, as "Authorization: Bearer"
+	PassBearer = "bearer"
 	// intGerritMerge is interval between each status check to merge a submit, in seconds
 	intGerritMerge = 15
 	// actionSep is the separator for multiple actions
@@ -81,7 +93,6 @@ var (
 	cfg       jirrit
 	uiSilent  bool
 	step      int
-	svrTypes  []string
 	errAuth   = errors.New("auth failure")
 	errConn   = errors.New("conn failure")
 	errCfg    = errors.New("cfg failure")
@@ -89,9 +100,15 @@ var (
 	errSrvr   = errors.New("server error")
 )
 
+// cfgMu guards projects of servers in cfg, and saving of it
+var cfgMu sync.Mutex
+
 type passwords struct {
 	Cmt  string `xml:",comment"`
 	Type string `xml:"type,attr"`
+	// Src is where to get the password, PassSrcText by default,
+	// with Pass as what the source needs
+	Src  string `xml:"src,attr,omitempty"`
 	Pass string `xml:",chardata"`
 }
 
@@ -123,6 +140,40 @@ type svrs struct {
 	Flds  fields    `xml:"fields"`
 	Proj  string    `xml:"project"`
 	Watch string    `xml:"watch"`
+	// Jobs is the default number of IDs to process at a time
+	Jobs int `xml:"jobs"`
+	// Retries is the max times to retry a request, when the server is busy
+	Retries int `xml:"retries"`
+	// RetryWait is the initial wait in milliseconds before a retry
+	RetryWait int `xml:"retrywait"`
+	// Timeout is the timeout in seconds of each request, including retries
+	Timeout int `xml:"timeout"`
+	// CA is the file of CA certificates in PEM, besides system ones
+	CA string `xml:"ca,omitempty"`
+	// Cert and Key are files of the client certificate and key in PEM,
+	// Key defaulting to Cert
+	Cert string `xml:"cert,omitempty"`
+	Key  string `xml:"key,omitempty"`
+	// Insecure skips verification of certificates of the server
+	Insecure bool `xml:"insecure,omitempty"`
+	// Proxy is an http, https or socks5 URL of the proxy,
+	// with NoProxy or $NO_PROXY not to use it.
+	// Without it, proxies from environment are used.
+	Proxy   string `xml:"proxy,omitempty"`
+	NoProxy string `xml:"noproxy,omitempty"`
+	// UserAgent is User-Agent of requests, if any
+	UserAgent string `xml:"useragent,omitempty"`
+	// Headers are added to all requests
+	Headers []header `xml:"header"`
+	// client sends requests to the server, shared by its copies
+	client *svrClient
+}
+
+// header is a custom header of requests to a server,
+// with its value from a source as passwords
+type header struct {
+	Name string `xml:"name,attr"`
+	passwords
 }
 
 type jirrit struct {
@@ -135,7 +186,18 @@ type jirrit struct {
 	Log  string    `xml:"log"`
 	User string    `xml:"user"`
 	Pass passwords `xml:"pass"`
-	Svrs []svrs    `xml:"server"`
+	// PassStore is the file of the encrypted password store
+	PassStore string `xml:"passstore"`
+	// Audit is the file of the audit log
+	Audit string `xml:"audit"`
+	// Serve is config of the JSON API of the serve command
+	Serve struct {
+		// Tokens are bearer tokens allowed to access the API
+		Tokens []passwords `xml:"token"`
+	} `xml:"serve"`
+	// Templates render results of actions for text output
+	Templates []outTemplate `xml:"templates>template"`
+	Svrs      []svrs        `xml:"server"`
 }
 
 // LogTypeErr logs failure in type conversion
@@ -147,35 +209,31 @@ func LogTypeErr(v any, exp string) {
 
 // Log wrapped logging and command output
 func Log(onscreen, wttime bool, inf ...any) {
+	if onscreen {
+		showStrln(inf...)
+	}
 	if len(cfg.Log) < 1 {
-		switch onscreen {
-		case true:
-			eztools.ShowStrln(inf...)
-		}
 		return
 	}
-	switch onscreen {
+	switch wttime {
 	case true:
-		switch wttime {
-		case true:
-			eztools.LogPrintWtTime(inf...)
-		case false:
-			eztools.LogPrint(inf...)
-		}
+		eztools.LogWtTime(inf...)
 	case false:
-		switch wttime {
-		case true:
-			eztools.LogWtTime(inf...)
-		case false:
-			eztools.Log(inf...)
-		}
+		eztools.Log(inf...)
 	}
 }
 
 type params struct {
-	h, ver, v, vv, vvv, reverse, getSvrCfg, setSvrCfg         bool
-	r, a, w, k, f, z, i, b, cfg, log, hd, p, l, c, fn, fv, fs string
-	Def, CfgSvrOpt                                            string
+	h, ver, v, vv, vvv, reverse, getSvrCfg, setSvrCfg            bool
+	r, a, w, k, f, z, i, b, cfg, log, hd, p, l, c, fn, fv, fs, o string
+	undo, workflow                                               string
+	Def, CfgSvrOpt                                               string
+	j, timeout                                                   int
+	listen, cmd, where, format, sort, fields, groupBy, errReport string
+	limit                                                        int
+	dryRun, follow                                               bool
+	followMatch, estimate, toStatus                              string
+	s                                                            []string
 }
 
 func (p *params) Declare() {
@@ -201,7 +259,9 @@ func (p *params) Declare() {
 		"multiple actions separated by "+actionSep)
 	flag.StringVar(&p.w, "w", ParamDef, "JIRA ID to store in settings, "+
 		"to be together with -r. current setting shown, if empty value.")
-	flag.StringVar(&p.k, "k", "", "key or description. reject reason for JIRA")
+	flag.StringVar(&p.k, "k", "", "key or description. "+
+		"reject reason, JQL of searches, type of links "+
+		"or worklog ID for JIRA")
 	flag.StringVar(&p.i, "i", "",
 		"ID of isSue, change, commit or assignee, or build for Jenkins")
 	flag.StringVar(&p.b, "b", "", "branch for JIRA and Gerrit")
@@ -213,15 +273,45 @@ func (p *params) Declare() {
 			"or job ID for Jenkins")
 	flag.StringVar(&p.c, "c", "",
 		"new component when transferring issues, "+
-			"or comment for transitions for JIRA and bugzilla")
+			"or comment for transitions for JIRA and bugzilla, "+
+			"or of worklogs for JIRA")
 	flag.StringVar(&p.l, "l", "",
 		"test steps for JIRA, or, "+
-			"linked issue when linking issues, "+
+			"linked issue or URL when linking issues, "+
+			"or start time of worklogs, "+
+			"or more fields of JQL searches, separated by commas, "+
 			"or resolution of transition in bugzilla, "+
-			"or more param for issue listing of Gerrit")
+			"or more param for issue listing of Gerrit, "+
+			"or parameters of builds for Jenkins, as NAME=VALUE separated by &")
 	flag.StringVar(&p.f, "f", "", "file to be sent/saved as, "+
 		"or file ID of download in Gerrit")
-	flag.StringVar(&p.z, "z", "", "number limit to show Jenkins builds")
+	flag.StringVar(&p.z, "z", "", "number limit to show Jenkins builds, "+
+		"or time spent of worklogs for JIRA")
+	flag.StringVar(&p.estimate, "estimate", "", "new remaining "+
+		"estimate of a case for worklogs for JIRA, or leave to keep it. "+
+		"adjusted automatically by default")
+	flag.StringVar(&p.toStatus, "to-status", "", "status to move a case "+
+		"to for JIRA, instead of choosing a transition")
+	flag.IntVar(&p.j, "j", 0, "number of IDs to process at a time, "+
+		"to be together with -a. jobs of the server by default")
+	flag.BoolVar(&p.dryRun, "dry-run", false, "print requests "+
+		"changing anything, such as PUT, POST and DELETE, instead of "+
+		"sending them, and take them as successes. others are sent.")
+	flag.BoolVar(&p.follow, "follow", false, "follow console "+
+		"output of a Jenkins build till it finishes, for "+
+		"\"get log of a build\", exiting with its result")
+	flag.StringVar(&p.followMatch, "follow-match", "", "regular "+
+		"expression of lines of console output to show, with -follow")
+	flag.IntVar(&p.timeout, "timeout", 0, "timeout in seconds "+
+		"of each request, including retries. "+
+		"timeout of the server by default. no timeout if 0")
+	flag.StringVar(&p.undo, "undo", "", "entry number of the audit log "+
+		"to undo, by the inverse action, if any")
+	flag.StringVar(&p.workflow, "workflow", "", "YAML file of steps "+
+		"to run, each of which is an action on a server, "+
+		"with params referring to results of steps before")
+	flag.StringVar(&p.listen, "listen", serveListenDef, "address to "+
+		"listen on, for "+serveCmd)
 	flag.StringVar(&p.cfg, "cfg", "", "config file")
 	flag.StringVar(&p.log, "log", "", "log file")
 	flag.Var(&paramS, "s", "solution for bugzilla closure. "+
@@ -230,7 +320,9 @@ func (p *params) Declare() {
 		"appended to each field definition, such as "+
 		"\"-s 'guten morgen; bonne soirée'\" is similar to "+
 		"\"-s morgen -s tag\" if \"guten\" & \"bonne\" "+
-		"defined in config as in example.xml.")
+		"defined in config as in example.xml. "+
+		"Or fields as NAME=VALUE to create JIRA cases, "+
+		"or edits as NAME=VALUE, NAME+=VALUE or NAME-=VALUE of JIRA cases.")
 	flag.StringVar(&p.fn, "fn", "", "output filter, name. "+
 		"to be used together with fv or fs")
 	flag.StringVar(&p.fv, "fv", "", "output filter, value. "+
@@ -238,13 +330,34 @@ func (p *params) Declare() {
 		"this name-value pair only")
 	flag.StringVar(&p.fs, "fs", "", "output filter, python3 script. "+
 		"not to be used together with fn or fv. "+
-		"results filtered by return value 0 from script fv, "+
-		"run by command fn. Note this may be very time-consuming")
+		"results filtered by script fs, run by command fn once for "+
+		"each batch, reading results as a JSON array and writing "+
+		"a JSON array of true or false for each")
+	flag.StringVar(&p.where, "where", "", "output filter, expression "+
+		"of keys of results, such as "+
+		`'status != "Closed" && branch =~ "^release/" && created > now-7d'`)
+	flag.StringVar(&p.o, "o", OutText, "output format of results, "+
+		strings.Join(outFmts, "|")+". "+
+		"logs and prompts go to stderr for formats other than "+OutText)
+	flag.StringVar(&p.format, "format", "", "Go template to render "+
+		"each result, instead of -o, such as "+
+		`'{{.id}}\t{{.status}}\t{{trunc 40 .summary}}', `+
+		"with helpers trunc, pad, date and join")
+	flag.StringVar(&p.sort, "sort", "", "field to sort results by, "+
+		"as field[:desc], numbers in IDs such as X-12 compared as numbers")
+	flag.StringVar(&p.fields, "fields", "", "fields of results "+
+		"to output in order, separated by commas")
+	flag.IntVar(&p.limit, "limit", 0, "max number of results "+
+		"of each ID, after sorting. no limit if 0")
+	flag.StringVar(&p.groupBy, "group-by", "", "field to count "+
+		"results by, printing counts instead of results")
+	flag.StringVar(&p.errReport, "error-report", "", "format of the "+
+		"report of all failures to stderr, with server, action, ID, "+
+		"HTTP status and response of each. "+ErrReportJSON+" only")
 }
 
 func (p params) Parse() {
 	flag.Parse()
-	eztools.AuthInsecureTLS = true
 	if p.reverse {
 		step = -1
 	} else {
@@ -263,25 +376,58 @@ func mkIssueinfo(p params) IssueInfos {
 		{p.l, IssueinfoStrLink},
 		{p.f, IssueinfoStrFile},
 		{p.z, IssueinfoStrSize},
-		{p.c, IssueinfoStrComments}}
-	// paramS to be handled when needed
+		{p.c, IssueinfoStrComments},
+		{p.estimate, IssueinfoStrEstimate},
+		{p.toStatus, IssueinfoStrToState}}
 	for _, i := range matrix {
 		if len(i[0]) > 0 {
 			inf[i[1]] = i[0]
 		}
 	}
+	if len(p.s) > 0 {
+		inf[IssueinfoStrFields] = fieldsStr(p.s)
+	}
 	return inf
 }
 
+// fieldsStr makes a value of IssueinfoStrFields of -s,
+// as lines, or a JSON array if any of them has multiple lines
+func fieldsStr(pairs []string) string {
+	if !slices.ContainsFunc(pairs, func(s string) bool {
+		return strings.Contains(s, "\n")
+	}) {
+		return strings.Join(pairs, "\n")
+	}
+	b, _ := json.Marshal(pairs)
+	return string(b)
+}
+
+// fieldsOf gets -s from IssueinfoStrFields, as fieldsStr makes it
+func fieldsOf(inf IssueInfos) []string {
+	var pairs []string
+	if err := json.Unmarshal([]byte(inf[IssueinfoStrFields]),
+		&pairs); err == nil {
+		return pairs
+	}
+	for _, pair := range strings.Split(inf[IssueinfoStrFields], "\n") {
+		if len(strings.TrimSpace(pair)) > 0 {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
 // DefLooper to loop one action/function with multiple ID's
 type DefLooper struct {
 	para          params
 	svr           *svrs
 	authInfo      eztools.AuthInfo
+	hdr           http.Header  // Headers of requests, by cfg2AuthInfo
 	funStr1       string       // A string describing the function
 	fun1          actionFunc   // Function for action to be called
 	issueInfoPrev IssueInfoSlc // issue information slices acumulated among loops
 	maxResults    int          // Maximum number of results in each loop
+	quiet         bool         // Results not printed, such as for serve
 }
 
 // SetFun to set the function to be called in all loops
@@ -300,15 +446,51 @@ func (l *DefLooper) GetIssueInfo() IssueInfoSlc {
 	return l.issueInfoPrev
 }
 
-// Loop to be used with loopIssues
-func (l *DefLooper) Loop(inf IssueInfos) (IssueInfoSlc, error) {
+// Jobs is the number of IDs to process at a time, from -j or the server.
+// Only one at a time, with interactions allowed.
+func (l *DefLooper) Jobs() int {
+	switch {
+	case !uiSilent:
+		return 1
+	case l.para.j > 0:
+		return l.para.j
+	case l.svr.Jobs > 0:
+		return l.svr.Jobs
+	}
+	return 1
+}
+
+// Timeout is the timeout of requests in seconds, from -timeout or the server
+func (l *DefLooper) Timeout() int {
+	if l.para.timeout > 0 {
+		return l.para.timeout
+	}
+	return l.svr.Timeout
+}
+
+// Exec runs the action on one ID, maybe along with other IDs,
+// with requests changing anything written to the audit log
+func (l *DefLooper) Exec(ctx context.Context,
+	inf IssueInfos) (IssueInfoSlc, error) {
 	Log(false, true, l.svr.Name, l.funStr1, inf)
+	svr := l.svr
+	if l.Jobs() > 1 {
+		// jobs at the same time may change its project
+		svr = svrCopy(svr)
+	}
+	ctx, rec := auditStart(ctx, svr, l.authInfo, l.funStr1, inf)
+	issues, err := l.fun1(ctx, svr, l.authInfo, inf)
+	rec.done(err)
+	return issues, err
+}
+
+// Done prints and accumulates results of one ID, in order of input
+func (l *DefLooper) Done(inf IssueInfos, issues IssueInfoSlc, err error) {
 	id := inf[IssueinfoStrID]
-	issues, err := l.fun1(l.svr, l.authInfo, inf)
 	if err != nil {
 		var op bool
 		e := err
-		if err == eztools.ErrNoValidResults {
+		if errors.Is(err, eztools.ErrNoValidResults) {
 			if !uiSilent {
 				op = true
 			}
@@ -316,19 +498,30 @@ func (l *DefLooper) Loop(inf IssueInfos) (IssueInfoSlc, error) {
 		}
 		Log(op, false, e)
 	} else {
-		if (l.maxResults > 0) && (len(issues) > l.maxResults) {
+		outSort(issues)
+		var taken []bool
+		if !l.quiet {
+			taken = issues.filter(l.para.fn, l.para.fv, l.para.fs)
+		}
+		// the limit is of results taken by filters, if any,
+		// with those before the last one kept as well
+		if n := limitTaken(taken, len(issues), l.maxResults); n < len(issues) {
 			Log(true, false, "limiting to", l.maxResults, "results")
-			issues = issues[:l.maxResults]
+			issues = issues[:n]
+		}
+		if !l.quiet {
+			issues.print(l.svr.Type, l.funStr1, id, taken)
 		}
-		issues.Print(id, l.para.fn, l.para.fv, l.para.fs)
 		l.issueInfoPrev = append(l.issueInfoPrev, issues...)
 	}
-	return issues, err
 }
 
-func mainLoop(svr *svrs, cats cat2Act, funs []action2Func,
-	issueInfo IssueInfos, para params) (err []error) {
-	var choices []string
+func mainLoop(ctx context.Context, svr *svrs, cats cat2Act,
+	funs []action2Func, issueInfo IssueInfos, para params) (err []error) {
+	var (
+		choices []string
+		acts    []action2Func
+	)
 	for ; ; svr = nil { // reset among loops
 		if svr == nil {
 			svr = chooseSvr(cats, cfg.Svrs)
@@ -336,27 +529,32 @@ func mainLoop(svr *svrs, cats cat2Act, funs []action2Func,
 				break
 			}
 		}
-		authInfo, errCfg := cfg2AuthInfo(*svr, cfg)
+		authInfo, hdr, errCfg := cfg2AuthInfo(*svr, cfg)
 		if errCfg != nil {
-			Log(false, false, errCfg)
+			// such as a wrong passphrase of the password store
+			Log(true, false, errCfg)
 			os.Exit(extCfg)
 		}
 		if len(svr.Proj) > 0 && !uiSilent {
-			eztools.ShowStrln("default project/ID prefix: " +
+			showStrln("default project/ID prefix: " +
 				svr.Proj)
 		}
 		if funs == nil {
-			choices = makeActs2Choose(*svr, cats[svr.Type])
+			choices, acts = makeActs2Choose(*svr, cats[svr.Type])
 		}
 		looper := DefLooper{
-			para: para, svr: svr, authInfo: authInfo, maxResults: -1}
-		err = LoopActions(svr, funs, issueInfo, &looper,
+			para: para, svr: svr, authInfo: authInfo, hdr: hdr,
+			maxResults: para.limit}
+		// for requests of input
+		ctxSvr := withClient(ctx, svr, hdr)
+		err = LoopActions(ctx, svr, funs, issueInfo, &looper,
 			func() (string, actionFunc, IssueInfoSlc) {
-				return chooseAct(svr,
-					authInfo, choices, cats[svr.Type],
+				return chooseAct(ctxSvr, svr,
+					authInfo, choices, acts,
 					mkIssueinfo(para), looper.GetIssueInfo())
 			})
-		if funs != nil || len(cfg.Svrs) < 2 {
+		if funs != nil || len(cfg.Svrs) < 2 ||
+			errors.Is(errors.Join(err...), errIntr) {
 			break
 		}
 	}
@@ -365,33 +563,38 @@ func mainLoop(svr *svrs, cats cat2Act, funs []action2Func,
 
 // LoopActions
 // when all looper fails for one action, this returns
+// An action is stopped by ctx or SIGINT, with results so far kept.
 // Return value: non-nil errors, action results wrapped with action names
-func LoopActions(svr *svrs, funs []action2Func, issueInfo IssueInfos,
-	looper *DefLooper,
+func LoopActions(ctx context.Context, svr *svrs, funs []action2Func,
+	issueInfo IssueInfos, looper *DefLooper,
 	chooseAct func() (string, actionFunc, IssueInfoSlc)) (err []error) {
 	var (
 		fun1    actionFunc
 		funStr1 string
 	)
+	ctx = withTimeout(withRetry(withClient(ctx, svr, looper.hdr), svr),
+		looper.Timeout())
 	for funIndx := 0; ; fun1 = nil { // reset fun1 among loops
 		var issueInfoCurr IssueInfoSlc
 		if funs != nil && funIndx < len(funs) {
 			// looping silent actions
 			fun1 = funs[funIndx].f
 			funStr1 = funs[funIndx].n
-			issueInfoCurr = looper.GetIssueInfo()
+			if funIndx == 0 {
+				// the first silent action takes the input,
+				// as there are no results of a former one yet
+				issueInfoCurr = IssueInfoSlc{issueInfo}
+			} else {
+				issueInfoCurr = looper.GetIssueInfo()
+			}
 			funIndx++
 			if eztools.Debugging && eztools.Verbose > 0 {
 				Log(true, false, "one action:", funStr1)
 			}
 		} else {
-			// if fun1 == nil { // reset issueInfo among loops
 			if chooseAct != nil {
 				funStr1, fun1, issueInfoCurr = chooseAct()
 			}
-			// } else { // first round and silent
-			// issueInfoCurr = IssueInfoSlc{issueInfo}
-			// }
 		}
 		if fun1 == nil {
 			if eztools.Debugging && eztools.Verbose > 0 {
@@ -406,12 +609,39 @@ func LoopActions(svr *svrs, funs []action2Func, issueInfo IssueInfos,
 		if issueInfoCurr == nil {
 			issueInfoCurr = IssueInfoSlc{makeIssueInfo()}
 		}
+		// SIGINT stops this action, instead of the process
+		ctxAct, stop := intrCtx(ctx)
 		for _, inf := range issueInfoCurr {
-			if _, err1 := loopIssues(svr, inf, looper.Loop); err1 != nil {
+			if ctxAct.Err() != nil {
+				break
+			}
+			if _, err1 := loopIssuesJobs(ctxAct, svr, inf, looper.Jobs(),
+				func(inf IssueInfos) (IssueInfoSlc, error) {
+					return looper.Exec(ctxAct, inf)
+				}, looper.Done); err1 != nil {
 				Log(false, false, err1)
-				err = append(err, fmt.Errorf("%s: %w", funStr1, err1))
+				// one for each ID, if multiple
+				errs1 := []error{err1}
+				if j, ok := err1.(interface{ Unwrap() []error }); ok {
+					errs1 = j.Unwrap()
+				}
+				for _, e := range errs1 {
+					id := inf[IssueinfoStrID]
+					if ie, ok := e.(issueErr); ok {
+						id, e = ie.id, ie.err
+					}
+					err = append(err, &actErr{svr: svr.Name,
+						action: funStr1, id: id, err: e})
+				}
 			}
 		}
+		if cause := context.Cause(ctxAct); cause != nil &&
+			!errors.Is(errors.Join(err...), cause) {
+			// interrupted after the last ID, with nothing skipped
+			err = append(err, &actErr{svr: svr.Name, action: funStr1,
+				err: cause})
+		}
+		stop()
 		if err != nil || (funs != nil && funIndx == len(funs)) {
 			break
 		}
@@ -423,6 +653,9 @@ func flagParse() params {
 	var p params
 	p.Declare()
 	p.Parse()
+	// a command after params, if any
+	p.cmd = flag.Arg(0)
+	p.s = paramS
 
 	eztools.Debugging = p.v || p.vv || p.vvv
 	switch {
@@ -433,34 +666,47 @@ func flagParse() params {
 	case p.v:
 		eztools.Verbose = 1
 	}
+	dryRun = p.dryRun
 	return p
 }
 
 func flagHelp() {
-	eztools.ShowStrln("::Return values::")
-	eztools.ShowStrln("", "0", "no error")
-	eztools.ShowStrln("", extCfg, "config error")
-	eztools.ShowStrln("", extAuth, "auth error")
-	eztools.ShowStrln("", extConn, "connection error")
-	eztools.ShowStrln("", extInpt, "input error")
-	eztools.ShowStrln("", extRslt, "result error")
-	eztools.ShowStrln("", extGram, "request error")
-	eztools.ShowStrln("", extSrvr, "server error")
-	eztools.ShowStrln("::When inputting ID's, there are following",
+	showStrln("::Return values::")
+	showStrln("", "0", "no error")
+	showStrln("", extCfg, "config error")
+	showStrln("", extAuth, "auth error")
+	showStrln("", extConn, "connection error")
+	showStrln("", extInpt, "input error")
+	showStrln("", extRslt, "result error")
+	showStrln("", extGram, "request error")
+	showStrln("", extSrvr, "server error")
+	showStrln("", extIntr, "interrupted, with results so far")
+	showStrln("", extBldUnstable, "Jenkins build unstable, with -follow")
+	showStrln("", extBldFailure, "Jenkins build failed, with -follow")
+	showStrln("", extBldAborted, "Jenkins build aborted, with -follow")
+	showStrln("", extBldOther, "Jenkins build with other results, "+
+		"such as NOT_BUILT, with -follow")
+	showStrln("", extOthr, "other error")
+	showStrln("::When inputting ID's, there are following",
 		"options for some actions::")
-	eztools.ShowStrln(" 1. single ID, such as 0 or X-0")
-	eztools.ShowStrln(" 2. multiple IDs, such as 0,0,0 or X-0,2,1")
-	eztools.ShowStrln(" 3. ID range, such as 0,,2 or X-0,2")
-	eztools.ShowStrln("")
+	showStrln(" 1. single ID, such as 0 or X-0")
+	showStrln(" 2. multiple IDs, such as 0,0,0 or X-0,2,1")
+	showStrln(" 3. ID range, such as 0,,2 or X-0,2")
+	showStrln("")
+	showStrln("::\""+shellCmd+"\" after params starts a shell",
+		"of actions, with history and completion::")
+	showStrln("::\""+serveCmd+"\" after params serves actions",
+		"of all servers as a JSON API, on -listen::")
+	showStrln("")
 	flag.Usage()
-	eztools.ShowStrln("")
-	eztools.ShowStrln("::Action strings, \"a\", to be used with",
+	showStrln("")
+	showStrln("::Action strings, \"a\", to be used with",
 		"server names, \"r\", only, and that will",
 		"eliminate interactions in UI::")
 	for cat, i := range makeCat2Act() {
-		eztools.ShowStrln("\t\t" + cat)
+		showStrln("\t\t" + cat)
 		for _, j := range i {
-			eztools.ShowStrln("\t" + j.n)
+			showStrln("\t" + j.n)
 		}
 	}
 }
@@ -476,7 +722,7 @@ func loadCfg(p params) {
 			home, _ := os.UserHomeDir()
 			cfgFile = filepath.Join(home, module+".xml")
 		}
-		switch eztools.PromptStr("create " +
+		switch promptStr("create " +
 			cfgFile + "?([Enter]=y)") {
 		case "", "y", "Y", "yes", "YES", "Yes":
 			break
@@ -567,27 +813,66 @@ func matchFuncFromParam(action string, svr *svrs, cats cat2Act) []action2Func {
 	return ret
 }
 
+// errSummary shows all errors, such as ones of multiple IDs
+func errSummary(errs []error) {
+	if len(errs) < 2 {
+		return
+	}
+	Log(true, false, len(errs), "failures:")
+	for _, err := range errs {
+		Log(true, false, err)
+	}
+}
+
+// exitCode maps an error to an exit code, 0 for no errors,
+// or extOthr if none matched
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+	case errors.Is(err, eztools.ErrInvalidInput):
+		return extInpt
+	case errors.Is(err, eztools.ErrInExistence),
+		errors.Is(err, eztools.ErrNoValidResults),
+		errors.Is(err, eztools.ErrOutOfBound):
+		return extRslt
+	case errors.Is(err, errAuth):
+		return extAuth
+	case errors.Is(err, errCfg):
+		return extCfg
+	case errors.Is(err, errConn):
+		return extConn
+	case errors.Is(err, errGram):
+		return extGram
+	case errors.Is(err, errSrvr):
+		return extSrvr
+	case errors.Is(err, errIntr):
+		return extIntr
+	case errors.Is(err, errBldUnstable):
+		return extBldUnstable
+	case errors.Is(err, errBldFailure):
+		return extBldFailure
+	case errors.Is(err, errBldAborted):
+		return extBldAborted
+	case errors.Is(err, errBldOther):
+		return extBldOther
+	default:
+		// other failures of REST requests
+		var re *restErr
+		if errors.As(err, &re) {
+			return extSrvr
+		}
+		return extOthr
+	}
+	return 0
+}
+
 func errExit(err error) {
 	if err != nil {
 		if eztools.Debugging {
 			Log(true, false, "exit with \""+err.Error()+"\"")
 		}
-		switch err {
-		case eztools.ErrInvalidInput:
-			os.Exit(extInpt)
-		case eztools.ErrInExistence,
-			eztools.ErrNoValidResults, eztools.ErrOutOfBound:
-			os.Exit(extRslt)
-		case errAuth:
-			os.Exit(extAuth)
-		case errCfg:
-			os.Exit(extCfg)
-		case errConn:
-			os.Exit(extConn)
-		case errGram:
-			os.Exit(extGram)
-		case errSrvr:
-			os.Exit(extSrvr)
+		if code := exitCode(err); code != 0 {
+			os.Exit(code)
 		}
 	}
 }
@@ -620,11 +905,9 @@ func mkSvrFromParam(p string) (svr *svrs, ok bool) {
 }
 
 func main() {
-	svrTypes = []string{CategoryJira, CategoryGerrit, CategoryJenkins, CategoryBugzilla}
-
 	p := flagParse()
 	if p.ver {
-		eztools.ShowStrln(module + " version " + Ver + " build " + Bld)
+		showStrln(module + " version " + Ver + " build " + Bld)
 		return
 	}
 	if p.h {
@@ -634,15 +917,38 @@ func main() {
 	if eztools.Debugging && eztools.Verbose > 1 {
 		stdOutput = true
 	}
+	if err := setOutFmt(p.o); err != nil {
+		errExit(err)
+	}
+	if err := setWhere(p.where); err != nil {
+		errExit(err)
+	}
+	if err := setOutSet(p.sort, p.fields, p.groupBy); err != nil {
+		errExit(err)
+	}
+	if err := setErrReport(p.errReport); err != nil {
+		errExit(err)
+	}
+	if err := setFollow(p.follow, p.followMatch); err != nil {
+		errExit(err)
+	}
 	cats := makeCat2Act()
 	loadCfg(p)
+	if err := setTemplates(p.format, cfg.Templates); err != nil {
+		errExit(err)
+	}
+	if err := setClients(cfg.Svrs); err != nil {
+		Log(true, false, err)
+		errExit(err)
+	}
+	auditFile = auditLogFile()
 
 	if p.getSvrCfg {
 		Log(true, false, "user:"+cfg.User)
 		for _, svr := range cfg.Svrs {
 			Log(true, false, "type:"+svr.Type+", name:"+
 				svr.Name+", url:"+svr.URL+
-				", ip:"+svr.IP)
+				", ip:"+svr.IP+", "+svrNetDesc(svr))
 		}
 		if !saveCfg(false) {
 			os.Exit(extCfg)
@@ -697,18 +1003,30 @@ func main() {
 	Log(false, false, "runtime params: server="+
 		svrParam+", action=", funStr, ", info array:")
 	Log(false, false, issueInfo)
-	err := mainLoop(svr, cats, funs, issueInfo, p)
+	var err []error
+	switch {
+	case len(p.undo) > 0:
+		err = auditUndo(context.Background(), cats, p)
+	case len(p.workflow) > 0:
+		err = wfRun(context.Background(), cats, p)
+	case p.cmd == shellCmd:
+		err = shellRun(context.Background(), cats, p)
+	case p.cmd == serveCmd:
+		err = serveRun(context.Background(), cats, p)
+	default:
+		err = mainLoop(context.Background(), svr, cats, funs, issueInfo, p)
+	}
 
 	if eztools.Debugging {
-		eztools.ShowStrln("waiting for update check...")
+		showStrln("waiting for update check...")
 	}
 	if <-upch {
 		if eztools.Debugging {
-			eztools.ShowStrln("waiting for update check to start...")
+			showStrln("waiting for update check to start...")
 		}
 		if <-upch {
 			if eztools.Debugging {
-				eztools.ShowStrln("waiting for update check to end...")
+				showStrln("waiting for update check to end...")
 			}
 			if <-upch {
 				if cfg.AppUp.Interval > 0 {
@@ -718,92 +1036,129 @@ func main() {
 			}
 		}
 	}
+	outFlush()
+	errReportWrite(err)
 	if err != nil {
+		errSummary(err)
+		if errors.Is(errors.Join(err...), errIntr) {
+			errExit(errIntr)
+		}
 		errExit(err[0])
 	}
 	errExit(nil)
 }
 
-// Print outputs the results
-// Parameters: original ID, filter parameters, name, value, script
-func (issues IssueInfoSlc) Print(id, fn, fv, fs string) {
-	funcScript := func(issueInfo IssueInfos) bool {
-		jsonData, err := json.Marshal(issueInfo)
-		if err != nil {
-			Log(true, false, "Error serializing JSON:", err)
-			return true
-		}
-		if eztools.Debugging && eztools.Verbose > 0 {
-			Log(false, false, "filtering with", fn, fs)
-		}
-		cmd := exec.Command(fn, fs)
-		cmd.Stdin = bytes.NewReader(jsonData)
-		var out bytes.Buffer
-		if eztools.Debugging && eztools.Verbose > 1 {
-			cmd.Stdout = &out
-		}
-		err = cmd.Run()
-		if eztools.Debugging && eztools.Verbose > 1 {
-			Log(true, false, "script err=", err, "out=", out.String())
+// filterScript runs a script by a command once for all results,
+// which are JSON array on its stdin, to write a JSON array of
+// true or false for each of them on its stdout
+// Return value: whether each result is taken, or none on failures
+func filterScript(fn, fs string, issues IssueInfoSlc) []bool {
+	jsonData, err := json.Marshal(issues)
+	if err != nil {
+		Log(true, false, "Error serializing JSON:", err)
+		return nil
+	}
+	if eztools.Debugging && eztools.Verbose > 0 {
+		Log(false, false, "filtering with", fn, fs)
+	}
+	cmd := exec.Command(fn, fs)
+	cmd.Stdin = bytes.NewReader(jsonData)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if eztools.Debugging && eztools.Verbose > 1 {
+		Log(true, false, "script err=", err, "out=", string(out))
+	}
+	if err != nil {
+		Log(true, false, "filter script", fs, err)
+		return nil
+	}
+	var taken []bool
+	if err = json.Unmarshal(out, &taken); err != nil ||
+		len(taken) != len(issues) {
+		Log(true, false, "filter script", fs, "NOT writing",
+			len(issues), "true or false", err)
+		return nil
+	}
+	return taken
+}
+
+// Print outputs the results in format of outFmt
+// Parameters: server type, action name, original ID,
+// filter parameters, name, value, script
+func (issues IssueInfoSlc) Print(svrType, action, id, fn, fv, fs string) {
+	issues.print(svrType, action, id, issues.filter(fn, fv, fs))
+}
+
+// filter tells which results are taken, by -fn with -fv or -fs, and -where
+func (issues IssueInfoSlc) filter(fn, fv, fs string) []bool {
+	var fun func(int) bool
+	if len(fn) > 0 {
+		switch {
+		case len(fs) > 0:
+			if _, err := os.Stat(fs); err != nil {
+				Log(true, false, err)
+				break
+			}
+			taken := filterScript(fn, fs, issues)
+			fun = func(i int) bool {
+				return i < len(taken) && taken[i]
+			}
+		case len(fv) > 0:
+			fun = func(i int) bool {
+				return issues[i][fn] == fv
+			}
 		}
-		if err == nil {
-			return true
+	}
+	taken := make([]bool, len(issues))
+	for i := range issues {
+		taken[i] = len(issues[i]) > 0 && (fun == nil || fun(i)) &&
+			(whereFilter == nil || whereFilter(issues[i]))
+	}
+	return taken
+}
+
+// limitTaken returns the number of results, up to the one of max taken.
+// All are taken if taken is nil.
+func limitTaken(taken []bool, n, max int) int {
+	if max < 1 {
+		return n
+	}
+	if taken == nil {
+		return min(n, max)
+	}
+	for i := range taken {
+		if !taken[i] {
+			continue
 		}
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
-				exitCode := status.ExitStatus()
-				if eztools.Debugging && eztools.Verbose > 1 {
-					Log(true, false, "exit code=", exitCode)
-				}
-				if exitCode == 0 {
-					return true
-				}
-			}
+		if max--; max < 1 {
+			return i + 1
 		}
-		return false
 	}
+	return n
+}
+
+// print shows results taken, as a slice from filter
+func (issues IssueInfoSlc) print(svrType, action, id string, taken []bool) {
 	if issues == nil {
 		Log(true, false, "No results.")
-	} else {
-		var i int
-		if step == 0 {
-			step = 1
-		}
-		if step < 0 {
-			i = len(issues) - 1
-		} else {
-			i = 0
-		}
-		var fun func(IssueInfos) bool
-		if len(fn) > 0 {
-			switch {
-			case len(fs) > 0:
-				if _, err := os.Stat(fs); err != nil {
-					Log(true, false, err)
-					break
-				}
-				fun = funcScript
-			case len(fv) > 0:
-				fun = func(issueInfo IssueInfos) bool {
-					return issueInfo[fn] == fv
-				}
-			}
-		}
-		for ; i >= 0 && i < len(issues); i += step {
-			if len(issues[i]) < 1 {
-				continue
-			}
-			if fun != nil && !fun(issues[i]) {
-				continue
-			}
-			Log(true, false, "Issue/Reviewer/Comment/File",
-				i+1, "(input ID:", id, ")")
-			for i1, v1 := range issues[i] {
-				Log(true, false, "\t", i1+"="+
-					strings.ReplaceAll(v1, "\n", "\n\t\t"))
-			}
+		return
+	}
+	var i int
+	if step == 0 {
+		step = 1
+	}
+	if step < 0 {
+		i = len(issues) - 1
+	}
+	var recs []outRecord
+	for ; i >= 0 && i < len(issues); i += step {
+		if i >= len(taken) || !taken[i] {
+			continue
 		}
+		recs = append(recs, outRecord{Action: action,
+			InputID: id, Index: i + 1, Fields: issues[i]})
 	}
+	outWrite(svrType, action, recs)
 }
 
 func failSvrCfg(cfgSvrOpt string) {
@@ -822,7 +1177,7 @@ func chooseSvrByType(svr []svrs, tp string) *svrs {
 			indx = append(indx, i)
 		}
 	}
-	i, _ := eztools.ChooseStrings(names)
+	i, _ := chooseStrings(names)
 	if i == eztools.InvalidID {
 		return nil
 	}
@@ -830,8 +1185,8 @@ func chooseSvrByType(svr []svrs, tp string) *svrs {
 }
 
 func matchSvr(svr []svrs, name string) *svrs {
-	for i, s := range svr {
-		if s.Name == name {
+	for i := range svr {
+		if svr[i].Name == name {
 			return &svr[i]
 		}
 	}
@@ -846,7 +1201,7 @@ func chkUsr(user string, save bool) string {
 	if len(user) > 0 {
 		return user
 	}
-	un := eztools.PromptStr("config needed. username")
+	un := promptStr("config needed. username")
 	if len(un) < 1 {
 		un = user
 	} else {
@@ -892,7 +1247,7 @@ func chkNInputSvrFld(svrSlc []svrs, svr1 svrs, field *string, text string,
 				break
 			}
 		}
-		value = eztools.PromptStr(text + " for server " + id)
+		value = promptStr(text + " for server " + id)
 		if len(value) < 1 {
 			return
 		}
@@ -900,7 +1255,7 @@ func chkNInputSvrFld(svrSlc []svrs, svr1 svrs, field *string, text string,
 		changed = true
 	}
 	if chkExistSvr(svrSlc, text, value, indx) {
-		eztools.ShowStrln("name or ip in existence. please enter a new one.")
+		showStrln("name or ip in existence. please enter a new one.")
 		*field = ""
 		_, ok = chkNInputSvrFld(svrSlc, svr1, field, text, indx)
 		return true, ok
@@ -913,8 +1268,7 @@ func chkSvr(svr []svrs, pass passwords, cfgSvrOpt string) bool {
 		failSvrCfg(cfgSvrOpt)
 		return false
 	}
-	pass4All := len(pass.Type) > 0 &&
-		(len(pass.Pass) > 0 || pass.Type == PassNone)
+	pass4All := len(pass.Type) > 0 && pass.configured()
 	changed := false
 	for i, svr1 := range svr {
 		mandatory := []struct {
@@ -933,14 +1287,13 @@ func chkSvr(svr []svrs, pass passwords, cfgSvrOpt string) bool {
 		if pass4All {
 			continue
 		}
-		if len(svr1.Pass.Type) < 1 || (svr1.Pass.Type != PassNone && len(svr1.Pass.Pass) < 1) {
-			eztools.ShowStrln("NO global password configured. Configure it for " + svr[i].Name)
-			passType, passTxt, ok := inputPass4Svr(svr1.Type)
+		if len(svr1.Pass.Type) < 1 || !svr1.Pass.configured() {
+			showStrln("NO global password configured. Configure it for " + svr[i].Name)
+			pass, ok := inputPass4Svr(svr1.Type, svr1.Name)
 			if !ok {
 				return false
 			}
-			svr[i].Pass.Type = passType
-			svr[i].Pass.Pass = passTxt
+			svr[i].Pass = pass
 			changed = true
 		}
 	}
@@ -950,59 +1303,57 @@ func chkSvr(svr []svrs, pass passwords, cfgSvrOpt string) bool {
 	return saveCfg(false)
 }
 
-func inputPass4Svr(svrType string) (passType, passTxt string, ok bool) {
+// inputPass4Svr asks for type and source of password of a server
+func inputPass4Svr(svrType, name string) (pass passwords, ok bool) {
 	passTypes := []string{
 		PassNone + " - no password",
 		PassBasic + " - plain text",
 		PassPlain + " - base64'ed",
 		PassDigest + " - HTTP password, such as from Settings of Gerrit",
-		PassToken + " - token password, such as from API Key settings of Bugzilla"}
+		PassToken + " - token password, such as from API Key settings of Bugzilla",
+		PassBearer + " - personal access token, sent as a bearer one, " +
+			"such as of Jira Data Center"}
 	const (
 		pref = "Since this server is "
 		affi = " is recommended."
 	)
-	switch svrType {
-	case CategoryGerrit:
-		eztools.ShowStrln(pref + svrType + ", " + PassDigest + affi)
-	case CategoryJira, CategoryJenkins:
-		eztools.ShowStrln(pref + svrType + ", " + PassBasic + affi)
-	case CategoryBugzilla:
-		eztools.ShowStrln(pref + svrType + ", " + PassToken + affi)
+	if b, ok := backends[svrType]; ok {
+		showStrln(pref + svrType + ", " + b.PassType() + affi)
+		if h, ok := b.(passHinter); ok {
+			showStrln(h.PassHint())
+		}
 	}
-	typeInd, _ := eztools.ChooseStrings(passTypes)
+	typeInd, _ := chooseStrings(passTypes)
 	if typeInd == eztools.InvalidID {
 		return
 	}
 	passTypeSlc := strings.Split(passTypes[typeInd], " - ")
-	passType = passTypeSlc[0]
-	if passType != PassNone {
-		passTxt = eztools.PromptStr("password")
-		if len(passTxt) < 1 {
-			return
-		}
+	pass.Type = passTypeSlc[0]
+	if pass.Type != PassNone {
+		pass.Src, pass.Pass, ok = inputPassSrc(name)
+		return
 	}
 	ok = true
 	return
 }
 
 func addSvr(svrIn []svrs, pass passwords) (svrOut []svrs, ret bool) {
-	const MAGIC = ")]}'"
 	var name, url, ip, magic string
 	svrOut = svrIn
-	eztools.ShowStrln("Only mandatory fields for servers will be asked.")
+	showStrln("Only mandatory fields for servers will be asked.")
 	for {
-		typeInd, _ := eztools.ChooseStrings(svrTypes)
+		typeInd, _ := chooseStrings(svrTypes)
 		if typeInd == eztools.InvalidID {
 			break
 		}
 		svrType := svrTypes[typeInd]
 		for _, i := range []string{"name", "url", "ip"} {
-			value := eztools.PromptStr(i)
+			value := promptStr(i)
 			if len(value) < 1 {
 				break
 			}
 			if chkExistSvr(svrOut, i, value, -1) {
-				eztools.ShowStrln("server already exists")
+				showStrln("server already exists")
 				break
 			}
 			switch i {
@@ -1017,44 +1368,57 @@ func addSvr(svrIn []svrs, pass passwords) (svrOut []svrs, ret bool) {
 		if len(name) < 1 || len(url) < 1 {
 			continue
 		}
-		if len(pass.Type) > 0 && len(pass.Pass) > 0 {
-			eztools.ShowStrln("If you want to use " + pass.Type +
-				" " + pass.Pass + " configured for all servers, answer an invalid value.")
+		if len(pass.Type) > 0 && pass.configured() {
+			showStrln("If you want to use " + pass.Type +
+				" " + pass.Src + " " + pass.Pass +
+				" configured for all servers, answer an invalid value.")
 		}
-		passType, passTxt, _ := inputPass4Svr(svrType)
-		if svrType == CategoryGerrit {
-			magic = eztools.PromptStr("magic([Y/y=" +
-				MAGIC + "])")
+		passSvr, _ := inputPass4Svr(svrType, name)
+		if def := backends[svrType].Magic(); len(def) > 0 {
+			magic = promptStr("magic([Y/y=" +
+				def + "])")
 			switch magic {
 			case "y", "Y":
-				magic = MAGIC
+				magic = def
 			}
 		} else {
-			magic = eztools.PromptStr("magic")
+			magic = promptStr("magic")
 		}
 		svrOut = append(svrOut, svrs{Type: svrType,
 			Name: name, URL: url, IP: ip, Magic: magic,
-			Pass: passwords{Type: passType, Pass: passTxt}})
+			Pass: passSvr})
 		ret = true
 	}
 	return
 }
 
+// saveProj sets the default project of a server and saves config.
+// The server may be a copy of the one in config, by svrCopy.
 func saveProj(svr *svrs, proj string) bool {
-	if svr == nil && len(proj) < 1 {
+	if svr == nil {
 		return false
 	}
-	var ret bool
-	if svr.Proj != proj {
-		svr.Proj = proj
-		ret = true
-	}
-	if !ret {
+	cfgMu.Lock()
+	defer cfgMu.Unlock()
+	if svr.Proj == proj {
 		return false
 	}
+	svr.Proj = proj
+	if s := matchSvr(cfg.Svrs, svr.Name); s != nil {
+		s.Proj = proj
+	}
 	return saveCfg(false)
 }
 
+// svrCopy copies a server for actions at the same time,
+// not to share its project changed by saveProj
+func svrCopy(svr *svrs) *svrs {
+	cfgMu.Lock()
+	defer cfgMu.Unlock()
+	s := *svr
+	return &s
+}
+
 func saveCfg(creation bool) bool {
 	fun := eztools.XMLWrite
 	if !creation {
@@ -1065,7 +1429,7 @@ func saveCfg(creation bool) bool {
 		return false
 	}
 	if eztools.Debugging && eztools.Verbose > 1 {
-		eztools.ShowStrln(cfgFile + " saved.")
+		showStrln(cfgFile + " saved.")
 	}
 	return true
 }
@@ -1103,8 +1467,8 @@ func chkUpdate(eztoolscfg string, upch chan bool) {
 	if len(eztoolscfg) == 0 {
 		db, _, err = eztools.MakeDb()
 		if err != nil {
-			if /*err == os.PathErr ||*/ err == eztools.ErrNoValidResults {
-				eztools.ShowStrln("NO configuration for EZtools. Get one to auto update this app!")
+			if /*err == os.PathErr ||*/ errors.Is(err, eztools.ErrNoValidResults) {
+				showStrln("NO configuration for EZtools. Get one to auto update this app!")
 			}
 			Log(true, false, err)
 			upch <- false
@@ -1116,9 +1480,19 @@ func chkUpdate(eztoolscfg string, upch chan bool) {
 	db.AppUpgrade(db.GetTblDef(), module, Ver, nil, upch)
 }
 
-func cfg2AuthInfo(svr svrs, cfg jirrit) (authInfo eztools.AuthInfo, err error) {
+// svrAuth is auth info of a server, and headers of its requests,
+// got by cfg2AuthInfo once for all actions
+type svrAuth struct {
+	authInfo eztools.AuthInfo
+	hdr      http.Header
+}
+
+// cfg2AuthInfo gets auth info of a server,
+// and headers of its requests, of bearer tokens and config, if any
+func cfg2AuthInfo(svr svrs, cfg jirrit) (authInfo eztools.AuthInfo,
+	hdr http.Header, err error) {
 	pass := svr.Pass
-	if len(pass.Pass) < 1 {
+	if len(pass.Pass) < 1 && pass.Src != PassSrcNetrc {
 		pass = cfg.Pass
 	}
 	authInfo = eztools.AuthInfo{User: cfg.User}
@@ -1132,14 +1506,29 @@ func cfg2AuthInfo(svr svrs, cfg jirrit) (authInfo eztools.AuthInfo, err error) {
 		authInfo.Type = eztools.AuthPlain
 	case PassBasic:
 		authInfo.Type = eztools.AuthBasic
-	default: //PassToken
+	default: //PassToken, PassBearer
 		authInfo.Type = eztools.AuthNone
 		//authInfo.Pass = ""
 		//return
 	}
-	authInfo.Pass = pass.Pass
-	if authInfo.Type != eztools.AuthNone && len(authInfo.Pass) < 1 {
+	if authInfo.Pass, err = pass.get(svr); err != nil {
+		return
+	}
+	if (authInfo.Type != eztools.AuthNone || pass.Type == PassBearer) &&
+		len(authInfo.Pass) < 1 {
 		err = errors.New("NO password configured")
+		return
+	}
+	hdr = make(http.Header)
+	if pass.Type == PassBearer {
+		hdr.Set("Authorization", "Bearer "+authInfo.Pass)
+	}
+	for _, h := range svr.Headers {
+		v, err := h.get(svr)
+		if err != nil {
+			return authInfo, nil, fmt.Errorf("header %s: %w", h.Name, err)
+		}
+		hdr.Add(h.Name, v)
 	}
 	return
 }
@@ -1150,7 +1539,8 @@ func cfg2AuthInfo(svr svrs, cfg jirrit) (authInfo eztools.AuthInfo, err error) {
 category name -> []action2Func
 cat2Act
 */
-type actionFunc func(*svrs, eztools.AuthInfo, IssueInfos) (IssueInfoSlc, error)
+type actionFunc func(context.Context, *svrs, eztools.AuthInfo,
+	IssueInfos) (IssueInfoSlc, error)
 type action2Func struct {
 	n string
 	f actionFunc
@@ -1161,13 +1551,13 @@ type cat2Act map[string][]action2Func
 func isValidSvr(cats cat2Act, svr *svrs) bool {
 	if len(svr.Name) < 1 || len(svr.Type) < 1 || len(svr.URL) < 1 {
 		if eztools.Verbose > 0 {
-			eztools.LogPrint("Skipping invalid server", svr.Name)
+			Log(true, false, "Skipping invalid server", svr.Name)
 		}
 		return false
 	}
 	if _, ok := cats[svr.Type]; !ok {
 		if eztools.Verbose > 0 {
-			eztools.LogPrint("Skipping unknown type", svr.Type, "of server", svr.Name, ". must be", cats)
+			Log(true, false, "Skipping unknown type", svr.Type, "of server", svr.Name, ". must be", cats)
 		}
 		return false
 	}
@@ -1190,8 +1580,8 @@ func chooseSvr(cats cat2Act, candidates []svrs) *svrs {
 		noInteractionAllowed()
 		return nil
 	}
-	eztools.ShowStrln(" Choose a server")
-	si, _ := eztools.ChooseStrings(choices)
+	showStrln(" Choose a server")
+	si, _ := chooseStrings(choices)
 	if si == eztools.InvalidID {
 		return nil
 	}
@@ -1199,23 +1589,29 @@ func chooseSvr(cats cat2Act, candidates []svrs) *svrs {
 	return &candidates[si]
 }
 
-func makeActs2Choose(svr svrs, funcs []action2Func) []string {
-	if svr.Type == CategoryJira {
-		if len(svr.Flds.TstExp+svr.Flds.TstPre+svr.Flds.TstStep) < 1 {
-			// the last two are to be hidden from choices,
-			// if lack of configuration of Tst*
-			funcs = funcs[:len(funcs)-2]
+// makeActs2Choose filters actions hidden by the backend
+// Return values: names and functions of actions to choose from
+func makeActs2Choose(svr svrs, funcs []action2Func) ([]string, []action2Func) {
+	if b, ok := backendOf(&svr); ok {
+		if h, ok := b.(actHider); ok {
+			var shown []action2Func
+			for _, f := range funcs {
+				if !h.hideAct(svr, f.n) {
+					shown = append(shown, f)
+				}
+			}
+			funcs = shown
 		}
 	}
 	choices := make([]string, len(funcs))
 	for i, choice := range funcs {
 		choices[i] = choice.n
 	}
-	return choices
+	return choices, funcs
 }
 
-func chooseAct(svr *svrs, authInfo eztools.AuthInfo, choices []string,
-	funcs []action2Func, issueInfo IssueInfos,
+func chooseAct(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	choices []string, funcs []action2Func, issueInfo IssueInfos,
 	issueInfoPrev IssueInfoSlc) (string, actionFunc, IssueInfoSlc) {
 	var (
 		fi  int
@@ -1231,10 +1627,10 @@ func chooseAct(svr *svrs, authInfo eztools.AuthInfo, choices []string,
 	case 1:
 		Log(false, false, "only action for a server: "+choices[0])
 	default:
-		eztools.ShowStrln(" Choose an action")
+		showStrln(" Choose an action")
 		const wtFormer = "_with former results_"
 		for _, choice1 := range [...][]string{append(choices, wtFormer), choices} {
-			fi, _ = eztools.ChooseStrings(choice1)
+			fi, _ = chooseStrings(choice1)
 			if fi == eztools.InvalidID {
 				return "", nil, IssueInfoSlc{issueInfo}
 			}
@@ -1246,7 +1642,7 @@ func chooseAct(svr *svrs, authInfo eztools.AuthInfo, choices []string,
 		}
 	}
 	if issueInfo != nil {
-		if inputIssueInfo4Act(svr, authInfo, funcs[fi].n, issueInfo) {
+		if inputIssueInfo4Act(ctx, svr, authInfo, funcs[fi].n, issueInfo) {
 			return "", nil, nil
 		}
 		ret = IssueInfoSlc{issueInfo}
@@ -1254,7 +1650,9 @@ func chooseAct(svr *svrs, authInfo eztools.AuthInfo, choices []string,
 	return funcs[fi].n, funcs[fi].f, ret
 }
 
-func chkErrRest(bodyBytes []byte,
+// chkErrRest maps an error of a REST request to errConn, errAuth and others,
+// wrapped in a restErr with the status and body
+func chkErrRest(method, reqURL string, bodyBytes []byte,
 	errno int, err error) error {
 	var (
 		dnsErr *net.DNSError
@@ -1276,7 +1674,7 @@ func chkErrRest(bodyBytes []byte,
 			err = errGram
 		case http.StatusNotFound:
 			err = eztools.ErrNoValidResults
-		case http.StatusUnauthorized:
+		case http.StatusUnauthorized, http.StatusForbidden:
 			err = errAuth
 		case http.StatusGatewayTimeout:
 			err = errConn
@@ -1287,12 +1685,12 @@ func chkErrRest(bodyBytes []byte,
 	if err != nil {
 		Log(true, false, "REST error", err)
 		Log(stdOutput, false, "REST body=", string(bodyBytes))
-	} else {
-		if eztools.Debugging && eztools.Verbose > 2 {
-			Log(stdOutput, false, "REST body=", string(bodyBytes))
-		}
+		return newRestErr(method, reqURL, errno, bodyBytes, err)
 	}
-	return err
+	if eztools.Debugging && eztools.Verbose > 2 {
+		Log(stdOutput, false, "REST body=", string(bodyBytes))
+	}
+	return nil
 }
 
 // chkRespErr checks whether it is an error or log the response
@@ -1313,55 +1711,143 @@ func logReq(method, url string) {
 	}
 }
 
-func restFile(method, url string, authInfo eztools.AuthInfo,
-	fType, fName string, hdrs map[string]string,
-	magic string) (body interface{}, err error) {
-	logReq(method, url)
-	resp, err := eztools.HTTPSendAuthNHdrNFile(method, url,
-		authInfo, fType, fName, hdrs)
+// restDo sends a request by the client of ctx, with retries, and handles
+// the response by done, with the timeout of ctx, if any, for all of them
+func restDo(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, bodyReq io.Reader, bodyType string,
+	hdrs map[string]string, done func(*http.Response) error) error {
+	ctxReq, stop, err := reqCtx(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	resp, err := restSend(ctx, ctxReq, method, url, bodyReq,
+		func(method string, bodyReq io.Reader) (*http.Response, error) {
+			return clientCtx(ctx).send(ctxReq, method, url, authInfo,
+				reqHdr(ctx, hdrs), bodyReq, bodyType)
+		})
 	if chkRespErr(resp, err) {
-		return
+		return sendErr(ctx, ctxReq, method, url, err)
+	}
+	if err = done(resp); err != nil && ctxReq.Err() != nil {
+		// timed out reading the response
+		err = newRestErr(method, url, resp.StatusCode, nil,
+			context.Cause(ctxReq))
 	}
-	_, _, bodyBytes, errInt, err :=
-		eztools.HTTPParseBody(resp, "", &body, []byte(magic))
-	return body, chkErrRest(bodyBytes, errInt, err)
+	return err
 }
 
-// restAttachment sends a request and save the attachement in the response
-// parameters: method, url, authInfo, bodyReq, magic(reserved)
-func restAttachment(method, url string, authInfo eztools.AuthInfo,
-	bodyReq io.Reader, _ string) (fileName string, err error) {
-	logReq(method, url)
-	resp, err := eztools.HTTPSendAuth(method,
-		url, "", authInfo, bodyReq)
-	if chkRespErr(resp, err) {
-		return
+// sendErr wraps an error of sending a request in a restErr,
+// as errConn, or the cause of ctxReq timed out,
+// unless it is of config, or ctx is done
+func sendErr(ctx, ctxReq context.Context, method, url string,
+	err error) error {
+	switch {
+	case ctxReq.Err() != nil:
+		err = context.Cause(ctxReq)
+	case ctx.Err() != nil && errors.Is(err, context.Cause(ctx)):
+		// not sent or retried
+		return err
+	case !errors.Is(err, errCfg):
+		err = fmt.Errorf("%w: %w", errConn, err)
+	}
+	return newRestErr(method, url, 0, nil, err)
+}
+
+func restFile(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, fType, fName string, hdrs map[string]string,
+	magic string) (body interface{}, err error) {
+	if dryRunSkip(method, url, nil, fName) {
+		return nil, nil
+	}
+	bodyReq, bodyType, err := fileBody(fType, fName)
+	if err != nil {
+		return nil, err
+	}
+	err = restDo(ctx, method, url, authInfo, bodyReq, bodyType, hdrs,
+		func(resp *http.Response) error {
+			_, _, bodyBytes, errInt, err :=
+				eztools.HTTPParseBody(resp, "", &body, []byte(magic))
+			if err = chkErrRest(method, url, bodyBytes, errInt,
+				err); err == nil {
+				auditSent(ctx, method, url, nil, fName)
+			}
+			return err
+		})
+	return body, err
+}
+
+// fileBody makes a multipart body of a file, as the field of fType
+// Return values: the body and its type
+func fileBody(fType, fName string) (io.Reader, string, error) {
+	f, err := os.Open(fName)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile(fType, filepath.Base(fName))
+	if err != nil {
+		return nil, "", err
 	}
-	_, fileName, err = eztools.HTTPSaveAttachment(resp, "")
+	if _, err = io.Copy(part, f); err != nil {
+		return nil, "", err
+	}
+	if err = w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
+
+// restAttachment sends a request and save the attachement in the response
+// parameters: ctx, method, url, authInfo, bodyReq, magic(reserved)
+func restAttachment(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, bodyReq io.Reader, _ string) (
+	fileName string, err error) {
+	if dryRunSkip(method, url, bodyReq, "") {
+		return "", nil
+	}
+	bodyAudit, bodyReq := auditBody(ctx, method, bodyReq)
+	err = restDo(ctx, method, url, authInfo, bodyReq, "", nil,
+		func(resp *http.Response) (err error) {
+			if _, fileName, err = eztools.HTTPSaveAttachment(resp,
+				""); err == nil {
+				auditSent(ctx, method, url, bodyAudit, "")
+			}
+			return err
+		})
 	return fileName, err
 }
 
 // return nil for 404
-func restSth(method, url string, authInfo eztools.AuthInfo,
-	bodyReq io.Reader, magic string) (body interface{}, err error) {
-	logReq(method, url)
+func restSth(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, bodyReq io.Reader,
+	magic string) (body interface{}, err error) {
 	/*if eztools.Debugging && eztools.Verbose > 2 && bodyReq != nil {
 		Log(stdOutput, false, "resting", bodyReq)
 	}*/
-	resp, err := eztools.HTTPSendAuth(method,
-		url, "", authInfo, bodyReq)
-	if chkRespErr(resp, err) {
-		return
-	}
-	_, _, bodyBytes, errInt, err :=
-		eztools.HTTPParseBody(resp, "", &body, []byte(magic))
-	return body, chkErrRest(bodyBytes, errInt, err)
+	if dryRunSkip(method, url, bodyReq, "") {
+		return nil, nil
+	}
+	bodyAudit, bodyReq := auditBody(ctx, method, bodyReq)
+	err = restDo(ctx, method, url, authInfo, bodyReq, "", nil,
+		func(resp *http.Response) error {
+			_, _, bodyBytes, errInt, err :=
+				eztools.HTTPParseBody(resp, "", &body, []byte(magic))
+			if err = chkErrRest(method, url, bodyBytes, errInt,
+				err); err == nil {
+				auditSent(ctx, method, url, bodyAudit, "")
+			}
+			return err
+		})
+	return body, err
 }
 
-func restMap(method, url string, authInfo eztools.AuthInfo,
-	bodyReq io.Reader, magic string) (
+func restMap(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, bodyReq io.Reader, magic string) (
 	bodyMap map[string]interface{}, err error) {
-	body, err := restSth(method, url, authInfo, bodyReq, magic)
+	body, err := restSth(ctx, method, url, authInfo, bodyReq, magic)
 	if err != nil || body == nil {
 		return
 	}
@@ -1372,6 +1858,32 @@ func restMap(method, url string, authInfo eztools.AuthInfo,
 	return
 }
 
+// restHdr sends a request like restSth, for the raw body
+// and headers in the response, such as Location.
+// Return: nil body and headers in dry run
+func restHdr(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, bodyReq io.Reader,
+	magic string) (bodyBytes []byte, hdr http.Header, err error) {
+	if dryRunSkip(method, url, bodyReq, "") {
+		return nil, nil, nil
+	}
+	bodyAudit, bodyReq := auditBody(ctx, method, bodyReq)
+	err = restDo(ctx, method, url, authInfo, bodyReq, "", nil,
+		func(resp *http.Response) error {
+			hdr = resp.Header
+			var body interface{}
+			_, _, b, errInt, err :=
+				eztools.HTTPParseBody(resp, "", &body, []byte(magic))
+			bodyBytes = b
+			if err = chkErrRest(method, url, bodyBytes, errInt,
+				err); err == nil {
+				auditSent(ctx, method, url, bodyAudit, "")
+			}
+			return err
+		})
+	return bodyBytes, hdr, err
+}
+
 /*
 get all values from
 
@@ -1427,7 +1939,7 @@ func getValuesFromMaps(name string, field interface{}) string {
 		noInteractionAllowed()
 		return ""
 	}
-	if choice, _ := eztools.ChooseStrings(values); choice != eztools.InvalidID {
+	if choice, _ := chooseStrings(values); choice != eztools.InvalidID {
 		return values[choice]
 	}
 	return ""
@@ -1454,7 +1966,7 @@ func loopStringMap(m map[string]interface{},
 	}
 	for i, v := range m {
 		/*if eztools.Debugging && eztools.Verbose > 1 {
-			eztools.ShowStrln("looping " + i)
+			showStrln("looping " + i)
 		}*/
 		if len(keyStr) > 0 {
 			matched := false
@@ -1523,7 +2035,7 @@ func chkNLoopStringMap(m interface{},
 func parseIssues(issueKey string, m map[string]interface{},
 	fun func(map[string]interface{}) IssueInfos) IssueInfoSlc {
 	/*if eztools.Debugging && eztools.Verbose > 1 {
-		eztools.ShowStrln(strs)
+		showStrln(strs)
 	}*/
 	results := make(IssueInfoSlc, 0)
 	loopStringMap(m, issueKey, nil,
@@ -1713,6 +2225,22 @@ const (
 	IssueinfoStrRmvd = "removed"
 	// IssueinfoStrAdded added string for changes in bugzilla
 	IssueinfoStrAdded = "added"
+	// IssueinfoStrType type string of links for JIRA
+	IssueinfoStrType = "type"
+	// IssueinfoStrDirection direction string of links for JIRA
+	IssueinfoStrDirection = "direction"
+	// IssueinfoStrLinkID ID string of links for JIRA
+	IssueinfoStrLinkID = "link_id"
+	// IssueinfoStrToday time logged today for JIRA
+	IssueinfoStrToday = "today"
+	// IssueinfoStrWeek time logged this week for JIRA
+	IssueinfoStrWeek = "week"
+	// IssueinfoStrEstimate remaining estimate string of worklogs for JIRA
+	IssueinfoStrEstimate = "estimate"
+	// IssueinfoStrToState status string to move to for JIRA
+	IssueinfoStrToState = "to_status"
+	// IssueinfoStrFields fields string as NAME=VALUE by -s for JIRA
+	IssueinfoStrFields = "fields"
 )
 
 type IssueInfos map[string]string
@@ -1764,125 +2292,208 @@ var reviewInfoTxt = []string{
 	IssueinfoStrID, IssueinfoStrName, IssueinfoStrVerified,
 	IssueinfoStrCodereview, IssueinfoStrDispname, IssueinfoStrApprovals}
 
-// loopIssues runs a function on all numbers between, inclusively,
-// X-0 and X-1, or 0,1 from input in format of X-0,1 or 0,1
-// If it is not a range, the function's return values are returned.
-// Otherwise, no return values.
-// IssueinfoStrID is set for each loop of function fun,
-// from multiple ID's in one issueInfo,
-// while other fields use the former values returned from function fun
-func loopIssues(svr *svrs, issueInfo IssueInfos, fun func(IssueInfos) (
-	IssueInfoSlc, error)) (issueInfoOut IssueInfoSlc, err error) {
-	printID := func() {
-		if err == nil {
-			Log(false, false, "Done with "+
-				issueInfo[IssueinfoStrID])
-		}
-	}
-	//Log(false, false,strings.Count(issueInfo[IssueinfoStrID], separator))
-	switch strings.Count(issueInfo[IssueinfoStrID], issueSeparator) {
-	case 0: // single ID
-		if prefix, lowerBoundStr, _, ok := parseTypicalJiraNum(svr,
-			issueInfo[IssueinfoStrID]); ok {
-			issueInfo[IssueinfoStrID] = prefix + lowerBoundStr
-		}
-		issueInfo, err := fun(issueInfo)
-		printID()
-		return issueInfo, err
-	case 2: // x,,y or x,y,z
-		parts := strings.Split(issueInfo[IssueinfoStrID], issueSeparator)
-		//Log(false, false,parts)
-		if len(parts) != 2 || len(parts[0]) < 1 || len(parts[2]) < 1 {
-			if len(parts) != 3 {
-				Log(true, false, "range format needs both parts aside with two \""+
-					issueSeparator+"\""+" or multiple parts, deliminated by \""+
-					issueSeparator+"\"")
-				return nil, eztools.ErrInvalidInput
-			}
+// issueErr is an error of an action on one of multiple IDs
+type issueErr struct {
+	id  string
+	err error
+}
+
+func (e issueErr) Error() string {
+	return e.id + ": " + e.err.Error()
+}
+
+func (e issueErr) Unwrap() error {
+	return e.err
+}
+
+// parseIssueIDs parses input in format of X-0,,1 or 0,,1 as a range,
+// or X-0,Y-1,2 as a list.
+// Return values: all IDs, whether it is a single ID, and error
+func parseIssueIDs(svr *svrs, id string) ([]string, bool, error) {
+	parts := strings.Split(id, issueSeparator)
+	if len(parts) == 1 {
+		if prefix, num, _, ok := parseTypicalJiraNum(svr, id); ok {
+			id = prefix + num
 		}
-		if len(parts[1]) < 1 { // x,,y
-			var (
-				prefix, lowerBoundStr  string
-				lowerBound, upperBound int
-			)
-			lowerBound, err = strconv.Atoi(parts[0])
-			if err != nil {
-				var ok bool
-				if prefix, lowerBoundStr, _, ok =
-					parseTypicalJiraNum(svr, parts[0]); !ok {
-					Log(true, false, "the former"+
-						" part must be in the"+
-						" form of X-0 or 0")
-					return
-				}
-				lowerBound, err = strconv.Atoi(lowerBoundStr)
-				if err != nil {
-					Log(true, false, lowerBoundStr+
-						" is NOT a number!")
-					return
-				}
+		return []string{id}, true, nil
+	}
+	if len(parts) == 3 && len(parts[1]) < 1 { // x,,y
+		if len(parts[0]) < 1 || len(parts[2]) < 1 {
+			Log(true, false, "range format needs both parts aside with two \""+
+				issueSeparator+"\""+" or multiple parts, deliminated by \""+
+				issueSeparator+"\"")
+			return nil, false, eztools.ErrInvalidInput
+		}
+		var (
+			prefix, lowerBoundStr  string
+			lowerBound, upperBound int
+			err                    error
+		)
+		lowerBound, err = strconv.Atoi(parts[0])
+		if err != nil {
+			var ok bool
+			if prefix, lowerBoundStr, _, ok =
+				parseTypicalJiraNum(svr, parts[0]); !ok {
+				Log(true, false, "the former"+
+					" part must be in the"+
+					" form of X-0 or 0")
+				return nil, false, eztools.ErrInvalidInput
 			}
-			upperBound, err = strconv.Atoi(parts[2])
+			lowerBound, err = strconv.Atoi(lowerBoundStr)
 			if err != nil {
-				Log(true, false,
-					"the latter part must be a number")
-				return
-			}
-			if lowerBound >= upperBound {
-				Log(true, false, "the number in the latter"+
-					" part must be greater than the one"+
-					" in the former part")
-				return
+				Log(true, false, lowerBoundStr+
+					" is NOT a number!")
+				return nil, false, eztools.ErrInvalidInput
 			}
-			for i := lowerBound; i <= upperBound; i++ {
-				issueInfo[IssueinfoStrID] =
-					prefix + strconv.Itoa(i)
-				//eztools.ShowStrln("looping ",
-				//issueInfo[IssueinfoStrID])
-				if inf, err := fun(issueInfo); err == nil {
-					issueInfoOut = append(issueInfoOut,
-						inf...)
-				}
-				printID()
-			}
-			return
 		}
-		// x,y,z instead of range
+		upperBound, err = strconv.Atoi(parts[2])
+		if err != nil {
+			Log(true, false,
+				"the latter part must be a number")
+			return nil, false, eztools.ErrInvalidInput
+		}
+		if lowerBound >= upperBound {
+			Log(true, false, "the number in the latter"+
+				" part must be greater than the one"+
+				" in the former part")
+			return nil, false, eztools.ErrInvalidInput
+		}
+		ids := make([]string, 0, upperBound-lowerBound+1)
+		for i := lowerBound; i <= upperBound; i++ {
+			ids = append(ids, prefix+strconv.Itoa(i))
+		}
+		return ids, false, nil
 	}
 	// x,y[,...]
-	parts := strings.Split(issueInfo[IssueinfoStrID], issueSeparator)
 	var (
 		prefix, prefixNew, currentNo string
 		ok                           bool
 	)
-	if prefix, currentNo, _, ok = parseTypicalJiraNum(svr, parts[0]); !ok {
-		currentNo = parts[0]
+	ids := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if prefixNew, currentNo, _, ok =
+			parseTypicalJiraNum(svr, part); !ok {
+			// reuse old prefix
+			currentNo = part
+		} else {
+			prefix = prefixNew
+		}
+		ids = append(ids, prefix+currentNo)
 	}
-	i := 1
-	/*eztools.ShowStrln(prefix + "_" + currentNo)
-	eztools.ShowStrln(parts)*/
-	for {
-		issueInfo[IssueinfoStrID] = prefix + currentNo
-		//eztools.ShowStrln("looping " + issueInfo[IssueinfoStrID])
-		inf, err := fun(issueInfo)
-		if err == nil {
-			issueInfoOut = append(issueInfoOut, inf...)
-		} // let it work for the next
-		printID()
-		if i < len(parts) {
-			if prefixNew, currentNo, _, ok =
-				parseTypicalJiraNum(svr, parts[i]); !ok {
-				// reuse old prefix
-				currentNo = parts[i]
-			} else {
-				prefix = prefixNew
+	return ids, false, nil
+}
+
+// loopIssues runs a function on all numbers between, inclusively,
+// X-0 and X-1, or 0,1 from input in format of X-0,1 or 0,1
+// If it is not a range, the function's return values are returned.
+// Otherwise, results of all successful loops, and errors of failed ones.
+// IssueinfoStrID is set for each loop of function fun,
+// from multiple ID's in one issueInfo,
+// while other fields use the former values returned from function fun
+// No more loops are run after ctx is done.
+func loopIssues(ctx context.Context, svr *svrs, issueInfo IssueInfos,
+	fun func(IssueInfos) (IssueInfoSlc, error)) (IssueInfoSlc, error) {
+	return loopIssuesJobs(ctx, svr, issueInfo, 1, fun, nil)
+}
+
+// loopIssuesJobs is loopIssues running fun for jobs of IDs at a time.
+// done, if not nil, is called for each ID in order of input,
+// with results of fun.
+// If jobs is greater than 1, each loop takes a copy of issueInfo,
+// instead of the former values returned from function fun.
+func loopIssuesJobs(ctx context.Context, svr *svrs, issueInfo IssueInfos,
+	jobs int, fun func(IssueInfos) (IssueInfoSlc, error),
+	done func(IssueInfos, IssueInfoSlc, error)) (IssueInfoSlc, error) {
+	ids, single, err := parseIssueIDs(svr, issueInfo[IssueinfoStrID])
+	if err != nil {
+		return nil, err
+	}
+	if single {
+		jobs = 1
+	}
+	type result struct {
+		inf IssueInfos
+		out IssueInfoSlc
+		err error
+		// skipped is true if not run, for ctx is done
+		skipped bool
+		ch      chan struct{}
+	}
+	results := make([]result, len(ids))
+	if jobs > 1 {
+		for i := range results {
+			results[i].ch = make(chan struct{})
+		}
+		sem := make(chan struct{}, jobs)
+		go func() {
+			for i := range ids {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+				}
+				if ctx.Err() != nil {
+					for j := i; j < len(ids); j++ {
+						results[j].skipped = true
+						close(results[j].ch)
+					}
+					return
+				}
+				inf := maps.Clone(issueInfo)
+				inf[IssueinfoStrID] = ids[i]
+				results[i].inf = inf
+				go func(r *result) {
+					r.out, r.err = fun(r.inf)
+					<-sem
+					close(r.ch)
+				}(&results[i])
 			}
-			i++
+		}()
+	}
+	var (
+		issueInfoOut IssueInfoSlc
+		errs         []error
+	)
+	for i := range results {
+		r := &results[i]
+		if jobs > 1 {
+			<-r.ch
 		} else {
+			r.skipped = ctx.Err() != nil
+		}
+		if r.skipped {
+			// skipped IDs to be retried as a list
+			errs = append(errs, issueErr{strings.Join(ids[i:], ","),
+				fmt.Errorf("%d of %d IDs skipped: %w",
+					len(ids)-i, len(ids), context.Cause(ctx))})
+			if single {
+				return nil, errs[0]
+			}
 			break
 		}
+		if jobs < 2 {
+			if ids[i] != issueInfo[IssueinfoStrID] {
+				issueInfo[IssueinfoStrID] = ids[i]
+			}
+			r.inf = issueInfo
+			r.out, r.err = fun(issueInfo)
+		}
+		if done != nil {
+			done(r.inf, r.out, r.err)
+		}
+		if single {
+			if r.err == nil {
+				Log(false, false, "Done with "+ids[i])
+			}
+			return r.out, r.err
+		}
+		if r.err != nil {
+			errs = append(errs, issueErr{ids[i], r.err})
+			continue
+		}
+		issueInfoOut = append(issueInfoOut, r.out...)
+		Log(false, false, "Done with "+ids[i])
 	}
-	return
+	return issueInfoOut, errors.Join(errs...)
 }
 
 func cfmInputOrPromptStrMultiLines(inf IssueInfos, ind, prompt string) {
@@ -1891,7 +2502,7 @@ func cfmInputOrPromptStrMultiLines(inf IssueInfos, ind, prompt string) {
 		return
 	}
 	const linefeed = " (end with \\ to continue with more lines. empty to stop)"
-	s := eztools.PromptStr(prompt + linefeed)
+	s := promptStr(prompt + linefeed)
 	if len(s) < 1 {
 		return
 	}
@@ -1910,32 +2521,22 @@ func cfmInputOrPromptStr(svr *svrs, inf IssueInfos, ind, prompt string) bool {
 	const linefeed = " (end with \\ to input multi lines)"
 	var def, base string
 	var changes, smart bool // no smart affix available by default
-	if ind == IssueinfoStrID && len(inf[ind]) > 0 {
-		switch {
-		case svr.Type == CategoryBugzilla:
-			def = "=" + inf[ind]
-		case svr.Type == CategoryJira:
-			var ok bool
-			if base, _, changes, ok = parseTypicalJiraNum(svr,
-				inf[ind]); ok {
-				smart = true // there is a reference for smart affix
-				//eztools.ShowStrln("not int previously")
-			}
-			def = "=" + inf[ind]
-		}
-	}
-	s := eztools.PromptStr(prompt + linefeed + def)
+	b, _ := backendOf(svr)
+	reuser, reusing := b.(idReuser)
+	if ind == IssueinfoStrID && len(inf[ind]) > 0 && reusing {
+		// there is a reference for smart affix
+		base, changes, smart = reuser.parseID(svr, inf[ind])
+		def = "=" + inf[ind]
+	}
+	s := promptStr(prompt + linefeed + def)
 	if len(s) < 1 || s == inf[ind] {
 		return false
 	}
-	if ind == IssueinfoStrID {
-		switch svr.Type {
-		case CategoryJira:
-			if sChg, ok := changeTypicalJiraNum(svr, s, base,
-				smart, changes); ok {
-				inf[ind] = sChg
-				return true
-			}
+	if ind == IssueinfoStrID && reusing {
+		if sChg, ok := reuser.changeID(svr, s, base,
+			smart, changes); ok {
+			inf[ind] = sChg
+			return true
 		}
 	}
 	// input not a number or no previous input to refer to
@@ -1970,8 +2571,8 @@ func useInputOrPrompt(svr *svrs, inf IssueInfos, ind string) bool {
 // useInputOrPrompt4ID lists open cases to choose from
 // Parameters: fun=function to list issues for user to choose from
 // Return value: true=no ID input; false=sth. input
-func useInputOrPrompt4ID(svr *svrs, authInfo eztools.AuthInfo,
-	issueInfo IssueInfos) bool {
+func useInputOrPrompt4ID(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos) bool {
 	/*switch svr.Type {
 	case CategoryGerrit:
 		defer gerritAnyID2ID(svr, authInfo, issueInfo)
@@ -1985,27 +2586,15 @@ func useInputOrPrompt4ID(svr *svrs, authInfo eztools.AuthInfo,
 	}
 	var (
 		strIndCmp, strIndSum string
-		listFunc             func(svr *svrs, authInfo eztools.AuthInfo,
-			issueInfo IssueInfos) (IssueInfoSlc, error)
+		listFunc             actionFunc
 	)
-	switch svr.Type {
-	case CategoryJira:
-		listFunc = JiraMyOpen
-		strIndCmp = IssueinfoStrProj
-		strIndSum = IssueinfoStrSummary
-	case CategoryBugzilla:
-		listFunc = BugzillaMyOpen
-		strIndCmp = IssueinfoStrProj
-		strIndSum = IssueinfoStrSummary
-	case CategoryGerrit:
-		listFunc = GerritMyOpen
-		strIndCmp = IssueinfoStrBranch
-		strIndSum = IssueinfoStrSubject
+	if b, ok := backendOf(svr); ok {
+		listFunc, strIndCmp, strIndSum = b.ListMine()
 	}
 	if listFunc == nil {
 		useInputOrPrompt(svr, issueInfo, IssueinfoStrID)
 	} else {
-		slc, err := listFunc(svr, authInfo, issueInfo)
+		slc, err := listFunc(ctx, svr, authInfo, issueInfo)
 		var choices []string
 		if err == nil && len(slc) > 0 {
 			for _, v := range slc {
@@ -2015,7 +2604,7 @@ func useInputOrPrompt4ID(svr *svrs, authInfo eztools.AuthInfo,
 						v[strIndCmp]+" :\t"+
 						v[strIndSum])
 			}
-			i, s := eztools.ChooseStrings(choices)
+			i, s := chooseStrings(choices)
 			if i == eztools.InvalidID {
 				issueInfo[IssueinfoStrID] = s
 			} else {
@@ -2032,245 +2621,24 @@ func useInputOrPrompt4ID(svr *svrs, authInfo eztools.AuthInfo,
 	return false
 }
 
-// inputIssueInfo4JB is inputIssueInfo4Act for Jira and Bugzilla
-func inputIssueInfo4JB(svr *svrs, authInfo eztools.AuthInfo,
-	action string, inf IssueInfos) bool {
-	switch action {
-	case "close a case to resolved from any known statuses":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPrompt(svr, inf, IssueinfoStrComments)
-		switch svr.Type {
-		case CategoryJira:
-			useInputOrPromptStr(svr, inf, IssueinfoStrLink,
-				"test step for closure")
-		}
-	case "move status of a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		strCmt := IssueinfoStrComments
-		switch svr.Type {
-		case CategoryBugzilla:
-			strCmt += " (added to all statues during transition)"
-		}
-		useInputOrPromptStr(svr, inf, IssueinfoStrComments, strCmt)
-	case "close a case with default design as steps",
-		"close a case with general requirement as steps":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPrompt(svr, inf, IssueinfoStrComments)
-	case "show details of a case",
-		"list comments of a case",
-		"list files attached to a case",
-		"list watchers of a case",
-		"check whether watching a case",
-		"watch a case",
-		"unwatch a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-	case "link a case to another":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPromptStr(svr, inf, IssueinfoStrLink,
-			"ID (not indexes above, if any) this issue blocks")
-	case "remove a file attached to a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrKey, "file ID")
-	case "add a file to a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPrompt(svr, inf, IssueinfoStrFile)
-		switch svr.Type {
-		case CategoryBugzilla:
-			useInputOrPromptStr(svr, inf,
-				IssueinfoStrKey, "description")
-		}
-	case "get a file to a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrKey, "file ID")
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrFile, "file to be saved as")
-	case "change a comment from a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrKey, "comment ID")
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrComments, "comment body")
-	case "delete a comment from a case":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrKey, "comment ID")
-	case "add a comment to a case":
-		if useInputOrPrompt(svr, inf, IssueinfoStrComments) {
-			return true
-		}
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-	case "reject a case from any known statuses":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPrompt(svr, inf, IssueinfoStrComments)
-	case "transfer a case to someone":
-		if useInputOrPrompt4ID(svr, authInfo, inf) {
-			return true
-		}
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrSummary, "assignee")
-		useInputOrPromptStr(svr, inf,
-			IssueinfoStrComments, "component")
-	}
-	return false
-}
-
 // inputIssueInfo4Act asks for input specific to the action and server type, and update
 // inf accordingly. Return true if not enough info is given, false otherwise.
-func inputIssueInfo4Act(svr *svrs, authInfo eztools.AuthInfo,
-	action string, inf IssueInfos) bool {
-	switch svr.Type {
-	case CategoryGerrit:
-		switch action {
-		case "rebase a submit",
-			"revert a submit",
-			"abandon a submit",
-			"show reviewers and scores of a submit",
-			"add scores to a submit",
-			"show revisions of a submit",
-			"show history of a submit":
-			if useInputOrPrompt4ID(svr, authInfo, inf) {
-				return true
-			}
-		case "list files of a submit by revision":
-			if useInputOrPrompt4ID(svr, authInfo, inf) {
-				return true
-			}
-			useInputOrPromptStr(svr, inf, IssueinfoStrRevCur,
-				"revision(empty for current)")
-		case "download a file of a submit":
-			if useInputOrPrompt4ID(svr, authInfo, inf) {
-				return true
-			}
-			useInputOrPromptStr(svr, inf, IssueinfoStrRevCur,
-				"revision(empty for current)")
-			useInputOrPrompt(svr, inf, IssueinfoStrFile)
-		case "cherry pick a submit":
-			if useInputOrPrompt4ID(svr, authInfo, inf) {
-				return true
-			}
-			useInputOrPromptStr(svr, inf, IssueinfoStrRevCur,
-				"revision(empty for current)")
-			useInputOrPrompt(svr, inf, IssueinfoStrBranch)
-			if len(inf[IssueinfoStrBranch]) < 1 {
-				return true
-			}
-		}
-	case CategoryJira, CategoryBugzilla:
-		return inputIssueInfo4JB(svr, authInfo, action, inf)
-	case CategoryJenkins:
-		switch action {
-		case "list jobs", "list builds":
-			useInputOrPromptStr(svr, inf, IssueinfoStrSize,
-				"max number of results")
-		case "get log of a build":
-			useInputOrPromptStr(svr, inf, IssueinfoStrFile,
-				"log file name to save as")
-		}
-	default:
+func inputIssueInfo4Act(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, action string, inf IssueInfos) bool {
+	b, ok := backendOf(svr)
+	if !ok {
 		Log(true, false, "Server type unknown: "+svr.Type)
 		return true
 	}
 	//eztools.ShowStrln(inf)
-	return false
+	return b.Input(ctx, svr, authInfo, action, inf)
 }
 
+// makeCat2Act collects actions of all registered backends
 func makeCat2Act() cat2Act {
-	return cat2Act{
-		CategoryJira: []action2Func{
-			{"transfer a case to someone", JiraTransfer},
-			{"move status of a case", JiraTransition},
-			{"show details of a case", JiraDetail},
-			{"list comments of a case", JiraComments},
-			{"add a comment to a case", JiraAddComment},
-			{"delete a comment from a case", JiraDelComment},
-			{"change a comment from a case", JiraModComment},
-			{"list my open cases", JiraMyOpen},
-			{"link a case to another", JiraLink},
-			{"list watchers of a case", JiraWatcherList},
-			{"check whether watching a case", JiraWatcherCheck},
-			{"watch a case", JiraWatcherAdd},
-			{"unwatch a case", JiraWatcherDel},
-			{"add a file to a case", JiraAddFile},
-			{"list files attached to a case", JiraListFile},
-			{"get a file to a case", JiraGetFile},
-			{"remove a file attached to a case", JiraDelFile},
-			{"reject a case from any known statuses", JiraReject},
-			{"close a case to resolved from any known statuses", JiraClose},
-			// the last two are to be hidden from choices,
-			// if lack of configuration of Tst*
-			{"close a case with default design as steps", JiraCloseDef},
-			{"close a case with general requirement as steps", JiraCloseGen}},
-		CategoryGerrit: []action2Func{
-			{"list merged submits of someone", GerritSbMerged},
-			{"list my open submits", GerritMyOpen},
-			{"list sbs open submits", GerritSbOpen},
-			{"list all open submits", GerritAllOpen},
-			{"list my open commits", GerritMyOpenCmts},
-			{"show details of a submit", GerritDetailOnCurrRev},
-			{"show revisions of a submit", GerritRevs},
-			{"show history of a submit", GerritHistory},
-			{"show reviewers and scores of a submit", GerritReviews},
-			{"show current revision or commit of a submit", GerritRev},
-			{"rebase a submit", GerritRebase},
-			{"merge a submit", GerritMerge},
-			{"show related submits of one", GerritRelated},
-			{"add scores to a submit", GerritScore},
-			{"add scores, wait for it to be mergable and merge a submit", GerritWaitNMerge},
-			{"wait for mergable and merge sbs submits", GerritWaitNMergeSb},
-			{"abandon all my open submits", GerritAbandonMyOpen},
-			{"abandon a submit", GerritAbandon},
-			{"cherry pick all my open submits", GerritPickMyOpen},
-			{"cherry pick a submit", GerritPick},
-			{"revert a submit", GerritRevert},
-			{"list files of a submit by revision", GerritListFilesByRev},
-			{"list config of a project", GerritListPrj},
-			{"download a file of a submit", GerritGetFile}},
-		CategoryJenkins: []action2Func{
-			{"list jobs", JenkinsListJobs},
-			{"show details of a build", JenkinsDetailOnBld},
-			{"get log of a build", JenkinsLogOfBld},
-			{"list builds", JenkinsListBlds}},
-		CategoryBugzilla: []action2Func{
-			{"transfer a case to someone", BugzillaTransfer},
-			{"move status of a case", BugzillaTransition},
-			{"show details of a case", BugzillaDetail},
-			{"list comments of a case", BugzillaComments},
-			{"add a comment to a case", BugzillaAddComment},
-			{"list my open cases", BugzillaMyOpen},
-			{"link a case to another", BugzillaLink},
-			{"list watchers of a case", BugzillaWatcherList},
-			{"watch a case", BugzillaWatcherAdd},
-			{"unwatch a case", BugzillaWatcherDel},
-			{"add a file to a case", BugzillaAddFile},
-			{"list files attached to a case", BugzillaListFile},
-			{"get a file to a case", BugzillaGetFile},
-			{"reject a case from any known statuses", BugzillaReject},
-			{"close a case to resolved from any known statuses", BugzillaClose},
-		}}
+	cats := make(cat2Act, len(backends))
+	for tp, b := range backends {
+		cats[tp] = b.Actions()
+	}
+	return cats
 }
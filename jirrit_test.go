@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"slices"
+	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"gitee.com/bon-ami/eztools/v6"
 	"github.com/stretchr/testify/suite"
@@ -66,7 +71,7 @@ func (s *TestSuite) SetupTest() {
 			s.Skip("no server specified")
 		}
 		var err error
-		s.authInfo, err = cfg2AuthInfo(*s.svr, cfg)
+		s.authInfo, _, err = cfg2AuthInfo(*s.svr, cfg)
 		if err != nil {
 			s.Skip("NO password configured for", infoSep, s.svr.Name, infoSep, err)
 		}
@@ -96,7 +101,8 @@ func (s *TestSuite) SetupTest() {
 func (s *TestSuite) Test1() {
 	looper := DefLooper{para: ParamsTest, svr: s.svr,
 		authInfo: s.authInfo, maxResults: maxResults}
-	errs := LoopActions(s.svr, s.funs, s.issueInfo, &looper, nil)
+	errs := LoopActions(context.Background(), s.svr, s.funs, s.issueInfo,
+		&looper, nil)
 	if errs != nil {
 		// Log(false, false, err)
 		for _, err1 := range errs {
@@ -133,6 +139,173 @@ func TestSave(t *testing.T) {
 	}
 }
 
+func TestParseIssueIDs(t *testing.T) {
+	svr := svrs{Proj: "X"}
+	for _, c := range []struct {
+		in     string
+		ids    []string
+		single bool
+		err    error
+	}{
+		{"X-1", []string{"X-1"}, true, nil},
+		{"X-1,,3", []string{"X-1", "X-2", "X-3"}, false, nil},
+		{"1,,2", []string{"1", "2"}, false, nil},
+		{"X-1,Y-2,3", []string{"X-1", "Y-2", "Y-3"}, false, nil},
+		{"X-3,,1", nil, false, eztools.ErrInvalidInput},
+		{",,1", nil, false, eztools.ErrInvalidInput},
+	} {
+		ids, single, err := parseIssueIDs(&svr, c.in)
+		if !slices.Equal(ids, c.ids) || single != c.single || !errors.Is(err, c.err) {
+			t.Error(c.in, "parsed as", ids, single, err)
+		}
+	}
+}
+
+func TestLoopIssuesJobs(t *testing.T) {
+	svr := svrs{Proj: "X"}
+	errOdd := errors.New("odd")
+	for _, jobs := range []int{1, 3} {
+		var (
+			done []string
+			mu   sync.Mutex
+			run  int
+		)
+		out, err := loopIssuesJobs(context.Background(), &svr,
+			IssueInfos{IssueinfoStrID: "X-1,,6"}, jobs,
+			func(inf IssueInfos) (IssueInfoSlc, error) {
+				n, _ := strconv.Atoi(inf[IssueinfoStrID][2:])
+				mu.Lock()
+				run++
+				if run > jobs {
+					t.Error("more than", jobs, "at a time")
+				}
+				mu.Unlock()
+				// later ones finish earlier
+				time.Sleep(time.Duration(7-n) * time.Millisecond)
+				mu.Lock()
+				run--
+				mu.Unlock()
+				if n%2 == 1 {
+					return nil, errOdd
+				}
+				return IssueInfoSlc{{IssueinfoStrID: inf[IssueinfoStrID]}}, nil
+			},
+			func(inf IssueInfos, _ IssueInfoSlc, _ error) {
+				done = append(done, inf[IssueinfoStrID])
+			})
+		if !slices.Equal(done, []string{"X-1", "X-2", "X-3", "X-4", "X-5", "X-6"}) {
+			t.Error(jobs, "jobs done in order of", done)
+		}
+		if len(out) != 3 || out[0][IssueinfoStrID] != "X-2" {
+			t.Error(jobs, "jobs results", out)
+		}
+		errs, ok := err.(interface{ Unwrap() []error })
+		if !ok || len(errs.Unwrap()) != 3 || !errors.Is(err, errOdd) {
+			t.Error(jobs, "jobs errors", err)
+		}
+	}
+}
+
+func TestLoopIssuesCancel(t *testing.T) {
+	svr := svrs{Proj: "X"}
+	for jobs, exp := range map[int][]string{
+		1: {"X-1", "X-2"},
+		3: {"X-1", "X-2", "X-3"}} {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		var (
+			run, done []string
+			mu        sync.Mutex
+		)
+		_, err := loopIssuesJobs(ctx, &svr,
+			IssueInfos{IssueinfoStrID: "X-1,,6"}, jobs,
+			func(inf IssueInfos) (IssueInfoSlc, error) {
+				mu.Lock()
+				run = append(run, inf[IssueinfoStrID])
+				mu.Unlock()
+				if inf[IssueinfoStrID] == "X-2" {
+					cancel(errIntr)
+				} else {
+					// not to free a job before cancellation
+					time.Sleep(20 * time.Millisecond)
+				}
+				return IssueInfoSlc{inf}, nil
+			},
+			func(inf IssueInfos, _ IssueInfoSlc, _ error) {
+				done = append(done, inf[IssueinfoStrID])
+			})
+		slices.Sort(run)
+		if !slices.Equal(run, exp) || !slices.Equal(done, exp) {
+			t.Error(jobs, "jobs run", run, "and done", done, "instead of", exp)
+		}
+		if !errors.Is(err, errIntr) {
+			t.Error(jobs, "jobs interrupted with", err)
+		}
+	}
+}
+
+func TestLoopActionsInput(t *testing.T) {
+	svr := svrs{Name: "fake", Type: CategoryJira}
+	var got []string
+	record := func(_ context.Context, _ *svrs, _ eztools.AuthInfo,
+		inf IssueInfos) (IssueInfoSlc, error) {
+		got = append(got, inf[IssueinfoStrID])
+		return IssueInfoSlc{{IssueinfoStrID: inf[IssueinfoStrID] + "0"}}, nil
+	}
+	looper := DefLooper{svr: &svr, maxResults: -1, quiet: true}
+	errs := LoopActions(context.Background(), &svr,
+		[]action2Func{{"first", record}, {"second", record}},
+		IssueInfos{IssueinfoStrID: "X-1"}, &looper, nil)
+	// the first takes the input and the second results of the first
+	if errs != nil || !slices.Equal(got, []string{"X-1", "X-10"}) {
+		t.Error("X-1 and X-10 expected, got", got, errs)
+	}
+}
+
+func TestFields(t *testing.T) {
+	for _, pairs := range [][]string{{"a=1", "b+=2"}, {"a=1\n2", "b=3"}} {
+		inf := mkIssueinfo(params{s: pairs})
+		if got := fieldsOf(inf); !slices.Equal(got, pairs) {
+			t.Error(pairs, "expected, got", got, "of", inf)
+		}
+	}
+	if got := fieldsOf(IssueInfos{IssueinfoStrFields: "a=1\n\nb=2\n"}); !slices.Equal(got,
+		[]string{"a=1", "b=2"}) {
+		t.Error("2 lines expected, got", got)
+	}
+}
+
+// TestLoopActionsJobs runs jobs changing the project of the server,
+// to be run with -race
+func TestLoopActionsJobs(t *testing.T) {
+	silent, svrsCfg := uiSilent, cfg.Svrs
+	defer func() {
+		uiSilent, cfg.Svrs = silent, svrsCfg
+	}()
+	uiSilent = true
+	cfg.Svrs = []svrs{{Name: "fake", Type: CategoryJira, Proj: "X"}}
+	svr := &cfg.Svrs[0]
+	parse := func(_ context.Context, svr *svrs, _ eztools.AuthInfo,
+		inf IssueInfos) (IssueInfoSlc, error) {
+		ids, _, err := parseIssueIDs(svr, inf[IssueinfoStrID])
+		return IssueInfoSlc{{IssueinfoStrID: ids[0],
+			IssueinfoStrProj: svr.Proj}}, err
+	}
+	looper := DefLooper{para: params{j: 4}, svr: svr, maxResults: -1,
+		quiet: true}
+	errs := LoopActions(context.Background(), svr,
+		[]action2Func{{"parse", parse}},
+		IssueInfos{IssueinfoStrID: "A-1,B-1,C-1,D-1"}, &looper, nil)
+	res := looper.GetIssueInfo()
+	if errs != nil || len(res) != 4 {
+		t.Fatal("4 results expected, got", res, errs)
+	}
+	for _, r := range res {
+		if r[IssueinfoStrProj]+"-1" != r[IssueinfoStrID] {
+			t.Error("project of its own expected, got", r)
+		}
+	}
+}
+
 func init() {
 	ParamsTest.Declare()
 }
@@ -143,8 +316,9 @@ func TestMain(m *testing.M) {
 	var errs []error
 	cfgFile, errs = eztools.XMLReadDefault(ParamsTest.cfg, "", "", "", module, &cfg)
 	if errs != nil {
-		eztools.ShowStrln("NO config file for tests")
-		os.Exit(0)
+		// tests against fake servers need no config
+		showStrln("NO config file for tests. Only offline ones to run.")
+		cfgFile = ""
 	}
 	actionsAll = makeCat2Act()
 	os.Exit(m.Run())
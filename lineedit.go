@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// keys of a terminal in raw mode
+const (
+	keyCtrlA  = 1
+	keyCtrlC  = 3
+	keyCtrlD  = 4
+	keyCtrlE  = 5
+	keyBS     = 8
+	keyTab    = 9
+	keyLF     = 10
+	keyCR     = 13
+	keyCtrlU  = 21
+	keyEsc    = 27
+	keyDelete = 127
+)
+
+// errLineIntr is returned by readLine on Ctrl-C, with the line dropped
+var errLineIntr = errors.New("line interrupted")
+
+// lineEd edits a line in a terminal in raw mode, with history and completion
+type lineEd struct {
+	in  io.Reader
+	out io.Writer
+	// history are lines entered before, the latest last
+	history []string
+	// complete completes a line, and returns candidates to show,
+	// if more than one
+	complete func(line string) (string, []string)
+
+	line []rune
+	pos  int
+	// prompt is shown before the line
+	prompt string
+}
+
+// readByte reads one byte, without buffering more,
+// for prompts of actions to read the rest
+func (e *lineEd) readByte() (byte, error) {
+	var b [1]byte
+	for {
+		n, err := e.in.Read(b[:])
+		if n > 0 {
+			return b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// redraw shows the prompt and the line, with the cursor at pos
+func (e *lineEd) redraw() {
+	fmt.Fprint(e.out, "\r"+e.prompt+string(e.line)+"\x1b[K")
+	if back := len(e.line) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+// set replaces the line, with the cursor at its end
+func (e *lineEd) set(s string) {
+	e.line = []rune(s)
+	e.pos = len(e.line)
+}
+
+// readLine reads a line, with the prompt shown before it.
+// Return values: the line, and io.EOF on Ctrl-D of an empty line,
+// or errLineIntr on Ctrl-C
+func (e *lineEd) readLine(prompt string) (string, error) {
+	e.prompt = prompt
+	e.set("")
+	e.redraw()
+	// hist is the index of the history line shown, and draft the line
+	// being edited before moving through history
+	hist, draft := len(e.history), ""
+	var pending []byte
+	for {
+		b, err := e.readByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case keyCR, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.line), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errLineIntr
+		case keyCtrlD:
+			if len(e.line) < 1 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.del(e.pos)
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.line)
+		case keyCtrlU:
+			e.line = e.line[e.pos:]
+			e.pos = 0
+		case keyBS, keyDelete:
+			if e.pos > 0 {
+				e.pos--
+				e.del(e.pos)
+			}
+		case keyTab:
+			e.tab()
+		case keyEsc:
+			seq, err := e.readEsc()
+			if err != nil {
+				return "", err
+			}
+			switch seq {
+			case "[A", "OA": // up
+				if hist > 0 {
+					if hist == len(e.history) {
+						draft = string(e.line)
+					}
+					hist--
+					e.set(e.history[hist])
+				}
+			case "[B", "OB": // down
+				if hist < len(e.history) {
+					hist++
+					if hist == len(e.history) {
+						e.set(draft)
+					} else {
+						e.set(e.history[hist])
+					}
+				}
+			case "[C", "OC": // right
+				e.pos = min(e.pos+1, len(e.line))
+			case "[D", "OD": // left
+				e.pos = max(e.pos-1, 0)
+			case "[H", "OH", "[1~":
+				e.pos = 0
+			case "[F", "OF", "[4~":
+				e.pos = len(e.line)
+			case "[3~":
+				e.del(e.pos)
+			}
+		default:
+			if b < ' ' {
+				continue
+			}
+			pending = append(pending, b)
+			if !utf8.FullRune(pending) {
+				continue
+			}
+			r, _ := utf8.DecodeRune(pending)
+			pending = pending[:0]
+			e.line = append(e.line[:e.pos],
+				append([]rune{r}, e.line[e.pos:]...)...)
+			e.pos++
+		}
+		e.redraw()
+	}
+}
+
+// del deletes the rune at i, if any
+func (e *lineEd) del(i int) {
+	if i < len(e.line) {
+		e.line = append(e.line[:i], e.line[i+1:]...)
+	}
+}
+
+// readEsc reads an escape sequence after ESC, as "[A"
+func (e *lineEd) readEsc() (string, error) {
+	var seq []byte
+	for {
+		b, err := e.readByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, b)
+		// final bytes of CSI and SS3 sequences
+		if len(seq) > 1 && b >= '@' && b <= '~' {
+			return string(seq), nil
+		}
+		if len(seq) == 1 && b != '[' && b != 'O' {
+			return string(seq), nil
+		}
+	}
+}
+
+// tab completes the line before the cursor, and shows candidates
+func (e *lineEd) tab() {
+	if e.complete == nil {
+		return
+	}
+	before := string(e.line[:e.pos])
+	completed, candidates := e.complete(before)
+	if len(candidates) > 1 {
+		fmt.Fprint(e.out, "\r\n"+strings.Join(candidates, "\r\n")+"\r\n")
+	}
+	if completed != before {
+		after := e.line[e.pos:]
+		e.set(completed)
+		e.line = append(e.line, after...)
+	}
+}
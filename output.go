@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// OutText human readable output, as "key=value" lines
+	OutText = "text"
+	// OutJSON one JSON array of all results, written when all done
+	OutJSON = "json"
+	// OutNDJSON one JSON object per line for each result
+	OutNDJSON = "ndjson"
+	// OutCSV comma separated values with a header for each action
+	OutCSV = "csv"
+	// OutTable aligned columns with a header for each action
+	OutTable = "table"
+)
+
+// outFmts are all supported output formats, the first being the default
+var outFmts = []string{OutText, OutJSON, OutNDJSON, OutCSV, OutTable}
+
+// outRecord is one result in machine readable output formats
+type outRecord struct {
+	Action  string     `json:"action"`
+	InputID string     `json:"input_id"`
+	Index   int        `json:"index"`
+	Fields  IssueInfos `json:"fields"`
+}
+
+var (
+	outFmt              = OutText
+	outWriter io.Writer = os.Stdout
+	// outRecs buffers results for OutJSON, or the current block of OutTable
+	outRecs []outRecord
+	// outAction and outCols are of the current block for OutCSV
+	outAction string
+	outCols   []string
+	// outColsPrefix columns before any fields for OutCSV and OutTable
+	outColsPrefix = []string{"action", "input_id", "index"}
+)
+
+// caseInfoTxt a case of Jira or Bugzilla
+var caseInfoTxt = []string{
+	IssueinfoStrID, IssueinfoStrProj, IssueinfoStrState,
+	IssueinfoStrDispname, IssueinfoStrSummary}
+
+// outColumns maps actions to columns for OutCSV and OutTable.
+// Actions not listed here use all keys found, in alphabetical order.
+var outColumns = map[string][]string{
+	"list my open cases":                          caseInfoTxt,
+	"show details of a case":                      caseInfoTxt,
+	"list merged submits of someone":              issueInfoTxt,
+	"list my open submits":                        issueInfoTxt,
+	"list sbs open submits":                       issueInfoTxt,
+	"list all open submits":                       issueInfoTxt,
+	"rebase a submit":                             issueInfoTxt,
+	"merge a submit":                              issueInfoTxt,
+	"abandon a submit":                            issueInfoTxt,
+	"abandon all my open submits":                 issueInfoTxt,
+	"restore a submit":                            issueInfoTxt,
+	"cherry pick a submit":                        issueInfoTxt,
+	"cherry pick all my open submits":             issueInfoTxt,
+	"revert a submit":                             issueInfoTxt,
+	"show details of a submit":                    issueDetailsTxt,
+	"show history of a submit":                    append(issueHistoryTxt, IssueinfoStrAuthor),
+	"show revisions of a submit":                  append(issueRev1Txt, IssueinfoStrRevCur),
+	"show current revision or commit of a submit": append(issueRevsTxt, IssueinfoStrNmb, IssueinfoStrCherry),
+	"list my open commits":                        append(issueRevsTxt, IssueinfoStrNmb, IssueinfoStrCherry),
+	"show reviewers and scores of a submit":       reviewInfoTxt,
+}
+
+// setOutFmt checks and sets the output format.
+// For machine readable formats, standard output is taken over by results,
+// so that logs and prompts go to standard error.
+func setOutFmt(f string) error {
+	if len(f) < 1 {
+		f = OutText
+	}
+	if !slices.Contains(outFmts, f) {
+		Log(true, false, "output format must be one of", outFmts)
+		return eztools.ErrInvalidInput
+	}
+	outFmt = f
+	if outFmt != OutText {
+		logWriter = os.Stderr
+	}
+	return nil
+}
+
+// outColumns4 returns columns of an action for records
+func outColumns4(action string, recs []outRecord) []string {
+	switch {
+	case action == outGroupAction:
+		return []string{outGroupBy, outGroupCount}
+	case len(outFields) > 0:
+		return outFields
+	}
+	if cols, ok := outColumns[action]; ok {
+		return cols
+	}
+	var cols []string
+	for _, rec := range recs {
+		for k := range rec.Fields {
+			if !slices.Contains(cols, k) {
+				cols = append(cols, k)
+			}
+		}
+	}
+	slices.Sort(cols)
+	return cols
+}
+
+// outRow makes values of a record for OutCSV and OutTable
+func outRow(rec outRecord, cols []string) []string {
+	row := []string{rec.Action, rec.InputID, strconv.Itoa(rec.Index)}
+	for _, col := range cols {
+		row = append(row, rec.Fields[col])
+	}
+	return row
+}
+
+// outWrite renders results of one action on one input ID
+// of a server type, or counts them by outGroupBy
+func outWrite(svrType, action string, recs []outRecord) {
+	if len(outGroupBy) > 0 {
+		outGroup(recs)
+		return
+	}
+	outRender(svrType, action, outProject(recs))
+}
+
+// outRender renders results in format of outFmt, or by a template
+func outRender(svrType, action string, recs []outRecord) {
+	if t := outTmplOf(svrType, action); t != nil {
+		outWriteTmpl(t, recs)
+		return
+	}
+	switch outFmt {
+	case OutText:
+		for _, rec := range recs {
+			Log(true, false, "Issue/Reviewer/Comment/File",
+				rec.Index, "(input ID:", rec.InputID, ")")
+			for _, k := range outKeys(action, rec.Fields) {
+				Log(true, false, "\t", k+"="+
+					strings.ReplaceAll(rec.Fields[k], "\n", "\n\t\t"))
+			}
+		}
+	case OutNDJSON:
+		enc := json.NewEncoder(outWriter)
+		for _, rec := range recs {
+			if err := enc.Encode(rec); err != nil {
+				Log(true, false, err)
+			}
+		}
+	case OutJSON:
+		outRecs = append(outRecs, recs...)
+	case OutCSV:
+		if len(recs) < 1 {
+			return
+		}
+		cols := outColumns4(action, recs)
+		w := csv.NewWriter(outWriter)
+		if outAction != action || !slices.Equal(cols, outCols) {
+			outAction, outCols = action, cols
+			w.Write(append(outColsPrefix, cols...))
+		}
+		for _, rec := range recs {
+			w.Write(outRow(rec, cols))
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			Log(true, false, err)
+		}
+	case OutTable:
+		if len(recs) < 1 {
+			return
+		}
+		if len(outRecs) > 0 && outRecs[0].Action != action {
+			outFlushTbl()
+		}
+		outRecs = append(outRecs, recs...)
+	}
+}
+
+// outFlushTbl writes buffered results of one action as a table
+func outFlushTbl() {
+	if len(outRecs) < 1 {
+		return
+	}
+	cols := outColumns4(outRecs[0].Action, outRecs)
+	w := tabwriter.NewWriter(outWriter, 0, 4, 2, ' ', 0)
+	cell := strings.NewReplacer("\t", " ", "\r", "", "\n", "\\n")
+	io.WriteString(w, strings.Join(append(outColsPrefix, cols...), "\t")+"\n")
+	for _, rec := range outRecs {
+		row := outRow(rec, cols)
+		for i := range row {
+			row[i] = cell.Replace(row[i])
+		}
+		io.WriteString(w, strings.Join(row, "\t")+"\n")
+	}
+	if err := w.Flush(); err != nil {
+		Log(true, false, err)
+	}
+	outRecs = nil
+}
+
+// outFlush writes all buffered results. It is to be called when all done.
+func outFlush() {
+	if len(outGroupBy) > 0 {
+		outFlushGroups()
+	}
+	if outFormat != nil {
+		return
+	}
+	switch outFmt {
+	case OutJSON:
+		if outRecs == nil {
+			outRecs = []outRecord{}
+		}
+		enc := json.NewEncoder(outWriter)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(outRecs); err != nil {
+			Log(true, false, err)
+		}
+		outRecs = nil
+	case OutTable:
+		outFlushTbl()
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// outTest renders results in a format and restores output settings
+func outTest(t *testing.T, format string, issues IssueInfoSlc,
+	actions ...string) string {
+	var buf bytes.Buffer
+	fmtOld, writerOld := outFmt, outWriter
+	defer func() {
+		outFmt, outWriter = fmtOld, writerOld
+		outRecs, outAction, outCols = nil, "", nil
+	}()
+	outFmt, outWriter = format, &buf
+	for _, action := range actions {
+		issues.Print(CategoryJira, action, "X-1", "", "", "")
+	}
+	outFlush()
+	return buf.String()
+}
+
+var outTestIssues = IssueInfoSlc{
+	{IssueinfoStrID: "X-1", IssueinfoStrState: "Open",
+		IssueinfoStrSummary: "first, with comma"},
+	{},
+	{IssueinfoStrID: "X-2", IssueinfoStrState: "Closed",
+		IssueinfoStrSummary: "second\nline"},
+}
+
+func TestOutJSON(t *testing.T) {
+	var recs []outRecord
+	out := outTest(t, OutJSON, outTestIssues,
+		"list my open cases", "show details of a case")
+	if err := json.Unmarshal([]byte(out), &recs); err != nil {
+		t.Fatal(err, out)
+	}
+	if len(recs) != 4 {
+		t.Fatal("4 records expected, got", len(recs))
+	}
+	if recs[1].Index != 3 || recs[1].Fields[IssueinfoStrID] != "X-2" ||
+		recs[2].Action != "show details of a case" {
+		t.Error("unexpected records", recs)
+	}
+	if out = outTest(t, OutJSON, nil, "list my open cases"); strings.TrimSpace(out) != "[]" {
+		t.Error("empty array expected, got", out)
+	}
+}
+
+func TestOutNDJSON(t *testing.T) {
+	out := outTest(t, OutNDJSON, outTestIssues, "list my open cases")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatal("2 lines expected, got", out)
+	}
+	var rec outRecord
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.InputID != "X-1" || rec.Fields[IssueinfoStrState] != "Open" {
+		t.Error("unexpected record", rec)
+	}
+}
+
+func TestOutCSV(t *testing.T) {
+	out := outTest(t, OutCSV, outTestIssues,
+		"list my open cases", "list my open cases", "unknown action")
+	exp := `action,input_id,index,id,project,status,displayName,summary
+list my open cases,X-1,1,X-1,,Open,,"first, with comma"
+list my open cases,X-1,3,X-2,,Closed,,"second
+line"
+list my open cases,X-1,1,X-1,,Open,,"first, with comma"
+list my open cases,X-1,3,X-2,,Closed,,"second
+line"
+action,input_id,index,id,status,summary
+unknown action,X-1,1,X-1,Open,"first, with comma"
+unknown action,X-1,3,X-2,Closed,"second
+line"
+`
+	if out != exp {
+		t.Error("unexpected csv", out)
+	}
+}
+
+func TestOutTable(t *testing.T) {
+	out := outTest(t, OutTable, outTestIssues, "list my open cases")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatal("header and 2 lines expected, got", out)
+	}
+	if !strings.HasPrefix(lines[0], "action") ||
+		!strings.Contains(lines[0], IssueinfoStrDispname) ||
+		!strings.Contains(lines[2], `second\nline`) {
+		t.Error("unexpected table", out)
+	}
+}
+
+func TestOutLogs(t *testing.T) {
+	var logs bytes.Buffer
+	stdout, logOld := os.Stdout, logWriter
+	defer func() {
+		logWriter = logOld
+		setOutFmt(OutText)
+	}()
+	if err := setOutFmt(OutCSV); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stdout != stdout || logWriter != os.Stderr {
+		t.Fatal("standard output kept and logs to standard error expected")
+	}
+	logWriter = &logs
+	Log(true, false, "a log")
+	out := outTest(t, OutCSV, outTestIssues, "list my open cases")
+	if logs.String() != "a log\n" || strings.Contains(out, "a log") {
+		t.Errorf("logs apart from results expected, got %q and %q",
+			logs.String(), out)
+	}
+}
+
+func TestOutFormat(t *testing.T) {
+	defer setTemplates("", nil)
+	if err := setTemplates(`{{.id}}\t{{pad 7 .status}}|{{trunc 6 .summary}}`,
+		nil); err != nil {
+		t.Fatal(err)
+	}
+	out := outTest(t, OutJSON, outTestIssues, "list my open cases")
+	if exp := "X-1\tOpen   |first…\nX-2\tClosed |secon…\n"; out != exp {
+		t.Errorf("%q expected, got %q", exp, out)
+	}
+	if err := setTemplates("", []outTemplate{
+		{Type: CategoryJira, Action: "list my open cases",
+			Tmpl: "{{.id}} {{.nothing}}"},
+		{Action: "list my open cases", Tmpl: "any type"},
+		{Action: "unknown action", Tmpl: `{{join "," .id .none .status}}`},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	out = outTest(t, OutText, outTestIssues, "list my open cases",
+		"unknown action")
+	if exp := "X-1 \nX-2 \nX-1,Open\nX-2,Closed\n"; out != exp {
+		t.Errorf("%q expected, got %q", exp, out)
+	}
+	// templates in config for text output only
+	if out = outTest(t, OutCSV, outTestIssues,
+		"unknown action"); !strings.HasPrefix(out, "action,") {
+		t.Error("csv expected, got", out)
+	}
+	for _, tmpl := range []string{"{{.id", "{{nothing .id}}"} {
+		if err := setTemplates(tmpl, nil); !errors.Is(err,
+			eztools.ErrInvalidInput) {
+			t.Error(tmpl, "invalid input expected, got", err)
+		}
+	}
+}
+
+func TestOutDate(t *testing.T) {
+	loc := time.Local
+	defer func() {
+		time.Local = loc
+	}()
+	time.Local = time.FixedZone("UTC+8", 8*60*60)
+	for in, exp := range map[string]string{
+		"1715342400000":                 "2024-05-10 20:00",
+		"2024-05-10 12:00:00.000000000": "2024-05-10 20:00",
+		"2024-05-10T12:00:00.000+0000":  "2024-05-10 20:00",
+		"yesterday":                     "yesterday",
+	} {
+		if got := outDate("2006-01-02 15:04", in); got != exp {
+			t.Error(in, exp, "expected, got", got)
+		}
+	}
+	if got := outDate("", "0"); got != "1970-01-01 08:00:00" {
+		t.Error("default layout expected, got", got)
+	}
+	if got := outPad(-5, "ab"); got != "   ab" {
+		t.Errorf("left padding expected, got %q", got)
+	}
+}
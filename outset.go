@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// outGroupAction is the action of records of counts by -group-by
+	outGroupAction = "group by"
+	// outGroupCount is the field of counts by -group-by
+	outGroupCount = "count"
+)
+
+var (
+	// outSortKey is the field to sort results by, from -sort, if any
+	outSortKey  string
+	outSortDesc bool
+	// outFields are fields to output in order, from -fields, if any
+	outFields []string
+	// outGroupBy is the field to count results by, from -group-by, if any
+	outGroupBy string
+	// outGroups are counts of values of outGroupBy so far
+	outGroups map[string]int
+)
+
+// outNumSuffix splits IDs such as X-12 into prefix and number
+var outNumSuffix = regexp.MustCompile(`^(.*?)(\d+)$`)
+
+// setOutSet checks and sets sorting, as field[:desc], fields
+// separated by commas, and the field to group by
+func setOutSet(sort, fields, groupBy string) error {
+	outSortKey, outSortDesc, outFields, outGroupBy, outGroups =
+		"", false, nil, groupBy, nil
+	if len(sort) > 0 {
+		key, dir, _ := strings.Cut(sort, ":")
+		switch dir {
+		case "desc":
+			outSortDesc = true
+		case "", "asc":
+		default:
+			Log(true, false, "sort order must be asc or desc, not", dir)
+			return eztools.ErrInvalidInput
+		}
+		if len(key) < 1 {
+			Log(true, false, "NO field to sort by")
+			return eztools.ErrInvalidInput
+		}
+		outSortKey = key
+	}
+	if len(fields) > 0 {
+		for _, f := range strings.Split(fields, ",") {
+			if f = strings.TrimSpace(f); len(f) > 0 &&
+				!slices.Contains(outFields, f) {
+				outFields = append(outFields, f)
+			}
+		}
+	}
+	if len(outGroupBy) > 0 {
+		outGroups = make(map[string]int)
+	}
+	return nil
+}
+
+// outSortCmp compares values as numbers, as IDs with the same prefix
+// by their numbers, such as X-9 and X-12, as times, or as strings
+func outSortCmp(a, b string) int {
+	ma, mb := outNumSuffix.FindStringSubmatch(a),
+		outNumSuffix.FindStringSubmatch(b)
+	if ma != nil && mb != nil && ma[1] == mb[1] {
+		if _, ok := whereNum(a); !ok {
+			x, _ := strconv.ParseUint(ma[2], 10, 64)
+			y, _ := strconv.ParseUint(mb[2], 10, 64)
+			if x != y {
+				if x < y {
+					return -1
+				}
+				return 1
+			}
+		}
+	}
+	return whereOrder(a, b)
+}
+
+// outSort sorts results by outSortKey, if any, with ones without it last
+func outSort(issues IssueInfoSlc) {
+	if len(outSortKey) < 1 {
+		return
+	}
+	slices.SortStableFunc(issues, func(a, b IssueInfos) int {
+		va, aok := a[outSortKey]
+		vb, bok := b[outSortKey]
+		switch {
+		case !aok && !bok:
+			return 0
+		case !aok:
+			return 1
+		case !bok:
+			return -1
+		}
+		if outSortDesc {
+			return outSortCmp(vb, va)
+		}
+		return outSortCmp(va, vb)
+	})
+}
+
+// outProject takes outFields of results, if any
+func outProject(recs []outRecord) []outRecord {
+	if len(outFields) < 1 {
+		return recs
+	}
+	for i, rec := range recs {
+		fields := make(IssueInfos)
+		for _, f := range outFields {
+			if v, ok := rec.Fields[f]; ok {
+				fields[f] = v
+			}
+		}
+		recs[i].Fields = fields
+	}
+	return recs
+}
+
+// outKeys returns keys of a result of an action, in order of outFields,
+// or alphabetical order
+func outKeys(action string, fields IssueInfos) []string {
+	var keys []string
+	switch {
+	case action == outGroupAction:
+		keys = []string{outGroupBy, outGroupCount}
+	case len(outFields) > 0:
+		keys = outFields
+	default:
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+	return slices.DeleteFunc(slices.Clone(keys), func(k string) bool {
+		_, ok := fields[k]
+		return !ok
+	})
+}
+
+// outGroup counts results by values of outGroupBy
+func outGroup(recs []outRecord) {
+	for _, rec := range recs {
+		outGroups[rec.Fields[outGroupBy]]++
+	}
+}
+
+// outFlushGroups writes counts by outGroupBy, the most first,
+// as lines of values and counts for OutText
+func outFlushGroups() {
+	groups := outGroups
+	outGroups = make(map[string]int)
+	vals := make([]string, 0, len(groups))
+	for v := range groups {
+		vals = append(vals, v)
+	}
+	slices.SortFunc(vals, func(a, b string) int {
+		if groups[a] != groups[b] {
+			return groups[b] - groups[a]
+		}
+		return strings.Compare(a, b)
+	})
+	if outFmt == OutText && outTmplOf("", outGroupAction) == nil {
+		w := tabwriter.NewWriter(outWriter, 0, 4, 2, ' ', 0)
+		io.WriteString(w, outGroupBy+"\t"+outGroupCount+"\n")
+		for _, v := range vals {
+			fmt.Fprintf(w, "%s\t%d\n", v, groups[v])
+		}
+		if err := w.Flush(); err != nil {
+			Log(true, false, err)
+		}
+		return
+	}
+	recs := make([]outRecord, len(vals))
+	for i, v := range vals {
+		recs[i] = outRecord{Action: outGroupAction, Index: i + 1,
+			Fields: IssueInfos{outGroupBy: v,
+				outGroupCount: strconv.Itoa(groups[v])}}
+	}
+	outRender("", outGroupAction, recs)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestOutSort(t *testing.T) {
+	defer setOutSet("", "", "")
+	issues := IssueInfoSlc{
+		{IssueinfoStrID: "X-12", IssueinfoStrNmb: "9"},
+		{IssueinfoStrID: "X-9", IssueinfoStrNmb: "100"},
+		{IssueinfoStrNmb: "10"},
+		{IssueinfoStrID: "W-100", IssueinfoStrNmb: "-1"},
+	}
+	ids := func() (ret []string) {
+		for _, issue := range issues {
+			ret = append(ret, issue[IssueinfoStrNmb])
+		}
+		return
+	}
+	for sort, exp := range map[string][]string{
+		IssueinfoStrID:           {"-1", "100", "9", "10"},
+		IssueinfoStrID + ":desc": {"9", "100", "-1", "10"},
+		IssueinfoStrNmb + ":asc": {"-1", "9", "10", "100"},
+	} {
+		if err := setOutSet(sort, "", ""); err != nil {
+			t.Fatal(err)
+		}
+		outSort(issues)
+		if !slices.Equal(ids(), exp) {
+			t.Error(sort, exp, "expected, got", ids())
+		}
+	}
+	for _, sort := range []string{":desc", "id:down"} {
+		if err := setOutSet(sort, "", ""); !errors.Is(err,
+			eztools.ErrInvalidInput) {
+			t.Error(sort, "invalid input expected, got", err)
+		}
+	}
+}
+
+func TestOutFields(t *testing.T) {
+	defer setOutSet("", "", "")
+	if err := setOutSet("", "summary, id,id", ""); err != nil {
+		t.Fatal(err)
+	}
+	out := outTest(t, OutCSV, outTestIssues, "list my open cases")
+	if !strings.HasPrefix(out, "action,input_id,index,summary,id\n") ||
+		strings.Contains(out, "Open") {
+		t.Error("summary and id expected, got", out)
+	}
+	if keys := outKeys("", outTestIssues[0]); !slices.Equal(keys,
+		[]string{IssueinfoStrSummary, IssueinfoStrID}) {
+		t.Error("keys in order of fields expected, got", keys)
+	}
+	setOutSet("", "", "")
+	if keys := outKeys("", outTestIssues[0]); !slices.Equal(keys,
+		[]string{IssueinfoStrID, IssueinfoStrState, IssueinfoStrSummary}) {
+		t.Error("keys in alphabetical order expected, got", keys)
+	}
+}
+
+func TestOutGroupBy(t *testing.T) {
+	defer setOutSet("", "", "")
+	if err := setOutSet("", "", IssueinfoStrState); err != nil {
+		t.Fatal(err)
+	}
+	issues := append(slices.Clone(outTestIssues), IssueInfos{
+		IssueinfoStrID: "X-3", IssueinfoStrState: "Closed"},
+		IssueInfos{IssueinfoStrID: "X-4"})
+	out := outTest(t, OutText, issues, "list my open cases")
+	if exp := "status  count\nClosed  2\n        1\nOpen    1\n"; out != exp {
+		t.Errorf("%q expected, got %q", exp, out)
+	}
+	if out = outTest(t, OutCSV, issues, "list my open cases",
+		"show details of a case"); out !=
+		"action,input_id,index,status,count\n"+
+			"group by,,1,Closed,4\ngroup by,,2,,2\ngroup by,,3,Open,2\n" {
+		t.Error("unexpected csv", out)
+	}
+}
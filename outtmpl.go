@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// outTemplate is a template in config to render results of an action,
+// of a server type, or all types if empty, instead of OutText
+type outTemplate struct {
+	Type   string `xml:"type,attr,omitempty"`
+	Action string `xml:"action,attr"`
+	Tmpl   string `xml:",chardata"`
+}
+
+var (
+	// outFormat is the template of -format for all results, if any
+	outFormat *template.Template
+	// outTmpls are templates in config by type and action, for OutText
+	outTmpls map[[2]string]*template.Template
+)
+
+// outFuncs are helpers in templates
+var outFuncs = template.FuncMap{
+	"trunc": outTrunc,
+	"pad":   outPad,
+	"date":  outDate,
+	"join":  outJoin,
+}
+
+// outTrunc truncates s to n chars, with "…" as the last one if cut
+func outTrunc(n int, s string) string {
+	if n < 1 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// outPad pads s with spaces to n chars on the right,
+// or on the left if n is negative
+func outPad(n int, s string) string {
+	left := n < 0
+	if left {
+		n = -n
+	}
+	spaces := n - utf8.RuneCountInString(s)
+	if spaces < 1 {
+		return s
+	}
+	if left {
+		return strings.Repeat(" ", spaces) + s
+	}
+	return s + strings.Repeat(" ", spaces)
+}
+
+// outDate reformats a time by a Go layout in local time, such as
+// milliseconds of Jenkins timestamp, Gerrit created and Jira times.
+// Values not recognized are kept.
+func outDate(layout, s string) string {
+	t, ok := whereTime(s)
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		t, ok = time.UnixMilli(ms), true
+	}
+	if !ok {
+		return s
+	}
+	if len(layout) < 1 {
+		layout = time.DateTime
+	}
+	return t.Local().Format(layout)
+}
+
+// outJoin joins non-empty values with sep
+func outJoin(sep string, vals ...string) string {
+	var ret []string
+	for _, v := range vals {
+		if len(v) > 0 {
+			ret = append(ret, v)
+		}
+	}
+	return strings.Join(ret, sep)
+}
+
+// outParse parses a template, with \t and \n as tab and new line
+func outParse(name, tmpl string) (*template.Template, error) {
+	tmpl = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(tmpl)
+	if !strings.HasSuffix(tmpl, "\n") {
+		tmpl += "\n"
+	}
+	t, err := template.New(name).Funcs(outFuncs).
+		Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", eztools.ErrInvalidInput, err)
+	}
+	return t, nil
+}
+
+// setTemplates checks and sets the template of -format,
+// and ones in config
+func setTemplates(format string, tmpls []outTemplate) error {
+	outFormat, outTmpls = nil, make(map[[2]string]*template.Template)
+	if len(format) > 0 {
+		t, err := outParse("format", format)
+		if err != nil {
+			Log(true, false, err)
+			return err
+		}
+		outFormat = t
+	}
+	for _, tmpl := range tmpls {
+		t, err := outParse(tmpl.Type+" "+tmpl.Action, tmpl.Tmpl)
+		if err != nil {
+			Log(true, false, "template of", tmpl.Action, err)
+			return err
+		}
+		outTmpls[[2]string{tmpl.Type, tmpl.Action}] = t
+	}
+	return nil
+}
+
+// outTmplOf gets the template for results of an action on a server type,
+// from -format, or config for OutText
+func outTmplOf(svrType, action string) *template.Template {
+	if outFormat != nil {
+		return outFormat
+	}
+	if outFmt != OutText {
+		return nil
+	}
+	if t, ok := outTmpls[[2]string{svrType, action}]; ok {
+		return t
+	}
+	return outTmpls[[2]string{"", action}]
+}
+
+// outWriteTmpl renders results by a template, with fields of each
+func outWriteTmpl(t *template.Template, recs []outRecord) {
+	for _, rec := range recs {
+		if err := t.Execute(outWriter, rec.Fields); err != nil {
+			Log(true, false, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gitee.com/bon-ami/eztools/v6"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// PassSrcText password as text in xml, by default
+	PassSrcText = "text"
+	// PassSrcCmd password from the first line of stdout of a command in xml
+	PassSrcCmd = "cmd"
+	// PassSrcEnv password from an environment variable named in xml
+	PassSrcEnv = "env"
+	// PassSrcNetrc password from netrc for host of the server.
+	// The file, if in xml, is used instead of $NETRC or ~/.netrc.
+	PassSrcNetrc = "netrc"
+	// PassSrcStore password from the encrypted store, by the key in xml
+	PassSrcStore = "store"
+	// passStoreEnv is the environment variable of the master passphrase
+	passStoreEnv = "JIRRIT_PASSPHRASE"
+	// passStoreIter is the number of PBKDF2 iterations for new stores
+	passStoreIter = 600000
+)
+
+var (
+	errPassSrc = errors.New("NO password from source")
+	// passStorePhrase is the master passphrase, asked only once for all
+	passStorePhrase string
+	// passStoreKey is derived from passStorePhrase and passStoreSalt
+	passStoreKey, passStoreSalt []byte
+)
+
+// configured checks whether a password is either of type none,
+// or with the text or the source to get it
+func (p passwords) configured() bool {
+	return p.Type == PassNone || len(p.Pass) > 0 || p.Src == PassSrcNetrc
+}
+
+// get gets the password from its source, for the server
+func (p passwords) get(svr svrs) (string, error) {
+	switch p.Src {
+	case "", PassSrcText:
+		return p.Pass, nil
+	case PassSrcCmd:
+		return passFromCmd(p.Pass)
+	case PassSrcEnv:
+		if v, ok := os.LookupEnv(p.Pass); ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("%w: %s %s", errPassSrc, p.Src, p.Pass)
+	case PassSrcNetrc:
+		return passFromNetrc(p.Pass, svr.URL)
+	case PassSrcStore:
+		return passFromStore(passStoreFile(), p.Pass)
+	}
+	return "", fmt.Errorf("%w: unknown %s", errCfg, p.Src)
+}
+
+// passFromCmd runs a command line, such as "pass show jira",
+// with no shell, and takes the first line of its stdout
+func passFromCmd(cmdLine string) (string, error) {
+	args := strings.Fields(cmdLine)
+	if len(args) < 1 {
+		return "", fmt.Errorf("%w: empty %s", errPassSrc, PassSrcCmd)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %s: %w", errPassSrc,
+			PassSrcCmd, args[0], err)
+	}
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
+
+// passFromNetrc looks up the password of the host of svrURL
+// in file, $NETRC or ~/.netrc, with the default entry as a fallback
+func passFromNetrc(file, svrURL string) (string, error) {
+	u, err := url.Parse(svrURL)
+	if err != nil {
+		return "", err
+	}
+	if len(file) < 1 {
+		file = os.Getenv("NETRC")
+	}
+	if len(file) < 1 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		file = filepath.Join(home, ".netrc")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errPassSrc, err)
+	}
+	defer f.Close()
+	var (
+		words []string
+		macro bool
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case macro:
+			// a macro ends with an empty line
+			macro = len(strings.TrimSpace(line)) > 0
+			continue
+		case strings.HasPrefix(line, "macdef"):
+			macro = true
+			continue
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	if err = scanner.Err(); err != nil {
+		return "", err
+	}
+	var (
+		pass, passDef, machine string
+		found, foundDef, inDef bool
+	)
+	for i := 0; i < len(words); i++ {
+		switch words[i] {
+		case "machine":
+			if i++; i < len(words) {
+				machine = words[i]
+			}
+			inDef = false
+		case "default":
+			inDef = true
+		case "login", "account":
+			// values may be the same as keywords
+			i++
+		case "password":
+			if i++; i >= len(words) {
+				break
+			}
+			switch {
+			case inDef:
+				if !foundDef {
+					passDef, foundDef = words[i], true
+				}
+			case machine == u.Hostname() && !found:
+				pass, found = words[i], true
+			}
+		}
+	}
+	switch {
+	case found:
+		return pass, nil
+	case foundDef:
+		return passDef, nil
+	}
+	return "", fmt.Errorf("%w: %s for %s", errPassSrc, file, u.Hostname())
+}
+
+// passStore is the encrypted store of passwords, saved as JSON
+type passStore struct {
+	Salt []byte `json:"salt"`
+	Iter int    `json:"iter"`
+	// Items are encrypted passwords by keys, with nonces as prefixes
+	Items map[string][]byte `json:"items"`
+}
+
+// passStoreFile is the store file besides the config file
+func passStoreFile() string {
+	if len(cfg.PassStore) > 0 {
+		return cfg.PassStore
+	}
+	if len(cfgFile) < 1 {
+		return module + ".pass"
+	}
+	return strings.TrimSuffix(cfgFile, filepath.Ext(cfgFile)) + ".pass"
+}
+
+// passStoreRead reads the store from file, or makes a new one
+func passStoreRead(file string) (*passStore, error) {
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		store := passStore{Salt: make([]byte, 16), Iter: passStoreIter,
+			Items: make(map[string][]byte)}
+		_, err = rand.Read(store.Salt)
+		return &store, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	var store passStore
+	if err = json.Unmarshal(b, &store); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errCfg, file, err)
+	}
+	if store.Items == nil {
+		store.Items = make(map[string][]byte)
+	}
+	return &store, nil
+}
+
+// aead makes the cipher from the passphrase, from passStoreEnv or input
+func (s *passStore) aead() (cipher.AEAD, error) {
+	if len(passStorePhrase) < 1 {
+		phrase, ok := os.LookupEnv(passStoreEnv)
+		if !ok {
+			if uiSilent {
+				noInteractionAllowed()
+				return nil, fmt.Errorf("%w: set %s for the password store",
+					eztools.ErrInvalidInput, passStoreEnv)
+			}
+			phrase = promptStr("master passphrase of " +
+				"the password store")
+		}
+		if len(phrase) < 1 {
+			return nil, eztools.ErrInvalidInput
+		}
+		passStorePhrase = phrase
+	}
+	if !bytes.Equal(passStoreSalt, s.Salt) {
+		passStoreKey = pbkdf2.Key([]byte(passStorePhrase),
+			s.Salt, s.Iter, 32, sha256.New)
+		passStoreSalt = s.Salt
+	}
+	block, err := aes.NewCipher(passStoreKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// open decrypts the password of key
+func (s *passStore) open(file, key string) (string, error) {
+	item, ok := s.Items[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s in %s", errPassSrc, key, file)
+	}
+	aead, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	if len(item) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: %s in %s", errCfg, key, file)
+	}
+	pass, err := aead.Open(nil, item[:aead.NonceSize()],
+		item[aead.NonceSize():], []byte(key))
+	if err != nil {
+		// to be asked again
+		passStorePhrase, passStoreSalt = "", nil
+		return "", fmt.Errorf("%w: wrong passphrase of %s?", errAuth, file)
+	}
+	return string(pass), nil
+}
+
+// passFromStore decrypts the password of key from the store in file
+func passFromStore(file, key string) (string, error) {
+	store, err := passStoreRead(file)
+	if err != nil {
+		return "", err
+	}
+	return store.open(file, key)
+}
+
+// passToStore encrypts and saves the password of key in the store in file
+func passToStore(file, key, pass string) error {
+	store, err := passStoreRead(file)
+	if err != nil {
+		return err
+	}
+	// check the passphrase with any existing one
+	for k := range store.Items {
+		if _, err = store.open(file, k); err != nil {
+			return err
+		}
+		break
+	}
+	aead, err := store.aead()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+	store.Items[key] = aead.Seal(nonce, nonce, []byte(pass), []byte(key))
+	b, err := json.MarshalIndent(store, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, b, 0600)
+}
+
+// inputPassSrc asks for the source of a password and what it needs.
+// A password to be stored is saved in the store, with key as its key.
+func inputPassSrc(key string) (src, passTxt string, ok bool) {
+	passSrcs := []string{
+		PassSrcText + " - as text in config",
+		PassSrcCmd + " - first line of output of a command, " +
+			"such as \"pass show jira\"",
+		PassSrcEnv + " - an environment variable",
+		PassSrcNetrc + " - netrc for host of the server",
+		PassSrcStore + " - encrypted store, unlocked by a master passphrase"}
+	srcInd, _ := chooseStrings(passSrcs)
+	if srcInd == eztools.InvalidID {
+		srcInd = 0
+	}
+	src, _, _ = strings.Cut(passSrcs[srcInd], " - ")
+	switch src {
+	case PassSrcText:
+		src = ""
+		passTxt = promptStr("password")
+	case PassSrcCmd:
+		passTxt = promptStr("command")
+	case PassSrcEnv:
+		passTxt = promptStr("name of environment variable")
+	case PassSrcNetrc:
+		// optional
+		return src, promptStr("netrc file([Enter]=default)"), true
+	case PassSrcStore:
+		pass := promptStr("password")
+		if len(pass) < 1 {
+			return
+		}
+		if err := passToStore(passStoreFile(), key, pass); err != nil {
+			Log(true, false, err)
+			return
+		}
+		passTxt = key
+	}
+	ok = len(passTxt) > 0
+	return
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPassSrc(t *testing.T) {
+	t.Setenv("JIRRIT_TEST_PASS", "fromenv")
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(netrc, []byte(
+		"machine other.com login u password other\n"+
+			"macdef init\nmachine jira.com password macro\n\n"+
+			"machine jira.com\n\tlogin password\n\tpassword fromnetrc\n"+
+			"default login u password fromdefault\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		pass passwords
+		url  string
+		exp  string
+		err  error
+	}{
+		{passwords{Pass: "plain"}, "", "plain", nil},
+		{passwords{Src: PassSrcText, Pass: "plain"}, "", "plain", nil},
+		{passwords{Src: PassSrcEnv, Pass: "JIRRIT_TEST_PASS"}, "", "fromenv", nil},
+		{passwords{Src: PassSrcEnv, Pass: "JIRRIT_TEST_NONE"}, "", "", errPassSrc},
+		{passwords{Src: PassSrcCmd, Pass: "echo fromcmd"}, "", "fromcmd", nil},
+		{passwords{Src: PassSrcCmd, Pass: "false"}, "", "", errPassSrc},
+		{passwords{Src: PassSrcNetrc, Pass: netrc},
+			"https://jira.com:8080/", "fromnetrc", nil},
+		{passwords{Src: PassSrcNetrc, Pass: netrc},
+			"https://gerrit.com/a/", "fromdefault", nil},
+		{passwords{Src: "keyring"}, "", "", errCfg},
+	} {
+		got, err := c.pass.get(svrs{URL: c.url})
+		if got != c.exp || !errors.Is(err, c.err) {
+			t.Error(c.pass, c.url, "got", got, err)
+		}
+	}
+}
+
+func TestPassStore(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jirrit.pass")
+	defer func() {
+		passStorePhrase, passStoreKey, passStoreSalt = "", nil, nil
+	}()
+	t.Setenv(passStoreEnv, "master")
+	if err := passToStore(file, "J", "secretJ"); err != nil {
+		t.Fatal(err)
+	}
+	if err := passToStore(file, "G", "secretG"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"secretJ", "secretG", "master"} {
+		if strings.Contains(string(b), s) {
+			t.Error(s, "in plain text in store")
+		}
+	}
+	// as a new process, with the key derived again
+	passStorePhrase, passStoreKey, passStoreSalt = "", nil, nil
+	if got, err := passFromStore(file, "G"); got != "secretG" || err != nil {
+		t.Error("secretG expected, got", got, err)
+	}
+	if _, err := passFromStore(file, "B"); !errors.Is(err, errPassSrc) {
+		t.Error("no password expected, got", err)
+	}
+	passStorePhrase, passStoreSalt = "wrong", nil
+	if _, err := passFromStore(file, "J"); !errors.Is(err, errAuth) {
+		t.Error("wrong passphrase expected, got", err)
+	}
+	passStorePhrase, passStoreSalt = "wrong", nil
+	if err := passToStore(file, "K", "secretK"); !errors.Is(err, errAuth) {
+		t.Error("no saving with wrong passphrase expected, got", err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// retryWaitDef is the default initial wait before a retry
+	retryWaitDef = 500 * time.Millisecond
+	// retryWaitMax is the max wait before a retry, from backoff
+	retryWaitMax = 30 * time.Second
+)
+
+// retryCfg is how requests are retried when servers are busy
+type retryCfg struct {
+	times int
+	wait  time.Duration
+}
+
+// retryKey is the key of retryCfg in contexts
+type retryKey struct{}
+
+// withRetry sets retry config of ctx from config of a server,
+// for actions on different servers at the same time
+func withRetry(ctx context.Context, svr *svrs) context.Context {
+	retry := retryCfg{times: svr.Retries,
+		wait: time.Duration(svr.RetryWait) * time.Millisecond}
+	if retry.wait <= 0 {
+		retry.wait = retryWaitDef
+	}
+	return context.WithValue(ctx, retryKey{}, retry)
+}
+
+// retryOf gets retry config of ctx, with no retries if not set
+func retryOf(ctx context.Context) retryCfg {
+	retry, _ := ctx.Value(retryKey{}).(retryCfg)
+	return retry
+}
+
+// retrySafeKey is the key in contexts of requests safe to retry
+type retrySafeKey struct{}
+
+// retrySafe marks requests with ctx safe to retry, even of non-idempotent
+// methods, such as POST, to be used by actions as the context for rest*
+func retrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+// retryable checks whether a request can be retried,
+// of an idempotent method, or marked by retrySafe
+func retryable(ctx context.Context, method string) bool {
+	if safe, _ := ctx.Value(retrySafeKey{}).(bool); safe {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut,
+		http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// retryNeeded checks a response or an error for busy servers
+// or connection blips
+func retryNeeded(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryWait is the time to wait before the retry after attempt,
+// from Retry-After of resp, or exponential backoff with jitter from base
+func retryWait(resp *http.Response, base time.Duration,
+	attempt int) time.Duration {
+	if resp != nil {
+		if after := resp.Header.Get("Retry-After"); len(after) > 0 {
+			if secs, err := strconv.Atoi(after); err == nil {
+				return time.Duration(secs) * time.Second
+			}
+			if tm, err := http.ParseTime(after); err == nil {
+				return max(time.Until(tm), 0)
+			}
+		}
+	}
+	wait := base << attempt
+	if wait <= 0 || wait > retryWaitMax {
+		wait = retryWaitMax
+	}
+	// half of it fixed and the other half random
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// restSend sends a request by send,
+// with a new copy of bodyReq, if any, for each attempt.
+// It is retried if configured and the method is idempotent or marked safe,
+// unless ctx of the action, or ctxReq of the request, is done.
+func restSend(ctx, ctxReq context.Context, method, url string,
+	bodyReq io.Reader,
+	send func(string, io.Reader) (*http.Response, error)) (
+	resp *http.Response, err error) {
+	retry := retryOf(ctx)
+	if !retryable(ctx, method) || retry.times < 1 {
+		logReq(method, url)
+		return send(method, bodyReq)
+	}
+	var body []byte
+	if bodyReq != nil {
+		if body, err = io.ReadAll(bodyReq); err != nil {
+			return
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		var bodyNew io.Reader
+		if body != nil {
+			bodyNew = bytes.NewReader(body)
+		}
+		logReq(method, url)
+		resp, err = send(method, bodyNew)
+		if attempt >= retry.times || !retryNeeded(resp, err) {
+			return
+		}
+		wait := retryWait(resp, retry.wait, attempt)
+		if eztools.Debugging && eztools.Verbose > 1 {
+			reason := any(err)
+			if err == nil {
+				reason = resp.Status
+			}
+			Log(true, false, "retrying", method, url, "in", wait,
+				"after", reason)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-ctxReq.Done():
+			return nil, context.Cause(ctxReq)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// retryTest runs a request against a server busy for some times,
+// and returns the number of requests received
+func retryTest(t *testing.T, method string, safe bool, busy int,
+	body string) (int32, error) {
+	var got int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&got, 1)
+		b, _ := io.ReadAll(r.Body)
+		if string(b) != body {
+			t.Errorf("request %d: body %q expected, got %q", n, body, b)
+		}
+		if int(n) <= busy {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+	ctx := withRetry(context.Background(), &svrs{Retries: 2, RetryWait: 1})
+	if safe {
+		ctx = retrySafe(ctx)
+	}
+	var bodyReq io.Reader
+	if len(body) > 0 {
+		bodyReq = strings.NewReader(body)
+	}
+	m, err := restMap(ctx, method, srv.URL,
+		eztools.AuthInfo{Type: eztools.AuthNone}, bodyReq, "")
+	if err == nil && m["ok"] != true {
+		t.Error("unexpected result", m)
+	}
+	return atomic.LoadInt32(&got), err
+}
+
+func TestRetry(t *testing.T) {
+	for _, c := range []struct {
+		method string
+		safe   bool
+		busy   int
+		got    int32
+		ok     bool
+	}{
+		{http.MethodGet, false, 2, 3, true},
+		{http.MethodGet, false, 3, 3, false},
+		{http.MethodPut, false, 1, 2, true},
+		{http.MethodPost, false, 1, 1, false},
+		{http.MethodPost, true, 1, 2, true},
+	} {
+		got, err := retryTest(t, c.method, c.safe, c.busy, `{"a":1}`)
+		if got != c.got || (err == nil) != c.ok {
+			t.Error(c.method, c.safe, "busy for", c.busy, "requested", got, "times, error", err)
+		}
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Retry-After", "2")
+	if w := retryWait(resp, retryWaitDef, 0); w != 2*time.Second {
+		t.Error("2s expected from Retry-After, got", w)
+	}
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	if w := retryWait(resp, retryWaitDef, 0); w != 0 {
+		t.Error("no wait expected for a past date, got", w)
+	}
+	const base = 100 * time.Millisecond
+	for attempt, limit := range []time.Duration{100, 200, 400} {
+		limit *= time.Millisecond
+		if w := retryWait(nil, base, attempt); w < limit/2 || w > limit {
+			t.Error("attempt", attempt, "waits", w, "out of", limit/2, limit)
+		}
+	}
+	if w := retryWait(nil, base, 40); w > retryWaitMax {
+		t.Error("wait", w, "over", retryWaitMax)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// serveCmd is the command line argument to serve the JSON API
+	serveCmd = "serve"
+	// serveListenDef is the default address to listen on
+	serveListenDef = "127.0.0.1:8080"
+	// serveBodyMax is the max size of request bodies
+	serveBodyMax = 1 << 20
+)
+
+// serveResp is the reply of an action
+type serveResp struct {
+	Results IssueInfoSlc `json:"results"`
+	// Code is the exit code of the errors, as listed by -h, 0 if none
+	Code   int      `json:"code"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// serveSvr is a server in the list of servers
+type serveSvr struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// server runs actions of configured servers for HTTP requests,
+// each at the same time as others
+type server struct {
+	cats cat2Act
+	para params
+	// tokens are bearer tokens allowed, with none meaning no control
+	tokens [][]byte
+	// mu guards auths
+	mu sync.Mutex
+	// auths are auth info of servers got, for sources not to be
+	// asked every time
+	auths map[string]svrAuth
+}
+
+// serveRun serves the JSON API on -listen, till SIGINT.
+// Without tokens configured, only loopback addresses are allowed.
+func serveRun(ctx context.Context, cats cat2Act, para params) []error {
+	s, err := newServer(cats, para)
+	if err != nil {
+		return []error{err}
+	}
+	if len(s.tokens) < 1 && !serveLoopback(para.listen) {
+		return []error{fmt.Errorf("%w: NO tokens configured to serve on %s",
+			errCfg, para.listen)}
+	}
+	ln, err := net.Listen("tcp", para.listen)
+	if err != nil {
+		return []error{fmt.Errorf("%w: %w", errConn, err)}
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	srv := &http.Server{Handler: s.handler(),
+		ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		ctxShut, cancel := context.WithTimeout(context.Background(),
+			10*time.Second)
+		defer cancel()
+		srv.Shutdown(ctxShut)
+	}()
+	Log(true, false, "serving on", ln.Addr().String())
+	if err = srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return []error{fmt.Errorf("%w: %w", errConn, err)}
+	}
+	return nil
+}
+
+// newServer makes a server with tokens from their sources,
+// without interactions
+func newServer(cats cat2Act, para params) (*server, error) {
+	uiSilent = true
+	s := &server{cats: cats, para: para,
+		auths: make(map[string]svrAuth)}
+	for _, tok := range cfg.Serve.Tokens {
+		t, err := tok.get(svrs{})
+		if err != nil {
+			return nil, fmt.Errorf("%w: token: %w", errCfg, err)
+		}
+		if len(t) < 1 {
+			return nil, fmt.Errorf("%w: empty token", errCfg)
+		}
+		s.tokens = append(s.tokens, []byte(t))
+	}
+	return s, nil
+}
+
+// serveLoopback checks whether an address to listen on is a loopback one
+func serveLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handler routes requests, with bearer tokens checked
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /servers", s.listSvrs)
+	mux.HandleFunc("GET /servers/{name}/actions", s.listActs)
+	mux.HandleFunc("POST /servers/{name}/actions/{action}", s.runAct)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authed(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+module+`"`)
+			serveErr(w, http.StatusUnauthorized, errAuth)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// authed checks the bearer token of a request, if any configured
+func (s *server) authed(r *http.Request) bool {
+	if len(s.tokens) < 1 {
+		return true
+	}
+	tok, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(tok), t) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// serveJSON replies v as JSON
+func serveJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		Log(false, false, err)
+	}
+}
+
+// serveErr replies an error without results
+func serveErr(w http.ResponseWriter, code int, err error) {
+	serveJSON(w, code, serveResp{Code: exitCode(err),
+		Errors: []string{err.Error()}})
+}
+
+// serveStatus maps an exit code to an HTTP status
+func serveStatus(code int) int {
+	switch code {
+	case 0:
+		return http.StatusOK
+	case extInpt:
+		return http.StatusBadRequest
+	case extRslt:
+		return http.StatusNotFound
+	case extIntr:
+		return http.StatusServiceUnavailable
+	}
+	// failures of servers behind
+	return http.StatusBadGateway
+}
+
+func (s *server) listSvrs(w http.ResponseWriter, _ *http.Request) {
+	list := make([]serveSvr, len(cfg.Svrs))
+	for i := range cfg.Svrs {
+		list[i] = serveSvr{Name: cfg.Svrs[i].Name, Type: cfg.Svrs[i].Type}
+	}
+	serveJSON(w, http.StatusOK, list)
+}
+
+func (s *server) listActs(w http.ResponseWriter, r *http.Request) {
+	svr := matchSvr(cfg.Svrs, r.PathValue("name"))
+	if svr == nil {
+		serveErr(w, http.StatusNotFound, fmt.Errorf("%w: server %s",
+			eztools.ErrNoValidResults, r.PathValue("name")))
+		return
+	}
+	var list []string
+	for _, act := range s.cats[svr.Type] {
+		list = append(list, act.n)
+	}
+	serveJSON(w, http.StatusOK, list)
+}
+
+// authOf gets auth info of a server, once for all requests
+func (s *server) authOf(svr *svrs) (svrAuth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if auth, ok := s.auths[svr.Name]; ok {
+		return auth, nil
+	}
+	authInfo, hdr, err := cfg2AuthInfo(*svr, cfg)
+	if err != nil {
+		return svrAuth{}, fmt.Errorf("%w: %w", errCfg, err)
+	}
+	s.auths[svr.Name] = svrAuth{authInfo, hdr}
+	return s.auths[svr.Name], nil
+}
+
+// funcs matches actions separated by actionSep,
+// without setting uiSilent for requests at the same time
+func (s *server) funcs(svr *svrs, action string) []action2Func {
+	var ret []action2Func
+	for _, act := range strings.Split(action, actionSep) {
+		i := slices.IndexFunc(s.cats[svr.Type], func(a action2Func) bool {
+			return a.n == act
+		})
+		if i < 0 {
+			return nil
+		}
+		ret = append(ret, s.cats[svr.Type][i])
+	}
+	return ret
+}
+
+// runAct runs an action with IssueInfos in the body, if any,
+// stopped if the client is gone
+func (s *server) runAct(w http.ResponseWriter, r *http.Request) {
+	svr := matchSvr(cfg.Svrs, r.PathValue("name"))
+	if svr == nil {
+		serveErr(w, http.StatusNotFound, fmt.Errorf("%w: server %s",
+			eztools.ErrNoValidResults, r.PathValue("name")))
+		return
+	}
+	// requests at the same time may change its project
+	svr = svrCopy(svr)
+	funs := s.funcs(svr, r.PathValue("action"))
+	if funs == nil {
+		serveErr(w, http.StatusNotFound, fmt.Errorf("%w: action %s of %s",
+			eztools.ErrNoValidResults, r.PathValue("action"), svr.Type))
+		return
+	}
+	inf := make(IssueInfos)
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, serveBodyMax))
+	if err := dec.Decode(&inf); err != nil && !errors.Is(err, io.EOF) {
+		serveErr(w, http.StatusBadRequest, fmt.Errorf("%w: %w",
+			eztools.ErrInvalidInput, err))
+		return
+	}
+	if inf == nil {
+		// for a body of null
+		inf = make(IssueInfos)
+	}
+	auth, err := s.authOf(svr)
+	if err != nil {
+		serveErr(w, http.StatusInternalServerError, err)
+		return
+	}
+	looper := DefLooper{para: s.para, svr: svr, authInfo: auth.authInfo,
+		hdr: auth.hdr, maxResults: s.para.limit, quiet: true}
+	errs := LoopActions(r.Context(), svr, funs, inf, &looper, nil)
+	resp := serveResp{Results: looper.GetIssueInfo()}
+	if resp.Results == nil {
+		resp.Results = IssueInfoSlc{}
+	}
+	for _, err := range errs {
+		resp.Errors = append(resp.Errors, err.Error())
+	}
+	resp.Code = exitCode(errors.Join(errs...))
+	serveJSON(w, serveStatus(resp.Code), resp)
+}
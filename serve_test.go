@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const serveToken = "t0ken"
+
+// serveReq sends a request to the API, with the token if any,
+// and decodes the reply to v
+func serveReq(t *testing.T, api *httptest.Server, method, path, token,
+	body string, v any) int {
+	req, err := http.NewRequest(method, api.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Error(method, path, err)
+	}
+	return resp.StatusCode
+}
+
+func TestServe(t *testing.T) {
+	silent, user, svrsCfg, tokens := uiSilent, cfg.User, cfg.Svrs,
+		cfg.Serve.Tokens
+	defer func() {
+		uiSilent, cfg.User, cfg.Svrs, cfg.Serve.Tokens = silent, user,
+			svrsCfg, tokens
+	}()
+	cfg.User = fakeUser
+	cfg.Serve.Tokens = []passwords{{Pass: serveToken}}
+	j := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "GET /rest/api/latest/search?jql=" +
+			"assignee%3Dtester%26status%21%3DClosed", resp: "search.json"},
+		{req: "POST /rest/api/latest/issue/X-9/comment",
+			resp: "error.json", code: http.StatusNotFound}})
+	defer j.Close()
+	g := auditFakeSvr(t, CategoryGerrit, []fakeReq{
+		{req: "GET /a/changes/?q=status:merged+owner:alice",
+			resp: "changes.json"}})
+	defer g.Close()
+	cfg.Svrs = []svrs{j.svr, g.svr}
+	s, err := newServer(makeCat2Act(), params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+
+	var resp serveResp
+	if code := serveReq(t, api, http.MethodGet, "/servers", "", "",
+		&resp); code != http.StatusUnauthorized || resp.Code != extAuth {
+		t.Error("unauthorized expected, got", code, resp)
+	}
+	var list []serveSvr
+	if code := serveReq(t, api, http.MethodGet, "/servers", serveToken, "",
+		&list); code != http.StatusOK || len(list) != 2 ||
+		list[1] != (serveSvr{g.svr.Name, CategoryGerrit}) {
+		t.Error("servers got", code, list)
+	}
+	var acts []string
+	if code := serveReq(t, api, http.MethodGet, "/servers/"+j.svr.Name+
+		"/actions", serveToken, "", &acts); code != http.StatusOK ||
+		len(acts) != len(makeCat2Act()[CategoryJira]) {
+		t.Error("actions got", code, acts)
+	}
+
+	path := func(svr, action string) string {
+		return "/servers/" + svr + "/actions/" + url.PathEscape(action)
+	}
+	// actions on different servers at the same time
+	var wg sync.WaitGroup
+	for _, c := range []struct {
+		svr, action, body string
+		n                 int
+	}{
+		{j.svr.Name, "list my open cases", "", 2},
+		{g.svr.Name, "list merged submits of someone", `{"id":"alice"}`, 1},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var resp serveResp
+			if code := serveReq(t, api, http.MethodPost,
+				path(c.svr, c.action), serveToken, c.body,
+				&resp); code != http.StatusOK || resp.Code != 0 ||
+				len(resp.Results) != c.n {
+				t.Error(c.action, "got", code, resp)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range []struct {
+		path, body string
+		status     int
+		code       int
+	}{
+		{path(j.svr.Name, "add a comment to a case"),
+			`{"id":"X-9","comments":"Not found"}`,
+			http.StatusNotFound, extRslt},
+		{path(j.svr.Name, "nothing"), "", http.StatusNotFound, extRslt},
+		{path("nothing", "list my open cases"), "", http.StatusNotFound,
+			extRslt},
+		{path(j.svr.Name, "list my open cases"), "{", http.StatusBadRequest,
+			extInpt},
+	} {
+		resp = serveResp{}
+		if status := serveReq(t, api, http.MethodPost, c.path, serveToken,
+			c.body, &resp); status != c.status || resp.Code != c.code ||
+			len(resp.Errors) < 1 {
+			t.Error(c.path, "got", status, resp)
+		}
+	}
+	for _, f := range []*fakeSvr{j, g} {
+		f.mu.Lock()
+		for _, req := range f.reqs[f.got:] {
+			t.Error("request not sent", req.req)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func TestServeLoopback(t *testing.T) {
+	for addr, exp := range map[string]bool{
+		"127.0.0.1:8080": true, "[::1]:80": true, "localhost:1": true,
+		":8080": false, "0.0.0.0:80": false, "192.168.0.1:80": false,
+	} {
+		if serveLoopback(addr) != exp {
+			t.Error(addr, exp, "expected")
+		}
+	}
+}
+
+// TestServeSameSvr runs actions on one server at the same time,
+// changing its project, to be run with -race
+func TestServeSameSvr(t *testing.T) {
+	user, svrsCfg, tokens := cfg.User, cfg.Svrs, cfg.Serve.Tokens
+	defer func() {
+		cfg.User, cfg.Svrs, cfg.Serve.Tokens = user, svrsCfg, tokens
+	}()
+	cfg.User = fakeUser
+	cfg.Serve.Tokens = []passwords{{Pass: serveToken}}
+	issue, err := os.ReadFile(filepath.Join("testdata", "jira", "issue.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(issue)
+	}))
+	defer j.Close()
+	cfg.Svrs = []svrs{{Type: CategoryJira, Name: "fake" + CategoryJira,
+		URL: j.URL + "/", User: fakeUser, Proj: "X",
+		Pass: passwords{Type: PassBasic, Pass: fakePass}}}
+	s, err := newServer(makeCat2Act(), params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := httptest.NewServer(s.handler())
+	defer api.Close()
+	var wg sync.WaitGroup
+	for _, id := range []string{"A-1", "B-1", "C-1", "D-1"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var resp serveResp
+			if code := serveReq(t, api, http.MethodPost, "/servers/"+
+				cfg.Svrs[0].Name+"/actions/"+
+				url.PathEscape("show details of a case"), serveToken,
+				`{"id":"`+id+`"}`, &resp); code != http.StatusOK ||
+				len(resp.Results) != 1 {
+				t.Error(id, "got", code, resp)
+			}
+		}()
+	}
+	wg.Wait()
+	if p := cfg.Svrs[0].Proj; p != "A" && p != "B" && p != "C" && p != "D" {
+		t.Error("project of one of the cases expected, got", p)
+	}
+}
@@ -0,0 +1,572 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitee.com/bon-ami/eztools/v6"
+	"golang.org/x/term"
+)
+
+const (
+	// shellCmd is the command line argument to start the shell
+	shellCmd = "shell"
+	// shellPrev is the variable of the previous result set
+	shellPrev = "_"
+	// shellHistMax is the max number of lines of history kept
+	shellHistMax = 500
+	// shellIDsMax is the max number of IDs seen kept for completion
+	shellIDsMax = 200
+)
+
+// errShellExit is returned by exec on exit of the shell
+var errShellExit = errors.New("exit")
+
+// shellBuiltins are commands of the shell, besides actions
+var shellBuiltins = []string{"help", "servers", "actions", "vars", "save",
+	"exit", "quit"}
+
+// shellVar is a reference to a result set, as $_, $_[0] or $_.id
+var shellVar = regexp.MustCompile(`^\$(\w+)(?:\[(\d+)\])?(?:\.(\w+))?$`)
+
+// shellName is a name of a result set to save
+var shellName = regexp.MustCompile(`^\w+$`)
+
+// shellFlags are params of actions, as the command line ones
+var shellFlags = map[string]func(*params) *string{
+	"-i":         func(p *params) *string { return &p.i },
+	"-k":         func(p *params) *string { return &p.k },
+	"-hd":        func(p *params) *string { return &p.hd },
+	"-p":         func(p *params) *string { return &p.p },
+	"-b":         func(p *params) *string { return &p.b },
+	"-l":         func(p *params) *string { return &p.l },
+	"-f":         func(p *params) *string { return &p.f },
+	"-z":         func(p *params) *string { return &p.z },
+	"-c":         func(p *params) *string { return &p.c },
+	"-estimate":  func(p *params) *string { return &p.estimate },
+	"-to-status": func(p *params) *string { return &p.toStatus },
+}
+
+// shell runs commands of actions on servers, keeping the server,
+// auth info and results among them
+type shell struct {
+	ctx  context.Context
+	cats cat2Act
+	para params
+	// svr is the current server
+	svr   *svrs
+	auths map[string]svrAuth
+	// vars are result sets, as $_ for the previous one
+	vars map[string]IssueInfoSlc
+	// ids are IDs recently seen in results, the latest last
+	ids []string
+}
+
+// shellHistFile is the history file besides the config file
+func shellHistFile() string {
+	if len(cfgFile) < 1 {
+		return module + ".history"
+	}
+	return strings.TrimSuffix(cfgFile, filepath.Ext(cfgFile)) + ".history"
+}
+
+// shellHistLoad reads the latest lines of the history file
+func shellHistLoad(file string) []string {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			Log(false, false, err)
+		}
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	if len(lines) > shellHistMax {
+		lines = lines[len(lines)-shellHistMax:]
+	}
+	return slices.DeleteFunc(lines, func(s string) bool {
+		return len(s) < 1
+	})
+}
+
+// shellHistAdd appends a line to the history file
+func shellHistAdd(file, line string) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		Log(false, false, err)
+		return
+	}
+	defer f.Close()
+	if _, err = f.WriteString(line + "\n"); err != nil {
+		Log(false, false, err)
+	}
+}
+
+// shellRun runs the shell on stdin, with line editing on a terminal.
+// Commands from other input are run without interactions.
+func shellRun(ctx context.Context, cats cat2Act, para params) []error {
+	sh := &shell{ctx: ctx, cats: cats, para: para,
+		auths: make(map[string]svrAuth),
+		vars:  make(map[string]IssueInfoSlc)}
+	if svr, ok := mkSvrFromParam(para.r); ok {
+		sh.svr = svr
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		uiSilent = true
+		var errs []error
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			err := sh.exec(scanner.Text())
+			if errors.Is(err, errShellExit) {
+				break
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errs
+	}
+	histFile := shellHistFile()
+	ed := &lineEd{in: os.Stdin, out: logWriter,
+		history: shellHistLoad(histFile), complete: sh.complete}
+	showStrln("\"help\" for commands, Ctrl-D or \"exit\" to quit")
+	for {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return []error{err}
+		}
+		line, err := ed.readLine(sh.prompt())
+		term.Restore(fd, state)
+		switch {
+		case errors.Is(err, errLineIntr):
+			continue
+		case err != nil:
+			// EOF
+			return nil
+		}
+		if line = strings.TrimSpace(line); len(line) < 1 {
+			continue
+		}
+		if n := len(ed.history); n < 1 || ed.history[n-1] != line {
+			ed.history = append(ed.history, line)
+			shellHistAdd(histFile, line)
+		}
+		err = sh.exec(line)
+		if errors.Is(err, errShellExit) {
+			return nil
+		}
+		if err != nil {
+			Log(true, false, err)
+		}
+	}
+}
+
+// prompt shows the current server
+func (sh *shell) prompt() string {
+	if sh.svr == nil {
+		return module + "> "
+	}
+	return strings.ToLower(sh.svr.Type) + ":" + sh.svr.Name + "> "
+}
+
+// shellTokens splits a line by spaces, with quoted strings kept whole
+func shellTokens(line string) ([]string, error) {
+	var tokens []string
+	for line = strings.TrimSpace(line); len(line) > 0; line =
+		strings.TrimSpace(line) {
+		switch line[0] {
+		case '"', '`':
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", eztools.ErrInvalidInput, line)
+			}
+			line = line[len(quoted):]
+			unquoted, _ := strconv.Unquote(quoted)
+			tokens = append(tokens, unquoted)
+		default:
+			end := strings.IndexAny(line, " \t")
+			if end < 0 {
+				end = len(line)
+			}
+			tokens = append(tokens, line[:end])
+			line = line[end:]
+		}
+	}
+	return tokens, nil
+}
+
+// svrOf matches a server by type:name or name
+func svrOf(tok string) *svrs {
+	tp, name, ok := strings.Cut(tok, ":")
+	if !ok {
+		return matchSvr(cfg.Svrs, tok)
+	}
+	svr := matchSvr(cfg.Svrs, name)
+	if svr == nil || !strings.EqualFold(svr.Type, tp) {
+		return nil
+	}
+	return svr
+}
+
+// actionOf matches an action by its full name, or by words
+// that words of its name begin with, the shortest one if multiple.
+// Return values: the action, and the number of tokens of it
+func actionOf(acts []action2Func, tokens []string) (action2Func, int, error) {
+	for n := len(tokens); n > 0; n-- {
+		words := strings.ToLower(strings.Join(tokens[:n], " "))
+		var matched []action2Func
+		for _, act := range acts {
+			if act.n == words {
+				return act, n, nil
+			}
+			names := strings.Fields(act.n)
+			if !slices.ContainsFunc(tokens[:n], func(tok string) bool {
+				return !slices.ContainsFunc(names, func(name string) bool {
+					return strings.HasPrefix(name, strings.ToLower(tok))
+				})
+			}) {
+				matched = append(matched, act)
+			}
+		}
+		if len(matched) < 1 {
+			continue
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			return len(matched[i].n) < len(matched[j].n)
+		})
+		if len(matched) > 1 && len(matched[0].n) == len(matched[1].n) {
+			return action2Func{}, 0, fmt.Errorf("%w: %q for both %q and %q",
+				eztools.ErrInvalidInput, words, matched[0].n, matched[1].n)
+		}
+		return matched[0], n, nil
+	}
+	return action2Func{}, 0, fmt.Errorf("%w: NO action for %q",
+		eztools.ErrInvalidInput, strings.Join(tokens, " "))
+}
+
+// exec runs a command of a line
+func (sh *shell) exec(line string) error {
+	tokens, err := shellTokens(line)
+	if err != nil || len(tokens) < 1 {
+		return err
+	}
+	switch tokens[0] {
+	case "exit", "quit":
+		return errShellExit
+	case "help", "?":
+		sh.help()
+		return nil
+	case "servers":
+		for _, svr := range cfg.Svrs {
+			showStrln(strings.ToLower(svr.Type) + ":" + svr.Name)
+		}
+		return nil
+	case "actions":
+		if sh.svr == nil {
+			return fmt.Errorf("%w: NO server chosen", eztools.ErrInvalidInput)
+		}
+		for _, act := range sh.cats[sh.svr.Type] {
+			showStrln(act.n)
+		}
+		return nil
+	case "vars":
+		names := make([]string, 0, len(sh.vars))
+		for name := range sh.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			showStrln("$"+name, len(sh.vars[name]), "results")
+		}
+		return nil
+	case "save":
+		if len(tokens) != 2 || tokens[1] == shellPrev ||
+			!shellName.MatchString(tokens[1]) {
+			return fmt.Errorf("%w: save NAME", eztools.ErrInvalidInput)
+		}
+		sh.vars[tokens[1]] = sh.vars[shellPrev]
+		return nil
+	}
+	if svr := svrOf(tokens[0]); svr != nil {
+		sh.svr = svr
+		tokens = tokens[1:]
+		if len(tokens) < 1 {
+			return nil
+		}
+	}
+	if sh.svr == nil {
+		return fmt.Errorf("%w: NO server chosen, by type:name first",
+			eztools.ErrInvalidInput)
+	}
+	act, n, err := actionOf(sh.cats[sh.svr.Type], tokens)
+	if err != nil {
+		return err
+	}
+	infs, err := sh.input(tokens[n:])
+	if err != nil {
+		return err
+	}
+	return sh.run(act, infs)
+}
+
+// run runs an action on the current server, with the results kept as $_
+func (sh *shell) run(act action2Func, infs IssueInfoSlc) error {
+	auth, ok := sh.auths[sh.svr.Name]
+	if !ok {
+		authInfo, hdr, err := cfg2AuthInfo(*sh.svr, cfg)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errCfg, err)
+		}
+		auth = svrAuth{authInfo, hdr}
+		sh.auths[sh.svr.Name] = auth
+	}
+	// asking for what is lacking, as the command line does
+	if !uiSilent && len(infs) == 1 &&
+		inputIssueInfo4Act(withClient(sh.ctx, sh.svr, auth.hdr), sh.svr,
+			auth.authInfo, act.n, infs[0]) {
+		return fmt.Errorf("%w: %s", eztools.ErrInvalidInput, act.n)
+	}
+	looper := DefLooper{para: sh.para, svr: sh.svr,
+		authInfo: auth.authInfo, hdr: auth.hdr,
+		maxResults: sh.para.limit}
+	var done bool
+	errs := LoopActions(sh.ctx, sh.svr, nil, nil, &looper,
+		func() (string, actionFunc, IssueInfoSlc) {
+			if done {
+				return "", nil, nil
+			}
+			done = true
+			return act.n, act.f, infs
+		})
+	if res := looper.GetIssueInfo(); len(res) > 0 || len(errs) < 1 {
+		sh.vars[shellPrev] = res
+		sh.seen(res)
+	}
+	errSummary(errs)
+	return errors.Join(errs...)
+}
+
+// seen keeps IDs of results for completion
+func (sh *shell) seen(res IssueInfoSlc) {
+	for _, r := range res {
+		id := r[IssueinfoStrID]
+		if len(id) < 1 {
+			continue
+		}
+		sh.ids = append(slices.DeleteFunc(sh.ids, func(s string) bool {
+			return s == id
+		}), id)
+	}
+	if len(sh.ids) > shellIDsMax {
+		sh.ids = sh.ids[len(sh.ids)-shellIDsMax:]
+	}
+}
+
+// input makes input of an action from arguments, which are IDs,
+// params as -c comments, or key=value.
+// A result set alone, as $_, as IDs runs the action on each of them.
+// Other references to result sets are replaced with values joined.
+func (sh *shell) input(args []string) (IssueInfoSlc, error) {
+	var (
+		p      params
+		ids    []string
+		kvs    = make(IssueInfos)
+		former IssueInfoSlc
+	)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if f, ok := shellFlags[arg]; ok || arg == "-s" {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%w: value of %s",
+					eztools.ErrInvalidInput, arg)
+			}
+			i++
+			val, err := sh.expand(args[i])
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				// -s may be multiple
+				p.s = append(p.s, val)
+				continue
+			}
+			*f(&p) = val
+			continue
+		}
+		if k, v, ok := strings.Cut(arg, "="); ok && len(k) > 0 &&
+			!strings.HasPrefix(k, "$") {
+			val, err := sh.expand(v)
+			if err != nil {
+				return nil, err
+			}
+			kvs[k] = val
+			continue
+		}
+		if m := shellVar.FindStringSubmatch(arg); m != nil &&
+			len(m[2]) < 1 && len(m[3]) < 1 {
+			res, ok := sh.vars[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("%w: NO $%s",
+					eztools.ErrInvalidInput, m[1])
+			}
+			former = append(former, res...)
+			continue
+		}
+		val, err := sh.expand(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, val)
+	}
+	inf := mkIssueinfo(p)
+	if len(ids) > 0 {
+		inf[IssueinfoStrID] = strings.Join(ids, issueSeparator)
+	}
+	for k, v := range kvs {
+		inf[k] = v
+	}
+	if former == nil {
+		return IssueInfoSlc{inf}, nil
+	}
+	infs := make(IssueInfoSlc, len(former))
+	for i, r := range former {
+		infs[i] = make(IssueInfos)
+		for k, v := range r {
+			infs[i][k] = v
+		}
+		for k, v := range inf {
+			infs[i][k] = v
+		}
+	}
+	return infs, nil
+}
+
+// expand replaces a reference to a result set with its values,
+// of IDs by default, joined by issueSeparator
+func (sh *shell) expand(arg string) (string, error) {
+	m := shellVar.FindStringSubmatch(arg)
+	if m == nil {
+		return arg, nil
+	}
+	res, ok := sh.vars[m[1]]
+	if !ok {
+		return "", fmt.Errorf("%w: NO $%s", eztools.ErrInvalidInput, m[1])
+	}
+	if len(m[2]) > 0 {
+		i, _ := strconv.Atoi(m[2])
+		if i >= len(res) {
+			return "", fmt.Errorf("%w: %s of %d results",
+				eztools.ErrOutOfBound, arg, len(res))
+		}
+		res = res[i : i+1]
+	}
+	key := m[3]
+	if len(key) < 1 {
+		key = IssueinfoStrID
+	}
+	var vals []string
+	for _, r := range res {
+		if v, ok := r[key]; ok {
+			vals = append(vals, v)
+		}
+	}
+	return strings.Join(vals, issueSeparator), nil
+}
+
+// complete completes servers, commands and actions,
+// or IDs seen and variables after them
+func (sh *shell) complete(line string) (string, []string) {
+	svr, rest := sh.svr, strings.TrimLeft(line, " ")
+	if first, after, ok := strings.Cut(rest, " "); ok {
+		if s := svrOf(first); s != nil {
+			svr, rest = s, strings.TrimLeft(after, " ")
+		}
+	}
+	head := line[:len(line)-len(rest)]
+	var candidates []string
+	if svr != nil {
+		for _, act := range sh.cats[svr.Type] {
+			if strings.HasPrefix(act.n, rest) {
+				candidates = append(candidates, act.n)
+			}
+		}
+	}
+	if len(candidates) < 1 && len(head) < 1 && !strings.Contains(rest, " ") {
+		for _, s := range cfg.Svrs {
+			candidates = append(candidates,
+				strings.ToLower(s.Type)+":"+s.Name, s.Name)
+		}
+		candidates = append(candidates, shellBuiltins...)
+		candidates = slices.DeleteFunc(candidates, func(s string) bool {
+			return !strings.HasPrefix(s, rest)
+		})
+	}
+	if len(candidates) > 0 {
+		return head + completeCommon(rest, candidates), candidates
+	}
+	// the last word as an ID or a variable
+	i := strings.LastIndexAny(line, " ,=") + 1
+	word := line[i:]
+	if len(word) < 1 {
+		return line, nil
+	}
+	// the latest first
+	for i := len(sh.ids) - 1; i >= 0; i-- {
+		if strings.HasPrefix(sh.ids[i], word) {
+			candidates = append(candidates, sh.ids[i])
+		}
+	}
+	for name := range sh.vars {
+		if strings.HasPrefix("$"+name, word) {
+			candidates = append(candidates, "$"+name)
+		}
+	}
+	return line[:i] + completeCommon(word, candidates), candidates
+}
+
+// completeCommon completes s to the common prefix of candidates,
+// with a space after it if only one
+func completeCommon(s string, candidates []string) string {
+	switch len(candidates) {
+	case 0:
+		return s
+	case 1:
+		return candidates[0] + " "
+	}
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(common) < len(s) {
+		return s
+	}
+	return common
+}
+
+// help shows commands of the shell
+func (sh *shell) help() {
+	for _, s := range []string{
+		"[type:name] action [IDs] [-c comments] [-k key] [key=value]...",
+		"\trun an action on a server, the current one if not given,",
+		"\tby its name, or words its name begins with, as \"detail X-1\"",
+		"type:name\tchoose the current server",
+		"$_\tthe previous results, as IDs to run an action on each of",
+		"$_[0].key\tvalues of the previous results, IDs by default",
+		"save NAME\tkeep the previous results as $NAME",
+		"servers, actions, vars\tlist servers, actions of the current " +
+			"server, or saved results",
+		"exit, quit\tquit the shell"} {
+		showStrln(s)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestLineEd(t *testing.T) {
+	for _, c := range []struct {
+		in, exp string
+		err     error
+	}{
+		{in: "ab\x7fc\x1b[Dx\r", exp: "axc"},
+		{in: "é\x01x\x05y\n", exp: "xéy"},
+		{in: "abc\x1b[D\x1b[D\x15\r", exp: "bc"},
+		{in: "abc\x1b[H\x1b[3~\r", exp: "bc"},
+		{in: "\x1b[A\x1b[A\x1b[A\x1b[B\r", exp: "two"},
+		{in: "x\x1b[A\x1b[B\r", exp: "x"},
+		{in: "com\tx\r", exp: "completedx"},
+		{in: "x\x03", err: errLineIntr},
+		{in: "\x04", err: io.EOF},
+		{in: "x", err: io.EOF},
+	} {
+		var out strings.Builder
+		e := lineEd{in: strings.NewReader(c.in), out: &out,
+			history: []string{"one", "two", "three"},
+			complete: func(line string) (string, []string) {
+				return line + "pleted", nil
+			}}
+		got, err := e.readLine("> ")
+		if got != c.exp || !errors.Is(err, c.err) {
+			t.Errorf("%q: %q expected, got %q, %v", c.in, c.exp, got, err)
+		}
+	}
+}
+
+func TestShellActionOf(t *testing.T) {
+	cats := makeCat2Act()
+	for _, c := range []struct {
+		tp     string
+		tokens []string
+		exp    string
+		n      int
+	}{
+		{CategoryGerrit, []string{"score", "4711"}, "add scores to a submit", 1},
+		{CategoryJira, []string{"detail", "X-12"}, "show details of a case", 1},
+		{CategoryJira, []string{"show", "details", "of", "a", "case", "X-1"},
+			"show details of a case", 5},
+		{CategoryJira, []string{"add", "comm", "X-1"},
+			"add a comment to a case", 2},
+		{CategoryJira, []string{"comment", "X-1"}, "", 0},
+		{CategoryJira, []string{"nothing"}, "", 0},
+	} {
+		act, n, err := actionOf(cats[c.tp], c.tokens)
+		switch {
+		case len(c.exp) < 1:
+			if !errors.Is(err, eztools.ErrInvalidInput) {
+				t.Error(c.tokens, "invalid input expected, got", act.n, err)
+			}
+		case act.n != c.exp || n != c.n || err != nil:
+			t.Errorf("%v: %q of %d expected, got %q of %d, %v",
+				c.tokens, c.exp, c.n, act.n, n, err)
+		}
+	}
+}
+
+func TestShellExec(t *testing.T) {
+	silent, user, svrsCfg := uiSilent, cfg.User, cfg.Svrs
+	defer func() {
+		uiSilent, cfg.User, cfg.Svrs = silent, user, svrsCfg
+	}()
+	uiSilent, cfg.User = true, fakeUser
+	j := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "GET /rest/api/latest/search?jql=" +
+			"assignee%3Dtester%26status%21%3DClosed", resp: "search.json"},
+		{req: "POST " + jiraFakeIssue + `/comment {"body":"Seen."}`,
+			resp: "comment.json", code: http.StatusCreated},
+		{req: "POST /rest/api/latest/issue/X-2/comment {\"body\":\"Seen.\"}",
+			resp: "comment.json", code: http.StatusCreated}})
+	defer j.Close()
+	cfg.Svrs = []svrs{j.svr}
+	sh := &shell{ctx: context.Background(), cats: makeCat2Act(),
+		auths: make(map[string]svrAuth),
+		vars:  make(map[string]IssueInfoSlc)}
+	if err := sh.exec("list my open cases"); !errors.Is(err,
+		eztools.ErrInvalidInput) {
+		t.Error("no server expected, got", err)
+	}
+	for _, line := range []string{
+		"jira:fakeJIRA list my open cases",
+		"save mine",
+		`add comment $mine -c "Seen."`,
+	} {
+		if err := sh.exec(line); err != nil {
+			t.Fatal(line, err)
+		}
+	}
+	if sh.svr != &cfg.Svrs[0] || len(sh.vars["mine"]) != 2 ||
+		len(sh.vars[shellPrev]) != 2 {
+		t.Error("2 results of each action expected, got", sh.vars)
+	}
+	for in, exp := range map[string]string{
+		"$mine":            "X-1,X-2",
+		"$mine[1].summary": "Typo in help",
+		"$mine.status":     "Open,In Progress",
+		"X-$mine":          "X-$mine",
+	} {
+		if got, err := sh.expand(in); got != exp || err != nil {
+			t.Errorf("%s: %q expected, got %q, %v", in, exp, got, err)
+		}
+	}
+	if _, err := sh.expand("$mine[2]"); !errors.Is(err, eztools.ErrOutOfBound) {
+		t.Error("out of bound expected, got", err)
+	}
+	infs, err := sh.input([]string{"X-3", "X-4", "-k", "$mine[0]",
+		"branch=main", "-s", "Labels+=a", "-s", "summary=b"})
+	if err != nil || len(infs) != 1 || infs[0][IssueinfoStrID] != "X-3,X-4" ||
+		infs[0][IssueinfoStrKey] != "X-1" || infs[0][IssueinfoStrBranch] != "main" ||
+		infs[0][IssueinfoStrFields] != "Labels+=a\nsummary=b" {
+		t.Error("input got", infs, err)
+	}
+	for _, c := range []struct {
+		line, exp  string
+		candidates []string
+	}{
+		{"fake", "fakeJIRA ", []string{"fakeJIRA"}},
+		{"jira:fakeJIRA add a comm", "jira:fakeJIRA add a comment to a case ",
+			[]string{"add a comment to a case"}},
+		{"detail X-", "detail X-", []string{"X-2", "X-1"}},
+		{"detail X-1,X", "detail X-1,X-", []string{"X-2", "X-1"}},
+		{"detail $m", "detail $mine ", []string{"$mine"}},
+		{"detail Y", "detail Y", nil},
+	} {
+		got, candidates := sh.complete(c.line)
+		if got != c.exp || !slices.Equal(candidates, c.candidates) {
+			t.Errorf("%q: %q of %q expected, got %q of %q", c.line,
+				c.exp, c.candidates, got, candidates)
+		}
+	}
+	if err := sh.exec("exit"); !errors.Is(err, errShellExit) {
+		t.Error("exit expected, got", err)
+	}
+}
+
+func TestShellInput(t *testing.T) {
+	silent, user, svrsCfg, stdin := uiSilent, cfg.User, cfg.Svrs, os.Stdin
+	defer func() {
+		uiSilent, cfg.User, cfg.Svrs, os.Stdin = silent, user, svrsCfg, stdin
+	}()
+	uiSilent, cfg.User = false, fakeUser
+	j := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "POST " + jiraFakeIssue + `/comment {"body":"Seen."}`,
+			resp: "comment.json", code: http.StatusCreated}})
+	defer j.Close()
+	cfg.Svrs = []svrs{j.svr}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	os.Stdin = r
+	// the comment is asked for
+	io.WriteString(w, "Seen.\n")
+	w.Close()
+	sh := &shell{ctx: context.Background(), cats: makeCat2Act(),
+		auths: make(map[string]svrAuth),
+		vars:  make(map[string]IssueInfoSlc)}
+	if err := sh.exec("jira:fakeJIRA add comment X-1"); err != nil {
+		t.Fatal(err)
+	}
+	if j.got != len(j.reqs) {
+		t.Error("comment to be added")
+	}
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// svrClient sends requests to a server, by its own transport
+// with TLS and proxy settings, leaving http.DefaultTransport as it is,
+// and keeps cookies and headers of sessions with it
+type svrClient struct {
+	client *http.Client
+	ua     string
+	// err is of settings of the server, returned for all requests
+	err error
+	// sessMu serializes getting sessions
+	sessMu sync.Mutex
+	// mu guards sess
+	mu sync.Mutex
+	// sess are headers of all requests to the server by names of sessions,
+	// such as the crumb of Jenkins, empty for sessions needing none
+	sess map[string]http.Header
+}
+
+// newSvrClient makes the client of a server
+func newSvrClient(svr svrs) *svrClient {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &svrClient{err: fmt.Errorf("%w: unknown default transport",
+			errCfg)}
+	}
+	tr, err := svrTransportOf(base, svr)
+	if err != nil {
+		return &svrClient{err: fmt.Errorf("%w: server %s: %w", errCfg,
+			svr.Name, err)}
+	}
+	// no errors without options
+	jar, _ := cookiejar.New(nil)
+	return &svrClient{client: &http.Client{Transport: tr, Jar: jar},
+		ua: svr.UserAgent}
+}
+
+// setClients makes clients of servers, checking their TLS and proxy settings
+func setClients(svrList []svrs) error {
+	for i := range svrList {
+		svrList[i].client = newSvrClient(svrList[i])
+		if err := svrList[i].client.err; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clientOf gets the client of a server, made if not yet
+func clientOf(svr *svrs) *svrClient {
+	cfgMu.Lock()
+	defer cfgMu.Unlock()
+	if svr.client == nil {
+		svr.client = newSvrClient(*svr)
+	}
+	return svr.client
+}
+
+// clientKey is the key of svrClient in contexts
+type clientKey struct{}
+
+// hdrKey is the key of headers of requests in contexts
+type hdrKey struct{}
+
+// withClient sets the client of a server, and headers of requests
+// by cfg2AuthInfo, to ctx, for requests of actions on the server
+func withClient(ctx context.Context, svr *svrs,
+	hdr http.Header) context.Context {
+	ctx = context.WithValue(ctx, clientKey{}, clientOf(svr))
+	return context.WithValue(ctx, hdrKey{}, hdr)
+}
+
+// reqHdr makes headers of a request, of ctx and hdrs
+func reqHdr(ctx context.Context, hdrs map[string]string) http.Header {
+	hdr, _ := ctx.Value(hdrKey{}).(http.Header)
+	hdr = hdr.Clone()
+	if hdr == nil {
+		hdr = make(http.Header)
+	}
+	for k, v := range hdrs {
+		hdr.Set(k, v)
+	}
+	return hdr
+}
+
+// clientCtx gets the client of ctx, or one by default settings
+func clientCtx(ctx context.Context) *svrClient {
+	if c, ok := ctx.Value(clientKey{}).(*svrClient); ok {
+		return c
+	}
+	return &svrClient{client: http.DefaultClient}
+}
+
+// setSess gets headers of a session with a server by get, once for
+// its client, or again if renew, to send them with all following requests
+// to the server. Cookies replied are kept by the client, too.
+func setSess(svr *svrs, name string, renew bool,
+	get func() (http.Header, error)) error {
+	c := clientOf(svr)
+	if c.err != nil {
+		return c.err
+	}
+	c.sessMu.Lock()
+	defer c.sessMu.Unlock()
+	c.mu.Lock()
+	_, ok := c.sess[name]
+	if renew {
+		// not to be sent to get it again
+		delete(c.sess, name)
+	}
+	c.mu.Unlock()
+	if ok && !renew {
+		return nil
+	}
+	hdr, err := get()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sess == nil {
+		c.sess = make(map[string]http.Header)
+	}
+	c.sess[name] = hdr
+	return nil
+}
+
+// newReq makes a request with auth info, headers of sessions and hdr,
+// and a type of the body, if any, defaulting to JSON
+func (c *svrClient) newReq(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, hdr http.Header, body []byte,
+	bodyType string) (*http.Request, error) {
+	var bodyReq io.Reader
+	if body != nil {
+		bodyReq = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReq)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		if len(bodyType) < 1 {
+			bodyType = "application/json"
+		}
+		req.Header.Set("Content-Type", bodyType)
+	}
+	switch authInfo.Type {
+	case eztools.AuthBasic:
+		req.SetBasicAuth(authInfo.User, authInfo.Pass)
+	case eztools.AuthPlain:
+		// BASE64ed already
+		req.Header.Set("Authorization", "Basic "+authInfo.Pass)
+	}
+	if len(c.ua) > 0 {
+		req.Header.Set("User-Agent", c.ua)
+	}
+	c.mu.Lock()
+	for _, sess := range c.sess {
+		for k, v := range sess {
+			req.Header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	c.mu.Unlock()
+	for k, v := range hdr {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	return req, nil
+}
+
+// send sends a request to the server, with auth info, as newReq makes it.
+// For digest auth, it is sent again with the digest of the challenge.
+func (c *svrClient) send(ctx context.Context, method, url string,
+	authInfo eztools.AuthInfo, hdr http.Header, bodyReq io.Reader,
+	bodyType string) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	var body []byte
+	if bodyReq != nil {
+		var err error
+		if body, err = io.ReadAll(bodyReq); err != nil {
+			return nil, err
+		}
+	}
+	req, err := c.newReq(ctx, method, url, authInfo, hdr, body, bodyType)
+	if err != nil {
+		// such as an invalid URL of the server
+		return nil, fmt.Errorf("%w: %w", errCfg, err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil || authInfo.Type != eztools.AuthDigest ||
+		resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	authz, err := digestAuthz(resp.Header.Get("WWW-Authenticate"), method,
+		req.URL.RequestURI(), authInfo.User, authInfo.Pass, "")
+	if err != nil {
+		// not a challenge of digest auth, replied as it is
+		Log(false, false, err)
+		return resp, nil
+	}
+	resp.Body.Close()
+	if req, err = c.newReq(ctx, method, url, authInfo, hdr,
+		body, bodyType); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authz)
+	return c.client.Do(req)
+}
+
+// digestAuthz makes Authorization of digest auth, RFC 7616, of MD5
+// or SHA-256, for a challenge in WWW-Authenticate, with a random cnonce
+// if empty
+func digestAuthz(chal, method, uri, user, pass, cnonce string) (string,
+	error) {
+	scheme, params, _ := strings.Cut(strings.TrimSpace(chal), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return "", fmt.Errorf("%w: NO digest challenge in %q", errAuth, chal)
+	}
+	p := digestParams(params)
+	alg := strings.ToUpper(p["algorithm"])
+	var h func(string) string
+	switch strings.TrimSuffix(alg, "-SESS") {
+	case "", "MD5":
+		h = func(s string) string {
+			sum := md5.Sum([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+	case "SHA-256":
+		h = func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+	default:
+		return "", fmt.Errorf("%w: digest algorithm %s not supported",
+			errAuth, alg)
+	}
+	if len(cnonce) < 1 {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		cnonce = hex.EncodeToString(b)
+	}
+	const nc = "00000001"
+	ha1 := h(user + ":" + p["realm"] + ":" + pass)
+	if strings.HasSuffix(alg, "-SESS") {
+		ha1 = h(ha1 + ":" + p["nonce"] + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+	var qop string
+	for _, q := range strings.Split(p["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+		}
+	}
+	authz := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", `+
+		`uri="%s"`, user, p["realm"], p["nonce"], uri)
+	if len(qop) > 0 {
+		authz += fmt.Sprintf(`, response="%s", qop=%s, nc=%s, cnonce="%s"`,
+			h(ha1+":"+p["nonce"]+":"+nc+":"+cnonce+":"+qop+":"+ha2),
+			qop, nc, cnonce)
+	} else {
+		authz += fmt.Sprintf(`, response="%s"`, h(ha1+":"+p["nonce"]+":"+ha2))
+	}
+	if len(p["algorithm"]) > 0 {
+		authz += ", algorithm=" + p["algorithm"]
+	}
+	if opaque, ok := p["opaque"]; ok {
+		authz += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+	return authz, nil
+}
+
+// digestParams parses params of a challenge, as key=value or key="value",
+// separated by commas, which may be in quoted values
+func digestParams(s string) map[string]string {
+	ret := make(map[string]string)
+	for len(s) > 0 {
+		var k string
+		k, s, _ = strings.Cut(s, "=")
+		k = strings.ToLower(strings.Trim(k, " ,"))
+		var v string
+		if s = strings.TrimLeft(s, " "); strings.HasPrefix(s, `"`) {
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			v = strings.ReplaceAll(s[1:min(end, len(s))], `\`, "")
+			_, s, _ = strings.Cut(s[min(end+1, len(s)):], ",")
+		} else {
+			v, s, _ = strings.Cut(s, ",")
+			v = strings.TrimSpace(v)
+		}
+		if len(k) > 0 {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// svrTransportOf makes a transport of a server from the base one
+func svrTransportOf(base *http.Transport, svr svrs) (*http.Transport,
+	error) {
+	tr := base.Clone()
+	tlsCfg := &tls.Config{InsecureSkipVerify: svr.Insecure}
+	if len(svr.CA) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(svr.CA)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("NO certificates in %s", svr.CA)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if len(svr.Cert) > 0 {
+		key := svr.Key
+		if len(key) < 1 {
+			// both in one file
+			key = svr.Cert
+		}
+		cert, err := tls.LoadX509KeyPair(svr.Cert, key)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	tr.TLSClientConfig = tlsCfg
+	if len(svr.Proxy) > 0 {
+		proxy, err := url.Parse(svr.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("proxy scheme must be http, https "+
+				"or socks5, not %q", proxy.Scheme)
+		}
+		noProxy := svrNoProxy(svr)
+		tr.Proxy = func(req *http.Request) (*url.URL, error) {
+			if noProxyMatch(noProxy, req.URL) {
+				return nil, nil
+			}
+			return proxy, nil
+		}
+	}
+	return tr, nil
+}
+
+// svrNoProxy is noproxy of a server, or $NO_PROXY
+func svrNoProxy(svr svrs) string {
+	if len(svr.NoProxy) > 0 {
+		return svr.NoProxy
+	}
+	if s := os.Getenv("NO_PROXY"); len(s) > 0 {
+		return s
+	}
+	return os.Getenv("no_proxy")
+}
+
+// noProxyMatch checks a URL against a NO_PROXY list, separated by
+// commas, of "*", hosts with optional ports, which match their
+// subdomains too, with or without a leading dot, IPs and CIDRs
+func noProxyMatch(noProxy string, u *url.URL) bool {
+	host, port := u.Hostname(), u.Port()
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case len(entry) < 1:
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost, entryPort := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, p
+		}
+		if len(entryPort) > 0 && entryPort != port {
+			continue
+		}
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		entryHost = strings.TrimPrefix(entryHost, ".")
+		host := strings.ToLower(host)
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// svrNetDesc describes effective TLS and proxy settings of a server
+func svrNetDesc(svr svrs) string {
+	tlsDesc := "verified"
+	if svr.Insecure {
+		tlsDesc = "INSECURE, not verified"
+	}
+	if len(svr.CA) > 0 {
+		tlsDesc += " with ca " + svr.CA
+	} else {
+		tlsDesc += " with system CAs"
+	}
+	if len(svr.Cert) > 0 {
+		tlsDesc += ", client cert " + svr.Cert
+		if len(svr.Key) > 0 {
+			tlsDesc += " and key " + svr.Key
+		}
+	}
+	proxyDesc := "from environment"
+	if len(svr.Proxy) > 0 {
+		proxyDesc = svr.Proxy
+		if u, err := url.Parse(svr.Proxy); err == nil {
+			// without passwords
+			proxyDesc = u.Redacted()
+		}
+		if noProxy := svrNoProxy(svr); len(noProxy) > 0 {
+			proxyDesc += " except " + noProxy
+		}
+	} else if u, err := url.Parse(svr.URL); err == nil {
+		if p, err := http.ProxyFromEnvironment(&http.Request{URL: u}); err == nil {
+			if p == nil {
+				proxyDesc = "none"
+			} else {
+				proxyDesc = p.Redacted() + " from environment"
+			}
+		}
+	}
+	ret := "tls: " + tlsDesc + ", proxy: " + proxyDesc
+	if len(svr.UserAgent) > 0 {
+		ret += ", user agent: " + svr.UserAgent
+	}
+	return ret
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+// whereFilter is the filter of results from -where, if any
+var whereFilter whereFunc
+
+// whereFunc checks whether a result is taken
+type whereFunc func(IssueInfos) bool
+
+// whereVal gets a value of an operand, and whether it exists
+type whereVal func(IssueInfos) (string, bool)
+
+// whereTok is a token of an expression
+type whereTok struct {
+	s string
+	// quoted is for string literals
+	quoted bool
+}
+
+// whereOps are operators, the ones of two chars first
+var whereOps = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~",
+	"(", ")", "!", "<", ">"}
+
+// whereNow is now, or now-7d or now+1h, with units of s, m, h, d and w
+var whereNow = regexp.MustCompile(`^now(?:([+-])(\d+)([smhdw]))?$`)
+
+// whereTimeFmts are formats of times in results, such as ones of Jira
+// and Gerrit, and of dates
+var whereTimeFmts = []string{time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700", "2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05.000000000", "2006-01-02 15:04:05",
+	"2006-01-02"}
+
+// setWhere checks and sets the filter of results from -where
+func setWhere(expr string) error {
+	if len(expr) < 1 {
+		whereFilter = nil
+		return nil
+	}
+	f, err := whereParse(expr, time.Now())
+	if err != nil {
+		Log(true, false, err)
+		return err
+	}
+	whereFilter = f
+	return nil
+}
+
+// whereLex splits an expression into tokens
+func whereLex(expr string) ([]whereTok, error) {
+	var toks []whereTok
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+			continue
+		case c == '"' || c == '`':
+			quoted, err := strconv.QuotedPrefix(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: unclosed string at %d of %q",
+					eztools.ErrInvalidInput, i, expr)
+			}
+			s, _ := strconv.Unquote(quoted)
+			toks = append(toks, whereTok{s: s, quoted: true})
+			i += len(quoted)
+			continue
+		}
+		var op string
+		for _, o := range whereOps {
+			if strings.HasPrefix(expr[i:], o) {
+				op = o
+				break
+			}
+		}
+		if len(op) > 0 {
+			toks = append(toks, whereTok{s: op})
+			i += len(op)
+			continue
+		}
+		end := i
+		for end < len(expr) && whereWordChar(expr[end]) {
+			end++
+		}
+		if end == i {
+			return nil, fmt.Errorf("%w: unexpected %q at %d of %q",
+				eztools.ErrInvalidInput, expr[i], i, expr)
+		}
+		toks = append(toks, whereTok{s: expr[i:end]})
+		i = end
+	}
+	return toks, nil
+}
+
+// whereWordChar checks a char of keys, numbers, dates and now-7d
+func whereWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' || strings.IndexByte("_-+.:/", c) >= 0
+}
+
+// whereParser parses tokens of an expression, with now for relative times
+type whereParser struct {
+	toks []whereTok
+	i    int
+	now  time.Time
+	expr string
+}
+
+// whereParse parses an expression, such as
+// status != "Closed" && branch =~ "^release/" && created > now-7d
+func whereParse(expr string, now time.Time) (whereFunc, error) {
+	toks, err := whereLex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := whereParser{toks: toks, now: now, expr: expr}
+	f, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.toks) {
+		return nil, p.errAt("unexpected")
+	}
+	return f, nil
+}
+
+// errAt makes an error at the current token
+func (p *whereParser) errAt(what string) error {
+	if p.i >= len(p.toks) {
+		return fmt.Errorf("%w: %s end of %q", eztools.ErrInvalidInput,
+			what, p.expr)
+	}
+	return fmt.Errorf("%w: %s %q in %q", eztools.ErrInvalidInput,
+		what, p.toks[p.i].s, p.expr)
+}
+
+// next takes the current token if it is the operator op
+func (p *whereParser) next(op string) bool {
+	if p.i < len(p.toks) && !p.toks[p.i].quoted && p.toks[p.i].s == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *whereParser) or() (whereFunc, error) {
+	left, err := p.and()
+	for err == nil && p.next("||") {
+		var right whereFunc
+		if right, err = p.and(); err == nil {
+			l := left
+			left = func(inf IssueInfos) bool {
+				return l(inf) || right(inf)
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) and() (whereFunc, error) {
+	left, err := p.unary()
+	for err == nil && p.next("&&") {
+		var right whereFunc
+		if right, err = p.unary(); err == nil {
+			l := left
+			left = func(inf IssueInfos) bool {
+				return l(inf) && right(inf)
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) unary() (whereFunc, error) {
+	if p.next("!") {
+		f, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(inf IssueInfos) bool {
+			return !f(inf)
+		}, nil
+	}
+	if p.next("(") {
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(")") {
+			return nil, p.errAt("\")\" expected instead of")
+		}
+		return f, nil
+	}
+	return p.cmp()
+}
+
+// cmp parses a comparison, or an operand alone to check its existence
+func (p *whereParser) cmp() (whereFunc, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	var op string
+	for _, o := range []string{"==", "!=", "<=", ">=", "=~", "!~",
+		"<", ">"} {
+		if p.next(o) {
+			op = o
+			break
+		}
+	}
+	if len(op) < 1 {
+		return func(inf IssueInfos) bool {
+			v, ok := left(inf)
+			return ok && len(v) > 0
+		}, nil
+	}
+	rightTok := p.i
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if op == "=~" || op == "!~" {
+		return p.match(op == "=~", left, right, p.toks[rightTok])
+	}
+	return func(inf IssueInfos) bool {
+		a, aok := left(inf)
+		b, bok := right(inf)
+		return whereCmp(op, a, aok, b, bok)
+	}, nil
+}
+
+// match makes a regular expression match, compiled once if quoted
+func (p *whereParser) match(want bool, left, right whereVal,
+	tok whereTok) (whereFunc, error) {
+	var re *regexp.Regexp
+	if tok.quoted {
+		var err error
+		if re, err = regexp.Compile(tok.s); err != nil {
+			return nil, fmt.Errorf("%w: %w in %q",
+				eztools.ErrInvalidInput, err, p.expr)
+		}
+	}
+	return func(inf IssueInfos) bool {
+		a, _ := left(inf)
+		re := re
+		if re == nil {
+			b, _ := right(inf)
+			var err error
+			if re, err = regexp.Compile(b); err != nil {
+				return false
+			}
+		}
+		return re.MatchString(a) == want
+	}, nil
+}
+
+// operand parses a string, a number, a date, now-7d or a key of results
+func (p *whereParser) operand() (whereVal, error) {
+	if p.i >= len(p.toks) {
+		return nil, p.errAt("operand expected at")
+	}
+	tok := p.toks[p.i]
+	if !tok.quoted && slices.Contains(whereOps, tok.s) {
+		return nil, p.errAt("operand expected instead of")
+	}
+	p.i++
+	constant := func(s string) whereVal {
+		return func(IssueInfos) (string, bool) {
+			return s, true
+		}
+	}
+	switch {
+	case tok.quoted:
+		return constant(tok.s), nil
+	case whereNow.MatchString(tok.s):
+		return constant(p.relTime(tok.s)), nil
+	}
+	if _, ok := whereNum(tok.s); ok {
+		return constant(tok.s), nil
+	}
+	if _, ok := whereTime(tok.s); ok {
+		return constant(tok.s), nil
+	}
+	key := tok.s
+	return func(inf IssueInfos) (string, bool) {
+		v, ok := inf[key]
+		return v, ok
+	}, nil
+}
+
+// relTime makes a time relative to now, as RFC3339
+func (p *whereParser) relTime(s string) string {
+	m := whereNow.FindStringSubmatch(s)
+	t := p.now
+	if len(m[1]) > 0 {
+		n, _ := strconv.Atoi(m[2])
+		d := time.Duration(n) * map[string]time.Duration{
+			"s": time.Second, "m": time.Minute, "h": time.Hour,
+			"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[3]]
+		if m[1] == "-" {
+			d = -d
+		}
+		t = t.Add(d)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// whereNum parses a number
+func whereNum(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+// whereTime parses a time of any of whereTimeFmts
+func whereTime(s string) (time.Time, bool) {
+	for _, f := range whereTimeFmts {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// whereOrder compares values as numbers if both are,
+// as times if both are, or as strings
+func whereOrder(a, b string) int {
+	if x, ok := whereNum(a); ok {
+		if y, ok := whereNum(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := whereTime(a); ok {
+		if y, ok := whereTime(b); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// whereCmp compares values by op.
+// Nothing is less or greater than values not existing.
+func whereCmp(op, a string, aok bool, b string, bok bool) bool {
+	c := whereOrder(a, b)
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	}
+	if !aok || !bok {
+		return false
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestWhere(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	inf := IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrState: "Open",
+		IssueinfoStrBranch: "release/1.0", IssueinfoStrSize: "12",
+		"created":     "2024-05-08T10:00:00.000+0000",
+		"updated":     "2024-04-01 10:00:00.000000000",
+		"Code-Review": "-1", "empty": ""}
+	for expr, exp := range map[string]bool{
+		`status != "Closed"`:                         true,
+		`status == "Open" && branch =~ "^release/"`:  true,
+		`branch !~ "^release/"`:                      false,
+		`branch =~ "^main$" || status == Open`:       false,
+		`branch =~ "^main$" || status == "Open"`:     true,
+		`!(status == "Open")`:                        false,
+		`size > 9`:                                   true,
+		`size > "9"`:                                 true,
+		`size < 100 && size >= 12 && size <= 12`:     true,
+		`created > now-7d`:                           true,
+		`created > now-1d`:                           false,
+		`updated < now-1w && updated > 2024-03-31`:   true,
+		`created < now+1h`:                           true,
+		`Code-Review < 0`:                            true,
+		`id`:                                         true,
+		`empty`:                                      false,
+		`!missing`:                                   true,
+		`missing != "x"`:                             true,
+		`missing < 1 || missing > 1`:                 false,
+		"summary =~ `.` || (status == `Open` && id)": true,
+	} {
+		f, err := whereParse(expr, now)
+		if err != nil {
+			t.Error(expr, err)
+			continue
+		}
+		if f(inf) != exp {
+			t.Error(expr, exp, "expected")
+		}
+	}
+	for _, expr := range []string{
+		`status ==`, `(status == "Open"`, `status = "Open"`, `"unclosed`,
+		`status == "Open" status`, `branch =~ "("`, `&& id`, `id ||`,
+	} {
+		if _, err := whereParse(expr, now); !errors.Is(err,
+			eztools.ErrInvalidInput) {
+			t.Error(expr, "invalid input expected, got", err)
+		}
+	}
+}
+
+func TestWherePrint(t *testing.T) {
+	defer setWhere("")
+	if err := setWhere(`status == "Closed" || summary =~ "comma"`); err != nil {
+		t.Fatal(err)
+	}
+	out := outTest(t, OutCSV, outTestIssues, "list my open cases")
+	if strings.Count(out, "list my open cases") != 2 {
+		t.Error("2 records expected, got", out)
+	}
+	if err := setWhere(`status == "Closed"`); err != nil {
+		t.Fatal(err)
+	}
+	out = outTest(t, OutCSV, outTestIssues, "list my open cases")
+	if strings.Contains(out, "Open") || !strings.Contains(out, "Closed") {
+		t.Error("only X-2 expected, got", out)
+	}
+}
+
+func TestWhereLimit(t *testing.T) {
+	defer setWhere("")
+	fmtOld, writerOld := outFmt, outWriter
+	defer func() {
+		outFmt, outWriter = fmtOld, writerOld
+		outRecs, outAction, outCols = nil, "", nil
+	}()
+	var buf strings.Builder
+	outFmt, outWriter = OutCSV, &buf
+	for where, exp := range map[string]int{`status == "Closed"`: 3, "": 1} {
+		if err := setWhere(where); err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		looper := DefLooper{svr: &svrs{Type: CategoryJira},
+			funStr1: "list my open cases", maxResults: 1}
+		looper.Done(IssueInfos{IssueinfoStrID: "X-1"},
+			slices.Clone(outTestIssues), nil)
+		if strings.Count(buf.String(), "list my open cases") != 1 {
+			t.Error(where, "1 record expected, got", buf.String())
+		}
+		if n := len(looper.GetIssueInfo()); n != exp {
+			t.Error(where, exp, "results kept expected, got", n)
+		}
+	}
+}
+
+func TestFilterScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh")
+	}
+	dir := t.TempDir()
+	script, runs := filepath.Join(dir, "filter.sh"), filepath.Join(dir, "runs")
+	if err := os.WriteFile(script, []byte("cat > /dev/null\necho run >> "+
+		runs+"\necho '[false, false, true]'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fmtOld, writerOld := outFmt, outWriter
+	defer func() {
+		outFmt, outWriter = fmtOld, writerOld
+		outRecs, outAction, outCols = nil, "", nil
+	}()
+	var buf strings.Builder
+	outFmt, outWriter = OutCSV, &buf
+	outTestIssues.Print(CategoryJira, "list my open cases", "X-1", "sh", "", script)
+	outFlush()
+	if strings.Contains(buf.String(), "Open") ||
+		!strings.Contains(buf.String(), "Closed") {
+		t.Error("only X-2 expected, got", buf.String())
+	}
+	if b, err := os.ReadFile(runs); err != nil || string(b) != "run\n" {
+		t.Error("one run expected, got", string(b), err)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitee.com/bon-ami/eztools/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// workflow is a YAML file of steps, run one by one by -workflow
+type workflow struct {
+	// Vars are values referred to by steps as vars.<name>
+	Vars  map[string]string `yaml:"vars"`
+	Steps []wfStep          `yaml:"steps"`
+}
+
+// wfStep runs an action on a server, maybe for each of some items
+type wfStep struct {
+	// Name is for later steps to refer to, defaulting to step<N>
+	Name string `yaml:"name"`
+	// Server is the name of the server, needed if multiple configured
+	Server string `yaml:"server"`
+	// Action is one or more actions separated by actionSep, as -a
+	Action string `yaml:"action"`
+	// Params are keys of IssueInfos, such as id and comments, to values
+	Params map[string]string `yaml:"params"`
+	// When skips the step if it is false, empty, or 0
+	When string `yaml:"when"`
+	// Foreach runs the step for each of the values, as item
+	Foreach string `yaml:"foreach"`
+	// ContinueOnError runs later steps even if this one fails
+	ContinueOnError bool `yaml:"continue_on_error"`
+}
+
+// wfResult is the output of a step, referred to by later steps
+type wfResult struct {
+	results IssueInfoSlc
+	err     string
+}
+
+// wfVals are what templates in a workflow refer to
+type wfVals struct {
+	vars   map[string]string
+	params IssueInfos
+	steps  map[string]*wfResult
+	// item is the current one of foreach, if any
+	item *string
+}
+
+var (
+	// wfTmpl is a template in a value, as "{{ steps.list.results[*].id }}"
+	wfTmpl = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	// wfStepRef is a reference to results of a step, with index and field
+	wfStepRef = regexp.MustCompile(
+		`^steps\.([^.\[\]]+)\.results(?:\[(\*|\d+)\])?(?:\.(.+))?$`)
+	// wfErrRef is a reference to the error of a step
+	wfErrRef = regexp.MustCompile(`^steps\.([^.\[\]]+)\.error$`)
+)
+
+// wfFilters process values in templates, after "|", with arguments
+var wfFilters = map[string]struct {
+	args int
+	f    func(vals, args []string) ([]string, error)
+}{
+	// match takes all matches of a regular expression,
+	// or of the first group in it
+	"match": {1, func(vals, args []string) ([]string, error) {
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, err
+		}
+		var ret []string
+		for _, v := range vals {
+			for _, m := range re.FindAllStringSubmatch(v, -1) {
+				ret = append(ret, m[min(1, len(m)-1)])
+			}
+		}
+		return ret, nil
+	}},
+	"unique": {0, func(vals, _ []string) ([]string, error) {
+		var ret []string
+		for _, v := range vals {
+			if !slices.Contains(ret, v) {
+				ret = append(ret, v)
+			}
+		}
+		return ret, nil
+	}},
+	"join": {1, func(vals, args []string) ([]string, error) {
+		return []string{strings.Join(vals, args[0])}, nil
+	}},
+	"count": {0, func(vals, _ []string) ([]string, error) {
+		return []string{strconv.Itoa(len(vals))}, nil
+	}},
+	"first": {0, func(vals, _ []string) ([]string, error) {
+		return vals[:min(1, len(vals))], nil
+	}},
+	"eq": {1, func(vals, args []string) ([]string, error) {
+		return []string{strconv.FormatBool(
+			strings.Join(vals, issueSeparator) == args[0])}, nil
+	}},
+	"ne": {1, func(vals, args []string) ([]string, error) {
+		return []string{strconv.FormatBool(
+			strings.Join(vals, issueSeparator) != args[0])}, nil
+	}},
+	"not": {0, func(vals, _ []string) ([]string, error) {
+		return []string{strconv.FormatBool(!wfTruthy(vals))}, nil
+	}},
+}
+
+// wfTruthy is false for no values, or one of empty, false or 0
+func wfTruthy(vals []string) bool {
+	switch len(vals) {
+	case 0:
+		return false
+	case 1:
+		switch vals[0] {
+		case "", "false", "0":
+			return false
+		}
+	}
+	return true
+}
+
+// wfTokens splits an expression in a template into words,
+// quoted strings and "|"
+func wfTokens(expr string) (tokens []string, err error) {
+	for expr = strings.TrimSpace(expr); len(expr) > 0; expr =
+		strings.TrimSpace(expr) {
+		switch expr[0] {
+		case '|':
+			tokens = append(tokens, "|")
+			expr = expr[1:]
+		case '"', '`':
+			quoted, err := strconv.QuotedPrefix(expr)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", err, expr)
+			}
+			expr = expr[len(quoted):]
+			unquoted, _ := strconv.Unquote(quoted)
+			// quoted strings are kept with a mark to tell from words
+			tokens = append(tokens, "\""+unquoted)
+		default:
+			end := strings.IndexAny(expr, " \t|\"`")
+			if end < 0 {
+				end = len(expr)
+			}
+			tokens = append(tokens, expr[:end])
+			expr = expr[end:]
+		}
+	}
+	return
+}
+
+// eval evaluates an expression in a template, as
+// "steps.list.results[*].subject | match `[A-Z]+-[0-9]+` | unique"
+func (v *wfVals) eval(expr string) ([]string, error) {
+	tokens, err := wfTokens(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < 1 || tokens[0] == "|" {
+		return nil, fmt.Errorf("NO value in %q", expr)
+	}
+	var vals []string
+	if lit, ok := strings.CutPrefix(tokens[0], "\""); ok {
+		vals = []string{lit}
+	} else if vals, err = v.ref(tokens[0]); err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(tokens); {
+		if tokens[i] != "|" || i+1 >= len(tokens) {
+			return nil, fmt.Errorf("filter expected after %q in %q",
+				strings.Join(tokens[:i], " "), expr)
+		}
+		name := tokens[i+1]
+		filter, ok := wfFilters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q in %q", name, expr)
+		}
+		var args []string
+		for i += 2; i < len(tokens) && tokens[i] != "|"; i++ {
+			args = append(args, strings.TrimPrefix(tokens[i], "\""))
+		}
+		if len(args) != filter.args {
+			return nil, fmt.Errorf("%d arguments expected for %s in %q",
+				filter.args, name, expr)
+		}
+		if vals, err = filter.f(vals, args); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+// ref gets values of a reference to item, vars, params or steps
+func (v *wfVals) ref(ref string) ([]string, error) {
+	if ref == "item" {
+		if v.item == nil {
+			return nil, errors.New("item referred to without foreach")
+		}
+		return []string{*v.item}, nil
+	}
+	if name, ok := strings.CutPrefix(ref, "vars."); ok {
+		return []string{v.vars[name]}, nil
+	}
+	if name, ok := strings.CutPrefix(ref, "params."); ok {
+		return []string{v.params[name]}, nil
+	}
+	if m := wfErrRef.FindStringSubmatch(ref); m != nil {
+		res, err := v.step(m[1])
+		if err != nil {
+			return nil, err
+		}
+		return []string{res.err}, nil
+	}
+	m := wfStepRef.FindStringSubmatch(ref)
+	if m == nil {
+		return nil, fmt.Errorf("unknown reference %q", ref)
+	}
+	res, err := v.step(m[1])
+	if err != nil {
+		return nil, err
+	}
+	results := res.results
+	if len(m[2]) > 0 && m[2] != "*" {
+		i, _ := strconv.Atoi(m[2])
+		if i >= len(results) {
+			return nil, nil
+		}
+		results = results[i : i+1]
+	}
+	// IDs by default
+	field := m[3]
+	if len(field) < 1 {
+		field = IssueinfoStrID
+	}
+	var vals []string
+	for _, r := range results {
+		if val, ok := r[field]; ok {
+			vals = append(vals, val)
+		}
+	}
+	return vals, nil
+}
+
+// step gets the result of a step run before
+func (v *wfVals) step(name string) (*wfResult, error) {
+	res, ok := v.steps[name]
+	if !ok {
+		return nil, fmt.Errorf("step %q not run before", name)
+	}
+	return res, nil
+}
+
+// render replaces templates in a value.
+// A value of only one template may have multiple values,
+// while multiple ones are joined by issueSeparator in others.
+func (v *wfVals) render(s string) ([]string, error) {
+	locs := wfTmpl.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) < 1 {
+		return []string{s}, nil
+	}
+	trimmed := strings.TrimSpace(s)
+	if len(locs) == 1 && len(trimmed) == locs[0][1]-locs[0][0] {
+		return v.eval(s[locs[0][2]:locs[0][3]])
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range locs {
+		vals, err := v.eval(s[loc[2]:loc[3]])
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(strings.Join(vals, issueSeparator))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return []string{b.String()}, nil
+}
+
+// renderInf renders params of a step to input of its action
+func (v *wfVals) renderInf(params map[string]string) (IssueInfos, error) {
+	inf := make(IssueInfos)
+	for k, p := range params {
+		vals, err := v.render(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		if val := strings.Join(vals, issueSeparator); len(val) > 0 {
+			inf[k] = val
+		}
+	}
+	return inf, nil
+}
+
+// items renders foreach of a step, with multiple values in one split
+func (v *wfVals) items(foreach string) ([]string, error) {
+	vals, err := v.render(foreach)
+	if err != nil {
+		return nil, err
+	}
+	var items []string
+	for _, val := range vals {
+		for _, item := range strings.Split(val, issueSeparator) {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				items = append(items, item)
+			}
+		}
+	}
+	return items, nil
+}
+
+// wfLoad reads a workflow file, and checks servers and actions of steps
+// Return values: the workflow, and servers and actions of all steps
+func wfLoad(file string, cats cat2Act) (*workflow, []*svrs,
+	[][]action2Func, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+	var wf workflow
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err = dec.Decode(&wf); err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s: %w",
+			eztools.ErrInvalidInput, file, err)
+	}
+	if len(wf.Steps) < 1 {
+		return nil, nil, nil, fmt.Errorf("%w: NO steps in %s",
+			eztools.ErrInvalidInput, file)
+	}
+	svrs1 := make([]*svrs, len(wf.Steps))
+	funs := make([][]action2Func, len(wf.Steps))
+	names := make(map[string]struct{})
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		if len(step.Name) < 1 {
+			step.Name = "step" + strconv.Itoa(i+1)
+		}
+		errStep := func(s string) error {
+			return fmt.Errorf("%w: step %s of %s: %s",
+				eztools.ErrInvalidInput, step.Name, file, s)
+		}
+		if _, ok := names[step.Name]; ok {
+			return nil, nil, nil, errStep("duplicate name")
+		}
+		names[step.Name] = struct{}{}
+		switch {
+		case len(step.Server) > 0:
+			svrs1[i] = matchSvr(cfg.Svrs, step.Server)
+		case len(cfg.Svrs) == 1:
+			svrs1[i] = &cfg.Svrs[0]
+		}
+		if svrs1[i] == nil {
+			return nil, nil, nil, errStep("server \"" + step.Server +
+				"\" NOT configured")
+		}
+		funs[i] = matchFuncFromParam(step.Action, svrs1[i], cats)
+		if len(funs[i]) < 1 || len(funs[i]) !=
+			len(strings.Split(step.Action, actionSep)) {
+			return nil, nil, nil, errStep("action \"" + step.Action +
+				"\" NOT recognized for " + svrs1[i].Type)
+		}
+	}
+	return &wf, svrs1, funs, nil
+}
+
+// wfRun runs a workflow file of -workflow, step by step, without
+// interactions. Command line params are referred to as params.<key>.
+// It stops at a failing step, unless it continues on errors.
+func wfRun(ctx context.Context, cats cat2Act, para params) (errs []error) {
+	wf, svrs1, funs, err := wfLoad(para.workflow, cats)
+	if err != nil {
+		return []error{err}
+	}
+	uiSilent = true
+	vals := wfVals{vars: wf.Vars, params: mkIssueinfo(para),
+		steps: make(map[string]*wfResult)}
+	for i, step := range wf.Steps {
+		res := new(wfResult)
+		errsStep := wfRunStep(ctx, &vals, step, svrs1[i], funs[i],
+			para, res)
+		vals.steps[step.Name] = res
+		for _, err := range errsStep {
+			errs = append(errs, fmt.Errorf("step %s: %w", step.Name, err))
+		}
+		if len(errsStep) > 0 {
+			res.err = errors.Join(errsStep...).Error()
+			if !step.ContinueOnError ||
+				errors.Is(errors.Join(errsStep...), errIntr) {
+				break
+			}
+		}
+	}
+	return
+}
+
+// wfRunStep runs a step for each item, if any, with results in res
+func wfRunStep(ctx context.Context, vals *wfVals, step wfStep,
+	svr *svrs, funs []action2Func, para params, res *wfResult) []error {
+	vals.item = nil
+	if len(step.When) > 0 {
+		when, err := vals.render(step.When)
+		if err != nil {
+			return []error{fmt.Errorf("%w: when: %w",
+				eztools.ErrInvalidInput, err)}
+		}
+		if !wfTruthy(when) {
+			Log(true, false, "step", step.Name, "skipped")
+			return nil
+		}
+	}
+	// one run without items, if no foreach
+	items := []*string{nil}
+	if len(step.Foreach) > 0 {
+		strs, err := vals.items(step.Foreach)
+		if err != nil {
+			return []error{fmt.Errorf("%w: foreach: %w",
+				eztools.ErrInvalidInput, err)}
+		}
+		items = nil
+		for i := range strs {
+			items = append(items, &strs[i])
+		}
+	}
+	authInfo, hdr, err := cfg2AuthInfo(*svr, cfg)
+	if err != nil {
+		return []error{fmt.Errorf("%w: %w", errCfg, err)}
+	}
+	var errs []error
+	for _, item := range items {
+		vals.item = item
+		inf, err := vals.renderInf(step.Params)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: params: %w",
+				eztools.ErrInvalidInput, err))
+			continue
+		}
+		Log(true, false, "step", step.Name+":", step.Action, inf)
+		looper := DefLooper{para: para, svr: svr, authInfo: authInfo,
+			hdr: hdr, maxResults: para.limit}
+		errs = append(errs,
+			LoopActions(ctx, svr, funs, inf, &looper, nil)...)
+		res.results = append(res.results, looper.GetIssueInfo()...)
+		if errors.Is(errors.Join(errs...), errIntr) {
+			break
+		}
+	}
+	return errs
+}
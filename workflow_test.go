@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestWorkflowRender(t *testing.T) {
+	item := "X-2"
+	v := wfVals{vars: map[string]string{"proj": "X"},
+		params: IssueInfos{IssueinfoStrID: "alice"},
+		steps: map[string]*wfResult{
+			"list": {results: IssueInfoSlc{
+				{IssueinfoStrID: "1", IssueinfoStrSubject: "Fix X-1 and X-2"},
+				{IssueinfoStrID: "2", IssueinfoStrSubject: "Revert X-1"}}},
+			"none": {err: "failed"}},
+		item: &item}
+	for _, c := range []struct {
+		in  string
+		exp []string
+		err bool
+	}{
+		{in: "no template", exp: []string{"no template"}},
+		{in: "{{ steps.list.results[*].id }}", exp: []string{"1", "2"}},
+		{in: "{{steps.list.results}}", exp: []string{"1", "2"}},
+		{in: "{{ steps.list.results[1].subject }}", exp: []string{"Revert X-1"}},
+		{in: "{{ steps.list.results[5].id }}"},
+		{in: "id {{ steps.list.results }} of {{ vars.proj }}",
+			exp: []string{"id 1,2 of X"}},
+		{in: "{{ params.id }}/{{ item }}", exp: []string{"alice/X-2"}},
+		{in: "{{ steps.list.results[*].subject | match `X-[0-9]+` | unique }}",
+			exp: []string{"X-1", "X-2"}},
+		{in: `{{ steps.list.results[*].subject | match "(\\d+)" | join "+" }}`,
+			exp: []string{"1+2+1"}},
+		{in: "{{ steps.list.results | count }}", exp: []string{"2"}},
+		{in: "{{ steps.list.results | first }}", exp: []string{"1"}},
+		{in: `{{ steps.none.error | eq "failed" }}`, exp: []string{"true"}},
+		{in: `{{ steps.none.results | count | ne "0" }}`, exp: []string{"false"}},
+		{in: "{{ steps.none.results | not }}", exp: []string{"true"}},
+		{in: "{{ `literal` | count }}", exp: []string{"1"}},
+		{in: "{{ steps.later.results }}", err: true},
+		{in: "{{ steps.list.results | nofilter }}", err: true},
+		{in: "{{ steps.list.results | join }}", err: true},
+		{in: "{{ steps.list.results count }}", err: true},
+		{in: "{{ unknown }}", err: true},
+		{in: `{{ "unclosed }}`, err: true},
+	} {
+		got, err := v.render(c.in)
+		switch {
+		case c.err != (err != nil):
+			t.Error(c.in, "error got", err)
+		case !slices.Equal(got, c.exp):
+			t.Errorf("%s: %q expected, got %q", c.in, c.exp, got)
+		}
+	}
+	for _, c := range []struct {
+		in  []string
+		exp bool
+	}{
+		{nil, false}, {[]string{""}, false}, {[]string{"0"}, false},
+		{[]string{"false"}, false}, {[]string{"x"}, true},
+		{[]string{"", ""}, true},
+	} {
+		if wfTruthy(c.in) != c.exp {
+			t.Error(c.in, c.exp, "expected")
+		}
+	}
+}
+
+// wfWrite writes a workflow to a temporary file
+func wfWrite(t *testing.T, s string) string {
+	file := filepath.Join(t.TempDir(), "wf.yaml")
+	if err := os.WriteFile(file, []byte(s), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestWorkflowLoad(t *testing.T) {
+	svrsCfg := cfg.Svrs
+	defer func() {
+		cfg.Svrs = svrsCfg
+	}()
+	cfg.Svrs = []svrs{{Type: CategoryJira, Name: "j"},
+		{Type: CategoryGerrit, Name: "g"}}
+	for _, s := range []string{
+		"",
+		"steps: []",
+		"steps:\n- {server: j, action: list my open cases, unknown: x}",
+		"steps:\n- {action: list my open cases}",
+		"steps:\n- {server: x, action: list my open cases}",
+		"steps:\n- {server: g, action: list my open cases}",
+		"steps:\n- {server: j, action: list my open cases;nothing}",
+		"steps:\n- {name: a, server: j, action: list my open cases}\n" +
+			"- {name: a, server: j, action: list my open cases}",
+	} {
+		if _, _, _, err := wfLoad(wfWrite(t, s), makeCat2Act()); !errors.Is(err,
+			eztools.ErrInvalidInput) {
+			t.Errorf("%q: invalid input expected, got %v", s, err)
+		}
+	}
+	wf, svrs1, funs, err := wfLoad(wfWrite(t, "steps:\n"+
+		"- {server: j, action: list my open cases}\n"+
+		"- {server: g, action: list my open submits;list merged submits of someone}"),
+		makeCat2Act())
+	if err != nil || wf.Steps[1].Name != "step2" || svrs1[1].Name != "g" ||
+		len(funs[1]) != 2 {
+		t.Error("2 steps expected, got", wf, err)
+	}
+}
+
+func TestWorkflowRun(t *testing.T) {
+	silent, user, svrsCfg := uiSilent, cfg.User, cfg.Svrs
+	defer func() {
+		uiSilent, cfg.User, cfg.Svrs = silent, user, svrsCfg
+	}()
+	cfg.User = fakeUser
+	g := auditFakeSvr(t, CategoryGerrit, []fakeReq{
+		{req: "GET /a/changes/?q=status:merged+owner:alice",
+			resp: "changes.json"}})
+	defer g.Close()
+	j := auditFakeSvr(t, CategoryJira, []fakeReq{
+		{req: "GET /rest/api/latest/search?jql=" +
+			"assignee%3Dtester%26status%21%3DClosed", resp: "search.json"},
+		{req: "POST " + jiraFakeIssue +
+			`/comment {"body":"Fixed by Fix crash on start for X-1"}`,
+			resp: "comment.json", code: http.StatusCreated},
+		{req: "POST /rest/api/latest/issue/X-2" +
+			`/comment {"body":"Fixed by Fix crash on start for X-2"}`,
+			resp: "comment.json", code: http.StatusCreated},
+		{req: "POST /rest/api/latest/issue/X-9/comment",
+			resp: "error.json", code: http.StatusNotFound},
+		{req: "POST " + jiraFakeIssue + `/comment {"body":"X-9 failed"}`,
+			resp: "comment.json", code: http.StatusCreated}})
+	defer j.Close()
+	cfg.Svrs = []svrs{g.svr, j.svr}
+	file := wfWrite(t, `
+vars:
+  missing: X-9
+steps:
+  - name: merged
+    server: fakeGerrit
+    action: list merged submits of someone
+    params:
+      id: "{{ params.id }}"
+  - name: mine
+    server: fakeJIRA
+    action: list my open cases
+    when: "{{ steps.merged.results[*].subject | match crash }}"
+  - name: none
+    server: fakeJIRA
+    action: list my open cases
+    when: '{{ steps.merged.results | count | eq "0" }}'
+  - name: comment
+    server: fakeJIRA
+    action: add a comment to a case
+    foreach: "{{ steps.mine.results[*].id }}"
+    params:
+      id: "{{ item }}"
+      comments: "Fixed by {{ steps.merged.results[0].subject }} for {{ item }}"
+  - name: bad
+    server: fakeJIRA
+    action: add a comment to a case
+    continue_on_error: true
+    params:
+      id: "{{ vars.missing }}"
+      comments: "Not found"
+  - name: report
+    server: fakeJIRA
+    action: add a comment to a case
+    when: "{{ steps.bad.error }}"
+    params:
+      id: X-1
+      comments: "{{ vars.missing }} failed"
+`)
+	errs := wfRun(context.Background(), makeCat2Act(),
+		params{workflow: file, i: "alice"})
+	if len(errs) != 1 {
+		t.Error("1 error of step bad expected, got", errs)
+	}
+	for _, f := range []*fakeSvr{g, j} {
+		f.mu.Lock()
+		for _, req := range f.reqs[f.got:] {
+			t.Error("request not sent", req.req)
+		}
+		f.mu.Unlock()
+	}
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+const (
+	// jiraTmFmt is the format of time of worklogs on servers
+	jiraTmFmt = "2006-01-02T15:04:05.000-0700"
+	// jiraTmFmtIn is the format of time of worklogs by users,
+	// besides time.RFC3339
+	jiraTmFmtIn = "2006-01-02 15:04"
+	// jiraEstimateLeave is the remaining estimate not to be adjusted
+	jiraEstimateLeave = "leave"
+)
+
+// jiraNow is the current time, for timers and reports of worklogs
+var jiraNow = time.Now
+
+// jiraTimersMu guards timers from loading till saving, among jobs and serve
+var jiraTimersMu sync.Mutex
+
+// jiraWorklog is a worklog to be sent
+type jiraWorklog struct {
+	TimeSpent        string `json:"timeSpent,omitempty"`
+	TimeSpentSeconds int    `json:"timeSpentSeconds,omitempty"`
+	Started          string `json:"started,omitempty"`
+	Comment          string `json:"comment,omitempty"`
+}
+
+// jiraTmIn parses time of worklogs by users, as jiraTmFmtIn in local time,
+// or time.RFC3339
+func jiraTmIn(s string) (string, error) {
+	if len(s) < 1 {
+		return "", nil
+	}
+	tm, err := time.ParseInLocation(jiraTmFmtIn, s, jiraNow().Location())
+	if err != nil {
+		if tm, err = time.Parse(time.RFC3339, s); err != nil {
+			Log(true, false, "time must be as", jiraTmFmtIn, "or",
+				time.RFC3339, "rather than", s)
+			return "", eztools.ErrInvalidInput
+		}
+	}
+	return tm.Format(jiraTmFmt), nil
+}
+
+// jiraEstimateQuery is the query to adjust the remaining estimate,
+// to a new one, or jiraEstimateLeave, or automatically if empty
+func jiraEstimateQuery(remaining string) string {
+	switch remaining {
+	case "":
+		return ""
+	case jiraEstimateLeave:
+		return "?adjustEstimate=" + jiraEstimateLeave
+	}
+	return "?" + url.Values{"adjustEstimate": {"new"},
+		"newEstimate": {remaining}}.Encode()
+}
+
+// jiraDur formats seconds as time spent of worklogs, such as "1h 30m"
+func jiraDur(secs int) string {
+	h, m := secs/3600, secs%3600/60
+	switch {
+	case h == 0:
+		return strconv.Itoa(m) + "m"
+	case m == 0:
+		return strconv.Itoa(h) + "h"
+	}
+	return strconv.Itoa(h) + "h " + strconv.Itoa(m) + "m"
+}
+
+// jiraParse1Worklog parses a worklog of a case
+func jiraParse1Worklog(id string, v interface{}) IssueInfos {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		LogTypeErr(v, "map[string]interface{}")
+		return nil
+	}
+	res := IssueInfos{IssueinfoStrID: id}
+	for k, ind := range map[string]string{"id": IssueinfoStrKey,
+		"timeSpent": IssueinfoStrSize, "started": IssueinfoStrDate,
+		"comment": IssueinfoStrComments} {
+		res[ind], _ = m[k].(string)
+	}
+	if author := chkNLoopStringMap(m[IssueinfoStrAuthor], "",
+		[]string{IssueinfoStrName}); author != nil {
+		res[IssueinfoStrAuthor] = author[0]
+	}
+	return res
+}
+
+// jiraSendWorklog sends a worklog of a case, to add one,
+// or to update the one of IssueinfoStrKey
+func jiraSendWorklog(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo, issueInfo IssueInfos,
+	worklog jiraWorklog) (IssueInfoSlc, error) {
+	jsonStr, err := json.Marshal(worklog)
+	if err != nil {
+		return nil, err
+	}
+	method, RestAPIStr := http.MethodPost,
+		svr.URL+urlAPI4JR+issueInfo[IssueinfoStrID]+"/worklog"
+	if len(issueInfo[IssueinfoStrKey]) > 0 {
+		method = http.MethodPut
+		RestAPIStr += "/" + issueInfo[IssueinfoStrKey]
+	}
+	bodyMap, err := restMap(ctx, method,
+		RestAPIStr+jiraEstimateQuery(issueInfo[IssueinfoStrEstimate]),
+		authInfo, bytes.NewReader(jsonStr), svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	if bodyMap == nil && dryRun {
+		// nothing replied for a request not sent
+		return IssueInfos{IssueinfoStrID: issueInfo[IssueinfoStrID],
+			IssueinfoStrKey:      issueInfo[IssueinfoStrKey],
+			IssueinfoStrSize:     worklog.TimeSpent,
+			IssueinfoStrDate:     worklog.Started,
+			IssueinfoStrComments: worklog.Comment}.ToSlc(), nil
+	}
+	return jiraParse1Worklog(issueInfo[IssueinfoStrID], bodyMap).ToSlc(), nil
+}
+
+// JiraAddWorklog adds a worklog to a case, of time spent by IssueinfoStrSize,
+// such as "1h 30m", started at IssueinfoStrLink, or now,
+// with a comment by IssueinfoStrComments, if any.
+// The remaining estimate is set by IssueinfoStrEstimate, if any.
+func JiraAddWorklog(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 ||
+		len(issueInfo[IssueinfoStrSize]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	started, err := jiraTmIn(issueInfo[IssueinfoStrLink])
+	if err != nil {
+		return nil, err
+	}
+	// not to update any
+	issueInfo[IssueinfoStrKey] = ""
+	return jiraSendWorklog(ctx, svr, authInfo, issueInfo, jiraWorklog{
+		TimeSpent: issueInfo[IssueinfoStrSize], Started: started,
+		Comment: issueInfo[IssueinfoStrComments]})
+}
+
+// JiraModWorklog updates the worklog of IssueinfoStrKey of a case,
+// with time spent, start time or comment, as JiraAddWorklog does
+func JiraModWorklog(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 ||
+		len(issueInfo[IssueinfoStrKey]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	started, err := jiraTmIn(issueInfo[IssueinfoStrLink])
+	if err != nil {
+		return nil, err
+	}
+	worklog := jiraWorklog{TimeSpent: issueInfo[IssueinfoStrSize],
+		Started: started, Comment: issueInfo[IssueinfoStrComments]}
+	if worklog == (jiraWorklog{}) {
+		Log(true, false, "NO time spent, start time or comment to update")
+		return nil, eztools.ErrInvalidInput
+	}
+	return jiraSendWorklog(ctx, svr, authInfo, issueInfo, worklog)
+}
+
+// JiraDelWorklog deletes the worklog of IssueinfoStrKey of a case,
+// with the remaining estimate set by IssueinfoStrEstimate, if any
+func JiraDelWorklog(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 ||
+		len(issueInfo[IssueinfoStrKey]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	if _, err := restSth(ctx, http.MethodDelete, svr.URL+urlAPI4JR+
+		issueInfo[IssueinfoStrID]+"/worklog/"+issueInfo[IssueinfoStrKey]+
+		jiraEstimateQuery(issueInfo[IssueinfoStrEstimate]),
+		authInfo, nil, svr.Magic); err != nil {
+		return nil, err
+	}
+	return IssueInfos{IssueinfoStrID: issueInfo[IssueinfoStrID],
+		IssueinfoStrKey: issueInfo[IssueinfoStrKey]}.ToSlc(), nil
+}
+
+// jiraWorklogs gets all worklogs of a case
+func jiraWorklogs(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	id string) ([]interface{}, error) {
+	bodyMap, err := restMap(ctx, http.MethodGet, svr.URL+urlAPI4JR+
+		id+"/worklog", authInfo, nil, svr.Magic)
+	if err != nil {
+		return nil, err
+	}
+	worklogs, _ := bodyMap["worklogs"].([]interface{})
+	return worklogs, nil
+}
+
+// JiraWorklogs lists worklogs of a case
+func JiraWorklogs(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	worklogs, err := jiraWorklogs(ctx, svr, authInfo, issueInfo[IssueinfoStrID])
+	if err != nil {
+		return nil, err
+	}
+	var res IssueInfoSlc
+	for _, worklog := range worklogs {
+		if inf := jiraParse1Worklog(issueInfo[IssueinfoStrID],
+			worklog); inf != nil {
+			res = append(res, inf)
+		}
+	}
+	return res, nil
+}
+
+// jiraTimer is work on a case since a time
+type jiraTimer struct {
+	Server string    `json:"server"`
+	ID     string    `json:"id"`
+	Start  time.Time `json:"start"`
+}
+
+// jiraTimersFile is where timers are saved, next to the config file
+func jiraTimersFile() string {
+	if len(cfgFile) < 1 {
+		return module + "_timers.json"
+	}
+	return strings.TrimSuffix(cfgFile, filepath.Ext(cfgFile)) + "_timers.json"
+}
+
+// jiraTimersLoad loads timers, if any
+func jiraTimersLoad() ([]jiraTimer, error) {
+	b, err := os.ReadFile(jiraTimersFile())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var timers []jiraTimer
+	if err = json.Unmarshal(b, &timers); err != nil {
+		Log(true, false, "timers not recognized in", jiraTimersFile())
+		return nil, err
+	}
+	return timers, nil
+}
+
+// jiraTimersSave saves timers, but in dry run mode,
+// to a temporary file renamed to the file, not to leave it partly written
+func jiraTimersSave(timers []jiraTimer) error {
+	if dryRun {
+		Log(true, false, "DRY RUN: timers not saved to", jiraTimersFile())
+		return nil
+	}
+	b, err := json.MarshalIndent(timers, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := jiraTimersFile()
+	f, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+// jiraTimerFind finds the timer of a case on a server
+func jiraTimerFind(timers []jiraTimer, svr *svrs, id string) int {
+	for i, timer := range timers {
+		if timer.Server == svr.Name && timer.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// JiraStartWork starts the timer of work on a case
+func JiraStartWork(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	jiraTimersMu.Lock()
+	defer jiraTimersMu.Unlock()
+	timers, err := jiraTimersLoad()
+	if err != nil {
+		return nil, err
+	}
+	if i := jiraTimerFind(timers, svr, issueInfo[IssueinfoStrID]); i >= 0 {
+		Log(true, false, "work on", issueInfo[IssueinfoStrID],
+			"already started at", timers[i].Start.Format(time.RFC3339))
+		return nil, eztools.ErrInExistence
+	}
+	timer := jiraTimer{svr.Name, issueInfo[IssueinfoStrID], jiraNow()}
+	if err = jiraTimersSave(append(timers, timer)); err != nil {
+		return nil, err
+	}
+	return IssueInfos{IssueinfoStrID: timer.ID,
+		IssueinfoStrDate: timer.Start.Format(time.RFC3339)}.ToSlc(), nil
+}
+
+// JiraStopWork stops the timer of work on a case, adding a worklog
+// of the time since it started, in minutes, with a comment
+// and the remaining estimate, as JiraAddWorklog does
+func JiraStopWork(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	if len(issueInfo[IssueinfoStrID]) < 1 {
+		return nil, eztools.ErrInvalidInput
+	}
+	jiraTimersMu.Lock()
+	defer jiraTimersMu.Unlock()
+	timers, err := jiraTimersLoad()
+	if err != nil {
+		return nil, err
+	}
+	i := jiraTimerFind(timers, svr, issueInfo[IssueinfoStrID])
+	if i < 0 {
+		Log(true, false, "NO work started on", issueInfo[IssueinfoStrID])
+		return nil, eztools.ErrNoValidResults
+	}
+	// at least a minute, as Jira requires
+	secs := max(int(math.Round(jiraNow().Sub(timers[i].Start).Minutes()))*60,
+		60)
+	issueInfo[IssueinfoStrKey] = ""
+	res, err := jiraSendWorklog(ctx, svr, authInfo, issueInfo, jiraWorklog{
+		TimeSpentSeconds: secs, Started: timers[i].Start.Format(jiraTmFmt),
+		Comment: issueInfo[IssueinfoStrComments]})
+	if err != nil {
+		// to be stopped again
+		return nil, err
+	}
+	for _, inf := range res {
+		if len(inf[IssueinfoStrSize]) < 1 {
+			inf[IssueinfoStrSize] = jiraDur(secs)
+		}
+	}
+	return res, jiraTimersSave(append(timers[:i], timers[i+1:]...))
+}
+
+// jiraMyself gets the user of authInfo
+func jiraMyself(ctx context.Context, svr *svrs,
+	authInfo eztools.AuthInfo) (map[string]interface{}, error) {
+	const RestAPIStr = "rest/api/latest/myself"
+	return restMap(ctx, http.MethodGet, svr.URL+RestAPIStr,
+		authInfo, nil, svr.Magic)
+}
+
+// jiraIsMe checks whether an author of worklogs is me, by accountId on
+// Jira Cloud, or by key or name otherwise, whichever me has first
+func jiraIsMe(me map[string]interface{}, author interface{}) bool {
+	m, _ := author.(map[string]interface{})
+	for _, k := range []string{"accountId", "key", IssueinfoStrName} {
+		if v, _ := me[k].(string); len(v) > 0 {
+			u, _ := m[k].(string)
+			return u == v
+		}
+	}
+	return false
+}
+
+// JiraReportWork reports time logged by the user today and this week,
+// from Monday, of each case and in total
+func JiraReportWork(ctx context.Context, svr *svrs, authInfo eztools.AuthInfo,
+	issueInfo IssueInfos) (IssueInfoSlc, error) {
+	now := jiraNow()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0,
+		now.Location())
+	week := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	me, err := jiraMyself(ctx, svr, authInfo)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := JiraSearch(ctx, svr, authInfo, IssueInfos{
+		IssueinfoStrKey: "worklogAuthor = currentUser() AND worklogDate >= \"" +
+			week.Format("2006-01-02") + "\" ORDER BY key"})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		res                     IssueInfoSlc
+		totalToday, totalWeekly int
+	)
+	for _, issue := range issues {
+		worklogs, err := jiraWorklogs(ctx, svr, authInfo, issue[IssueinfoStrID])
+		if err != nil {
+			return nil, err
+		}
+		var secsToday, secsWeekly int
+		for _, worklog := range worklogs {
+			m, ok := worklog.(map[string]interface{})
+			if !ok {
+				LogTypeErr(worklog, "map[string]interface{}")
+				continue
+			}
+			if !jiraIsMe(me, m[IssueinfoStrAuthor]) {
+				continue
+			}
+			started, _ := m["started"].(string)
+			tm, err := time.Parse(jiraTmFmt, started)
+			if err != nil || tm.Before(week) {
+				continue
+			}
+			secs, _ := m["timeSpentSeconds"].(float64)
+			secsWeekly += int(secs)
+			if !tm.Before(today) {
+				secsToday += int(secs)
+			}
+		}
+		if secsWeekly < 1 {
+			continue
+		}
+		totalToday += secsToday
+		totalWeekly += secsWeekly
+		res = append(res, IssueInfos{IssueinfoStrID: issue[IssueinfoStrID],
+			IssueinfoStrSummary: issue[IssueinfoStrSummary],
+			IssueinfoStrToday:   jiraDur(secsToday),
+			IssueinfoStrWeek:    jiraDur(secsWeekly)})
+	}
+	return append(res, IssueInfos{IssueinfoStrID: "total",
+		IssueinfoStrToday: jiraDur(totalToday),
+		IssueinfoStrWeek:  jiraDur(totalWeekly)}), nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gitee.com/bon-ami/eztools/v6"
+)
+
+func TestJiraWorkTimer(t *testing.T) {
+	file := cfgFile
+	defer func() {
+		cfgFile = file
+	}()
+	dir := t.TempDir()
+	cfgFile = filepath.Join(dir, "jirrit.xml")
+	if f := jiraTimersFile(); f != filepath.Join(dir, "jirrit_timers.json") {
+		t.Fatal("timers next to the config file expected, got", f)
+	}
+	// started 95 minutes ago, on the fake server of Jira
+	b, err := json.Marshal([]jiraTimer{
+		{"fake" + CategoryJira, "X-1", fakeNow.Add(-95 * time.Minute)},
+		{"other", "X-1", fakeNow}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(jiraTimersFile(), b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	fakeRun(t, CategoryJira, []fakeCase{
+		{action: "start work on a case", inf: IssueInfos{IssueinfoStrID: "X-1"},
+			err: eztools.ErrInExistence},
+		{action: "stop work on a case",
+			inf: IssueInfos{IssueinfoStrID: "X-1", IssueinfoStrComments: "done"},
+			reqs: []fakeReq{{req: "POST " + jiraFakeIssue + "/worklog" +
+				` {"timeSpentSeconds":5700,"started":"2026-10-14T08:25:00.000+0000",` +
+				`"comment":"done"}`, resp: "worklog.json",
+				code: http.StatusCreated}}},
+		{action: "stop work on a case", inf: IssueInfos{IssueinfoStrID: "X-1"},
+			err: eztools.ErrNoValidResults}})
+	timers, err := jiraTimersLoad()
+	if err != nil || len(timers) != 1 || timers[0].Server != "other" {
+		t.Error("timer of the other server expected, got", timers, err)
+	}
+}
+
+func TestJiraTimersJobs(t *testing.T) {
+	file := cfgFile
+	defer func() {
+		cfgFile = file
+	}()
+	cfgFile = filepath.Join(t.TempDir(), "jirrit.xml")
+	svr := &svrs{Name: "fake" + CategoryJira}
+	var (
+		ids []string
+		wg  sync.WaitGroup
+	)
+	for i := range 32 {
+		ids = append(ids, "X-"+strconv.Itoa(i+1))
+	}
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := JiraStartWork(context.Background(), svr,
+				eztools.AuthInfo{}, IssueInfos{IssueinfoStrID: id}); err != nil {
+				t.Error(id, err)
+			}
+		}()
+	}
+	wg.Wait()
+	timers, err := jiraTimersLoad()
+	if err != nil || len(timers) != len(ids) {
+		t.Error(len(ids), "timers expected, got", len(timers), err)
+	}
+	// nothing left but the timers
+	if files, _ := os.ReadDir(filepath.Dir(cfgFile)); len(files) != 1 {
+		t.Error("only the timers file expected, got", files)
+	}
+}
+
+func TestJiraIsMe(t *testing.T) {
+	server := map[string]interface{}{"key": "JIRAUSER10000", "name": "tester"}
+	cloud := map[string]interface{}{"accountId": "5b10a2844c20165700ede21g"}
+	for i, c := range []struct {
+		me     map[string]interface{}
+		author interface{}
+		exp    bool
+	}{
+		{server, map[string]interface{}{"key": "JIRAUSER10000",
+			"name": "tester"}, true},
+		{server, map[string]interface{}{"key": "JIRAUSER10001",
+			"name": "tester"}, false},
+		{cloud, map[string]interface{}{
+			"accountId": "5b10a2844c20165700ede21g"}, true},
+		{cloud, map[string]interface{}{"accountId": "other"}, false},
+		{cloud, nil, false},
+		{map[string]interface{}{}, map[string]interface{}{}, false},
+	} {
+		if got := jiraIsMe(c.me, c.author); got != c.exp {
+			t.Error(i, c.exp, "expected, got", got)
+		}
+	}
+}
+
+func TestJiraDur(t *testing.T) {
+	for secs, exp := range map[int]string{0: "0m", 60: "1m", 7200: "2h",
+		5400: "1h 30m", 90061: "25h 1m"} {
+		if got := jiraDur(secs); got != exp {
+			t.Error(secs, exp, "expected, got", got)
+		}
+	}
+}